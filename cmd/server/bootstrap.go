@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/service"
+)
+
+// bootstrapCommand — подкоманда для создания первого администратора:
+//
+//	./main bootstrap-admin -email admin@example.com -password ...
+//
+// Без флагов берутся BOOTSTRAP_ADMIN_EMAIL и BOOTSTRAP_ADMIN_PASSWORD.
+const bootstrapCommand = "bootstrap-admin"
+
+// runBootstrapCommand разбирает флаги подкоманды и создаёт администратора.
+func runBootstrapCommand(srv service.Service, args []string, email, password string) error {
+	fs := flag.NewFlagSet(bootstrapCommand, flag.ContinueOnError)
+	fs.StringVar(&email, "email", email, "administrator email (BOOTSTRAP_ADMIN_EMAIL)")
+	fs.StringVar(&password, "password", password, "administrator password (BOOTSTRAP_ADMIN_PASSWORD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	id, err := bootstrapAdmin(context.Background(), srv, email, password)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("administrator %s created (id=%d)\n", email, id)
+	return nil
+}
+
+// bootstrapAdmin создаёт первого администратора; если он уже есть — entities.ErrAdminExists.
+func bootstrapAdmin(ctx context.Context, srv service.Service, email, password string) (int64, error) {
+	if !entities.ValidEmail(email) {
+		return 0, entities.ErrInvalidEmail
+	}
+	if len(password) < 6 {
+		return 0, errors.New("password must be at least 6 characters")
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+	return srv.BootstrapAdmin(ctx, email, hash)
+}
@@ -12,21 +12,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/Skapar/backend/config"
+	"github.com/Skapar/backend/internal/auth"
 	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/grpcserver"
 	"github.com/Skapar/backend/internal/handler"
+	"github.com/Skapar/backend/internal/mailer"
 	"github.com/Skapar/backend/internal/middleware"
+	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/Skapar/backend/internal/repository"
 	"github.com/Skapar/backend/internal/service"
+	"github.com/Skapar/backend/internal/stream"
 	"github.com/Skapar/backend/internal/worker"
 	"github.com/Skapar/backend/pkg/cache"
 	"github.com/Skapar/backend/pkg/database"
+	pb "github.com/Skapar/backend/proto"
 
 	// Swagger
 	docs "github.com/Skapar/backend/docs"
@@ -39,6 +47,7 @@ import (
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -69,11 +78,37 @@ func main() {
 	// Конфиг
 	cfg := config.New()
 	cfg.Init()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+
+	// Ключи подписи JWT
+	keys, err := auth.NewKeySet(&auth.KeySetConfig{
+		Algorithm:           cfg.JWTAlgorithm,
+		Secret:              cfg.JWTSecret,
+		SigningKeyFile:      cfg.JWTSigningKeyFile,
+		SigningKeyID:        cfg.JWTSigningKeyID,
+		VerificationKeysDir: cfg.JWTVerificationKeysDir,
+	})
+	if err != nil {
+		log.Fatalf("failed to load JWT keys: %v", err)
+	}
 
-	var cacheR *cache.Cache
+	// Шифрование TOTP-секретов; без ключа второй фактор подключить нельзя
+	var secrets *auth.SecretBox
+	if cfg.TOTPEncryptionKey != "" {
+		if secrets, err = auth.NewSecretBox(cfg.TOTPEncryptionKey); err != nil {
+			log.Fatalf("invalid TOTP_ENCRYPTION_KEY: %v", err)
+		}
+	}
+
+	var (
+		cacheR *cache.Cache
+		rdb    redis.UniversalClient
+	)
 
 	if cfg.RedisAddr != "" {
-		rdb := redis.NewClient(&redis.Options{
+		rdb = redis.NewClient(&redis.Options{
 			Addr:         cfg.RedisAddr,
 			DialTimeout:  50 * time.Millisecond,
 			ReadTimeout:  50 * time.Millisecond,
@@ -82,6 +117,15 @@ func main() {
 
 		cacheR = &cache.Cache{}
 		cacheR.SetCacheImplementation(rdb)
+		cacheR.SetLogger(log)
+	}
+
+	// nil *cache.Cache в интерфейсе не равен nil: сервис должен видеть, что Redis нет
+	var svcCache cache.ICache
+	if cacheR != nil {
+		svcCache = cacheR
+	} else {
+		log.Warn("REDIS_ADDR is empty: login brute-force protection is disabled")
 	}
 
 	// Подключение к БД
@@ -98,31 +142,98 @@ func main() {
 	 */
 	pgRepository := repository.NewPGRepository(db, log)
 
+	// Рассылка событий клиентам WebSocket и SSE через Redis pub/sub
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	defer stopHub()
+
+	hub := stream.NewHub(&stream.HubConfig{Redis: rdb, Log: log, ReplaySize: cfg.StreamReplaySize})
+	hub.Start(hubCtx)
+
+	// Почта: подтверждение email и сброс пароля
+	var mail mailer.Mailer
+	switch cfg.MailerDriver {
+	case "smtp":
+		mail = mailer.NewSMTPMailer(&mailer.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	default:
+		mailDir := cfg.MailDir
+		if cfg.MailerDriver == "log" {
+			mailDir = ""
+		}
+		if mail, err = mailer.NewFileMailer(&mailer.FileConfig{Dir: mailDir, From: cfg.MailFrom, Log: log}); err != nil {
+			log.Fatalf("failed to init mailer: %v", err)
+		}
+	}
+
 	/*
 	 * service layer
 	 */
 	srv, err := service.NewService(&service.SConfig{
 		PGRepository: pgRepository,
-		Cache:        cacheR,
+		Cache:        svcCache,
 		Log:          log,
 		Config:       cfg,
+		Publisher:    hub,
+		Keys:         keys,
+		SecretBox:    secrets,
+		Mailer:       mail,
 	})
 	if err != nil {
 		log.Fatalf("failed to init service: %v", err)
 	}
 
+	// Подкоманда: создать первого администратора и выйти, не поднимая сервер
+	if len(os.Args) > 1 && os.Args[1] == bootstrapCommand {
+		if err := runBootstrapCommand(srv, os.Args[2:], cfg.BootstrapAdminEmail, cfg.BootstrapAdminPassword); err != nil {
+			log.Fatalf("%s: %v", bootstrapCommand, err)
+		}
+		return
+	}
+
+	// Первый администратор из окружения; если администратор уже есть, ничего не делаем
+	if cfg.BootstrapAdminEmail != "" {
+		id, err := bootstrapAdmin(context.Background(), srv, cfg.BootstrapAdminEmail, cfg.BootstrapAdminPassword)
+		switch {
+		case errors.Is(err, entities.ErrAdminExists):
+			log.Info("bootstrap admin skipped: an administrator already exists")
+		case err != nil:
+			log.Fatalf("failed to bootstrap admin: %v", err)
+		default:
+			log.Infof("bootstrap admin %s created (id=%d)", cfg.BootstrapAdminEmail, id)
+		}
+	}
+
+	// Поднимаем стаканы из открытых лимитных заявок
+	if err := srv.RestoreOrderBooks(context.Background()); err != nil {
+		log.Fatalf("failed to restore order books: %v", err)
+	}
+
 	cmd, query := cqrs.NewCQRS(srv)
 
 	wrk := worker.NewWorker(&worker.WorkerConfig{
 		Service: srv,
 		Log:     log,
+		Config:  cfg,
+		Keys:    keys,
 	})
 	wrk.Start()
 
 	// Gin
 	router := gin.New()
+	if err := router.SetTrustedProxies(splitList(cfg.TrustedProxies)); err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
+	// токен в query принимается только потоковыми маршрутами и вырезается до логгера
+	router.Use(middleware.QueryToken("/api/ws", "/api/stream/prices"))
 	router.Use(gin.LoggerWithConfig(gin.LoggerConfig{SkipPaths: []string{"/health"}}))
 	router.Use(gin.Recovery())
+	// IP и id запроса для журнала аудита
+	router.Use(middleware.RequestMeta())
 
 	// Swagger route
 	docs.SwaggerInfo.BasePath = "/api"
@@ -139,7 +250,8 @@ func main() {
 			"http://localhost:8080",
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"X-Content-Type, Content-Length", "Content-Type", "Authorization", "Accept"},
+		AllowHeaders:     []string{"X-Content-Type, Content-Length", "Content-Type", "Authorization", "Accept", "Last-Event-ID", middleware.IdempotencyHeader, middleware.APIKeyHeader, middleware.TOTPHeader, middleware.RequestIDHeader},
+		ExposeHeaders:    []string{middleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
@@ -167,72 +279,151 @@ func main() {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
 
-	authHandler := handler.NewAuthHandler(cmd, query, cfg)
+	authHandler := handler.NewAuthHandler(cmd, query, keys)
+	router.GET("/.well-known/jwks.json", authHandler.JWKS)
 	userHandler := handler.NewUserHandler(cmd, query)
 	stockHandler := handler.NewStockHandler(cmd, query, log)
 	orderHandler := handler.NewOrderHandler(cmd, query)
 	portfolioHandler := handler.NewPortfolioHandler(cmd, query)
 	historyHandler := handler.NewHistoryHandler(cmd, query)
+	accountHandler := handler.NewAccountHandler(cmd, query)
+	roleHandler := handler.NewRoleHandler(cmd, query)
+	apiKeyHandler := handler.NewAPIKeyHandler(cmd, query)
+	twoFactorHandler := handler.NewTwoFactorHandler(cmd, query)
+	inviteHandler := handler.NewInviteHandler(cmd, query)
+	auditHandler := handler.NewAuditHandler(query)
+	streamHandler := handler.NewStreamHandler(query, hub, corsConfig.AllowOrigins, log)
+
+	idempotency := middleware.Idempotency(srv, time.Duration(cfg.IdempotencyTTLHours)*time.Hour)
+
+	// права ролей лежат в stock_role_permission; маршрут требует право, а не роль
+	authn := middleware.AuthMiddleware(keys, query)
+	can := middleware.RequirePermission
+	// вывод денег, выпуск ключей и выдача ролей требуют свежий код второго фактора, если он включён
+	stepUp := middleware.RequireStepUp(cmd)
 
 	api := router.Group("/api")
 	{
 		api.POST("/register", authHandler.Register)
 		api.POST("/login", authHandler.Login)
+		api.POST("/login/2fa", authHandler.LoginTwoFactor)
+		api.POST("/token/refresh", authHandler.Refresh)
+		api.POST("/logout", authn, authHandler.Logout)
+		api.POST("/email/verification", authn, authHandler.RequestEmailVerification)
+		api.POST("/email/verify", authHandler.VerifyEmail)
+		api.POST("/password/forgot", authHandler.ForgotPassword)
+		api.POST("/password/reset", authHandler.ResetPassword)
+		api.GET("/ws", authn, streamHandler.Connect)
+
+		streams := api.Group("/stream")
+		streams.Use(authn)
+		{
+			streams.GET("/prices", can(entities.PermStocksRead), streamHandler.Prices)
+		}
 
 		users := api.Group("/users")
-		users.Use(middleware.AuthMiddleware(cfg))
+		users.Use(authn)
 		{
+			users.POST("", can(entities.PermUsersAdmin), stepUp, userHandler.CreateUser)
 			users.GET("/me", userHandler.GetMe)
+			users.GET("/all", can(entities.PermUsersReadAny), userHandler.GetAllUsers)
+
+			users.GET("/:id", can(entities.PermUsersReadAny), userHandler.GetUserByID)
+			users.PUT("/:id", can(entities.PermUsersAdmin), userHandler.UpdateUser)
+			users.DELETE("/:id", can(entities.PermUsersAdmin), userHandler.DeleteUser)
+			users.POST("/:id/unlock", can(entities.PermUsersAdmin), userHandler.UnlockUser)
 		}
 
-		admin := api.Group("/users")
-		admin.Use(middleware.AuthMiddleware(cfg, "ADMIN"))
+		stocks := api.Group("/stocks")
+		stocks.Use(authn)
 		{
-			admin.GET("/all", userHandler.GetAllUsers)
+			stocks.GET("/", can(entities.PermStocksRead), stockHandler.GetAllStocks)
+			stocks.GET("/:id", can(entities.PermStocksRead), stockHandler.GetStockByID)
+			stocks.GET("/:id/candles", can(entities.PermStocksRead), stockHandler.GetCandles)
 
-			admin.GET("/:id", userHandler.GetUserByID)
-			admin.PUT("/:id", userHandler.UpdateUser)
-			admin.DELETE("/:id", userHandler.DeleteUser)
+			stocks.POST("/", can(entities.PermStocksWrite), stockHandler.CreateStock)
+			stocks.PUT("/:id", can(entities.PermStocksWrite), stockHandler.UpdateStock)
+			stocks.DELETE("/:id", can(entities.PermStocksWrite), stockHandler.DeleteStock)
 		}
 
-		stocks := api.Group("/stocks")
-		stocks.Use(middleware.AuthMiddleware(cfg))
+		orders := api.Group("/orders")
+		orders.Use(authn)
 		{
-			stocks.GET("/", stockHandler.GetAllStocks)
-			stocks.GET("/:id", stockHandler.GetStockByID)
+			orders.POST("/", can(entities.PermOrdersWrite), idempotency, orderHandler.CreateOrder)
+			orders.GET("/user/:user_id", can(entities.PermOrdersRead), orderHandler.GetOrdersByUser)
+			orders.GET("/me", can(entities.PermOrdersRead), orderHandler.GetOrdersByUser)
+			orders.PUT("/:id/status", can(entities.PermOrdersWrite), orderHandler.UpdateOrderStatus)
+			orders.POST("/:id/cancel", can(entities.PermOrdersWrite), orderHandler.CancelOrder)
 		}
 
-		adminStocks := api.Group("/stocks")
-		adminStocks.Use(middleware.AuthMiddleware(cfg, "ADMIN"))
+		portfolio := api.Group("/portfolio")
+		portfolio.Use(authn)
 		{
-			adminStocks.POST("/", stockHandler.CreateStock)
-			adminStocks.PUT("/:id", stockHandler.UpdateStock)
-			adminStocks.DELETE("/:id", stockHandler.DeleteStock)
+			portfolio.GET("/:user_id/:stock_id", can(entities.PermPortfolioRead), portfolioHandler.GetPortfolio)
+			portfolio.POST("/", can(entities.PermPortfolioWriteAny), portfolioHandler.CreateOrUpdatePortfolio)
+			portfolio.GET("/me", can(entities.PermPortfolioRead), portfolioHandler.GetMyPortfolio)
+			portfolio.PUT("/cost-basis", can(entities.PermPortfolioWrite), portfolioHandler.SetCostBasisMethod)
 		}
 
-		orders := api.Group("/orders")
-		orders.Use(middleware.AuthMiddleware(cfg))
+		history := api.Group("/history")
+		history.Use(authn)
 		{
-			orders.POST("/", orderHandler.CreateOrder)
-			orders.GET("/user/:user_id", orderHandler.GetOrdersByUser)
-			orders.GET("/me", orderHandler.GetOrdersByUser)
-			orders.PUT("/:id/status", orderHandler.UpdateOrderStatus)
+			history.POST("/", can(entities.PermHistoryWrite), historyHandler.AddHistory)
+			history.GET("/user/:user_id", can(entities.PermHistoryRead), historyHandler.GetHistoryByUser)
+			history.GET("/me", can(entities.PermHistoryRead), historyHandler.GetHistoryByUser)
 		}
 
-		portfolio := api.Group("/portfolio")
-		portfolio.Use(middleware.AuthMiddleware(cfg))
+		account := api.Group("/account")
+		account.Use(authn)
 		{
-			portfolio.GET("/:user_id/:stock_id", portfolioHandler.GetPortfolio)
-			portfolio.POST("/", portfolioHandler.CreateOrUpdatePortfolio)
-			portfolio.GET("/me", portfolioHandler.GetMyPortfolio)
+			account.POST("/deposit", can(entities.PermAccountWrite), idempotency, accountHandler.Deposit)
+			account.POST("/withdraw", can(entities.PermAccountWrite), stepUp, idempotency, accountHandler.Withdraw)
+			account.GET("/ledger", can(entities.PermAccountRead), accountHandler.GetLedger)
+			account.GET("/reconcile", can(entities.PermLedgerReconcile), accountHandler.Reconcile)
 		}
 
-		history := api.Group("/history")
-		history.Use(middleware.AuthMiddleware(cfg))
+		// ключами управляют только из сессии, выпустить ключ ключом нельзя
+		apiKeys := api.Group("/api-keys")
+		apiKeys.Use(authn, middleware.SessionOnly())
+		{
+			apiKeys.POST("", stepUp, apiKeyHandler.CreateAPIKey)
+			apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+		}
+
+		twoFactor := api.Group("/2fa")
+		twoFactor.Use(authn, middleware.SessionOnly())
 		{
-			history.POST("/", historyHandler.AddHistory)
-			history.GET("/user/:user_id", historyHandler.GetHistoryByUser)
-			history.GET("/me", historyHandler.GetHistoryByUser)
+			twoFactor.GET("", twoFactorHandler.GetStatus)
+			twoFactor.POST("/enroll", twoFactorHandler.Enroll)
+			twoFactor.POST("/confirm", twoFactorHandler.Confirm)
+			twoFactor.POST("/disable", twoFactorHandler.Disable)
+			twoFactor.POST("/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes)
+		}
+
+		// приглашения: так заводятся администраторы, публичная регистрация даёт только TRADER
+		invites := api.Group("/invites")
+		invites.Use(authn, can(entities.PermUsersAdmin))
+		{
+			invites.POST("", stepUp, inviteHandler.CreateInvite)
+			invites.GET("", inviteHandler.ListInvites)
+			invites.DELETE("/:id", inviteHandler.RevokeInvite)
+		}
+
+		roles := api.Group("")
+		roles.Use(authn, can(entities.PermRolesAdmin))
+		{
+			roles.GET("/roles", roleHandler.ListRoles)
+			roles.PUT("/roles/:name", roleHandler.SetRole)
+			roles.GET("/permissions", roleHandler.ListPermissions)
+		}
+
+		// журнал только читается: записи добавляет сервис, изменить их нельзя
+		auditLog := api.Group("/audit")
+		auditLog.Use(authn, can(entities.PermAuditRead))
+		{
+			auditLog.GET("", auditHandler.ListAuditLog)
+			auditLog.GET("/verify", auditHandler.VerifyAuditLog)
 		}
 	}
 
@@ -253,6 +444,32 @@ func main() {
 		}
 	}()
 
+	// gRPC server
+	grpcImpl := grpcserver.NewServer(&grpcserver.ServerConfig{
+		Command: cmd,
+		Query:   query,
+		Hub:     hub,
+		Keys:    keys,
+		Log:     log,
+	})
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpcImpl.UnaryAuthInterceptor),
+		grpc.ChainStreamInterceptor(grpcImpl.StreamAuthInterceptor),
+	)
+	pb.RegisterStockServiceServer(grpcServer, grpcImpl)
+
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", cfg.ListenGRPCPort))
+	if err != nil {
+		log.Fatalf("failed to listen gRPC port: %v", err)
+	}
+
+	go func() {
+		log.Infof("gRPC server started on %s", grpcListener.Addr())
+		if err := grpcServer.Serve(grpcListener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			log.Fatalf("gRPC server error: %v", err)
+		}
+	}()
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -267,6 +484,31 @@ func main() {
 		log.Errorf("HTTP server forced to shutdown: %v", err)
 	}
 
+	// GracefulStop ждёт завершения RPC; по таймауту обрываем оставшиеся
+	grpcStopped := make(chan struct{})
+	grpcImpl.CloseStreams()
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		log.Warn("gRPC server forced to stop")
+		grpcServer.Stop()
+	}
+
 	wrk.Stop()
 	log.Info("Server exited properly")
 }
+
+// splitList разбирает список через запятую из переменной окружения.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
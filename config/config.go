@@ -1,9 +1,11 @@
 package config
 
 import (
+	"errors"
 	"log"
 
 	"github.com/kelseyhightower/envconfig"
+	"github.com/shopspring/decimal"
 )
 
 // Config read only.
@@ -13,9 +15,115 @@ type Config struct {
 	PostgresAddr   string `envconfig:"POSTGRES_ADDR" default:""`
 	RedisAddr      string `envconfig:"REDIS_ADDR" default:"redis:6379"`
 	JWTSecret      string `envconfig:"JWT_SECRET" default:"supersecretkey"`
-	JWTTTLMinutes  int    `envconfig:"JWT_TTL_MINUTES" default:"60"`
+	JWTTTLMinutes  int    `envconfig:"JWT_TTL_MINUTES" default:"15"`
+
+	// секрет по умолчанию допустим только при явном APP_ENV=development;
+	// пустое значение считается production
+	AppEnv string `envconfig:"APP_ENV" default:""`
+
+	// RS256/EdDSA: токены подписываются приватным ключом, проверить их можно по публичным
+	// ключам из /.well-known/jwks.json. Ротация: новый публичный ключ кладётся в каталог
+	// проверки, затем меняется ключ подписи; каталог перечитывается без рестарта.
+	JWTAlgorithm           string `envconfig:"JWT_ALG" default:"HS256"`         // HS256, RS256, EdDSA
+	JWTSigningKeyFile      string `envconfig:"JWT_SIGNING_KEY_FILE" default:""` // PEM приватного ключа
+	JWTSigningKeyID        string `envconfig:"JWT_SIGNING_KEY_ID" default:""`   // kid, по умолчанию — имя файла
+	JWTVerificationKeysDir string `envconfig:"JWT_VERIFICATION_KEYS_DIR" default:""`
+	JWTKeyReloadSeconds    int    `envconfig:"JWT_KEY_RELOAD_SECONDS" default:"60"`
+
+	// refresh-токены живут на сервере и меняются при каждом обновлении access-токена
+	RefreshTokenTTLHours int `envconfig:"REFRESH_TOKEN_TTL_HOURS" default:"720"`
+	TokenCleanupMinutes  int `envconfig:"TOKEN_CLEANUP_MINUTES" default:"60"`
+
+	// права ролей кэшируются в памяти; правки из других инстансов видны через TTL
+	PermissionCacheSeconds int `envconfig:"PERMISSION_CACHE_SECONDS" default:"30"`
+
+	MaxAPIKeysPerUser int `envconfig:"MAX_API_KEYS_PER_USER" default:"10"`
+
+	// TOTP: секреты шифруются ключом (base64, 32 байта); без ключа 2FA подключить нельзя.
+	// Ключ нельзя менять, пока есть подключённые пользователи: их секреты станут нечитаемыми.
+	TOTPEncryptionKey        string `envconfig:"TOTP_ENCRYPTION_KEY" default:""`
+	TOTPIssuer               string `envconfig:"TOTP_ISSUER" default:"Stock"`
+	TOTPMaxAttempts          int    `envconfig:"TOTP_MAX_ATTEMPTS" default:"5"`
+	TOTPLockoutMinutes       int    `envconfig:"TOTP_LOCKOUT_MINUTES" default:"15"`
+	LoginChallengeTTLMinutes int    `envconfig:"LOGIN_CHALLENGE_TTL_MINUTES" default:"5"`
+	RecoveryCodeCount        int    `envconfig:"RECOVERY_CODE_COUNT" default:"10"`
+
+	// защита от подбора пароля (нужен Redis): после N неудач в окне вход блокируется,
+	// каждая следующая блокировка вдвое длиннее предыдущей, но не дольше максимума
+	LoginMaxAttemptsPerEmail  int `envconfig:"LOGIN_MAX_ATTEMPTS_PER_EMAIL" default:"5"`
+	LoginMaxAttemptsPerIP     int `envconfig:"LOGIN_MAX_ATTEMPTS_PER_IP" default:"20"`
+	LoginAttemptWindowMinutes int `envconfig:"LOGIN_ATTEMPT_WINDOW_MINUTES" default:"15"`
+	LoginLockoutBaseSeconds   int `envconfig:"LOGIN_LOCKOUT_BASE_SECONDS" default:"60"`
+	LoginLockoutMaxMinutes    int `envconfig:"LOGIN_LOCKOUT_MAX_MINUTES" default:"60"`
+
+	// неудачные входы пишутся в журнал аудита пачками из ограниченной очереди;
+	// при переполнении записи отбрасываются с предупреждением в лог
+	AuditQueueSize    int `envconfig:"AUDIT_QUEUE_SIZE" default:"1000"`
+	AuditFlushSeconds int `envconfig:"AUDIT_FLUSH_SECONDS" default:"1"`
+
+	// письма: smtp — настоящая отправка, file — .eml в MAIL_DIR, log — только в лог сервера
+	MailerDriver string `envconfig:"MAILER" default:"log"`
+	MailFrom     string `envconfig:"MAIL_FROM" default:"no-reply@stock.local"`
+	MailDir      string `envconfig:"MAIL_DIR" default:"./mail"`
+	SMTPHost     string `envconfig:"SMTP_HOST" default:""`
+	SMTPPort     int    `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME" default:""`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD" default:""`
+
+	// ссылки в письмах ведут на фронтенд: APP_BASE_URL/verify-email?token=..., APP_BASE_URL/reset-password?token=...
+	AppBaseURL                string `envconfig:"APP_BASE_URL" default:"http://localhost:3000"`
+	EmailVerificationTTLHours int    `envconfig:"EMAIL_VERIFICATION_TTL_HOURS" default:"48"`
+	PasswordResetTTLMinutes   int    `envconfig:"PASSWORD_RESET_TTL_MINUTES" default:"30"`
+	PasswordResetsPerHour     int    `envconfig:"PASSWORD_RESETS_PER_HOUR" default:"3"` // на один email
+
+	// приглашения на регистрацию с ролью; код действует INVITE_TTL_HOURS, если админ не задал свой срок
+	InviteTTLHours int `envconfig:"INVITE_TTL_HOURS" default:"72"`
+
+	// первый администратор: создаётся при старте, если в системе ещё нет ни одного ADMIN.
+	// То же делает подкоманда `bootstrap-admin -email ... -password ...`.
+	BootstrapAdminEmail    string `envconfig:"BOOTSTRAP_ADMIN_EMAIL" default:""`
+	BootstrapAdminPassword string `envconfig:"BOOTSTRAP_ADMIN_PASSWORD" default:""`
+
+	// прокси, которым доверяем X-Forwarded-For (через запятую); пусто — IP клиента берётся из соединения.
+	// От этого зависят allowlist'ы IP у API-ключей.
+	TrustedProxies string `envconfig:"TRUSTED_PROXIES" default:""`
+
+	StopOrderCheckSeconds   int    `envconfig:"STOP_ORDER_CHECK_SECONDS" default:"5"`
+	OrderExpiryCheckSeconds int    `envconfig:"ORDER_EXPIRY_CHECK_SECONDS" default:"30"`
+	SessionCloseUTC         string `envconfig:"SESSION_CLOSE_UTC" default:"21:00"` // закрытие сессии для DAY-заявок, HH:MM
+
+	IdempotencyTTLHours int `envconfig:"IDEMPOTENCY_TTL_HOURS" default:"24"`
+
+	LedgerReconcileMinutes int `envconfig:"LEDGER_RECONCILE_MINUTES" default:"60"`
+
+	// сырые тики старше окна хранения сворачиваются в минутные бары
+	PriceTickRetentionHours    int `envconfig:"PRICE_TICK_RETENTION_HOURS" default:"24"`
+	PriceTickCompactionMinutes int `envconfig:"PRICE_TICK_COMPACTION_MINUTES" default:"15"`
+
+	// последние события стрима для докачки SSE по Last-Event-ID
+	StreamReplaySize int64 `envconfig:"STREAM_REPLAY_SIZE" default:"1000"`
+
+	// симуляция рынка: воркер пишет цены из фида как обычное изменение цены
+	PriceFeedEnabled         bool    `envconfig:"PRICE_FEED_ENABLED" default:"false"`
+	PriceFeedSource          string  `envconfig:"PRICE_FEED_SOURCE" default:"simulator"` // simulator, replay
+	PriceFeedIntervalSeconds int     `envconfig:"PRICE_FEED_INTERVAL_SECONDS" default:"5"`
+	PriceFeedSeed            int64   `envconfig:"PRICE_FEED_SEED" default:"42"`
+	PriceFeedDrift           float64 `envconfig:"PRICE_FEED_DRIFT" default:"0.05"`     // годовой μ
+	PriceFeedVolatility      float64 `envconfig:"PRICE_FEED_VOLATILITY" default:"0.3"` // годовая σ
+	PriceFeedParams          string  `envconfig:"PRICE_FEED_PARAMS" default:""`        // AAPL:0.08:0.25,TSLA:0.1:0.6
+	PriceFeedReplayFile      string  `envconfig:"PRICE_FEED_REPLAY_FILE" default:""`   // CSV timestamp,symbol,price
+	PriceFeedReplayLoop      bool    `envconfig:"PRICE_FEED_REPLAY_LOOP" default:"true"`
+
+	// шаг цены и лот для новых акций, если админ не задал свои
+	DefaultTickSize decimal.Decimal `envconfig:"DEFAULT_TICK_SIZE" default:"0.01"`
+	DefaultLotSize  decimal.Decimal `envconfig:"DEFAULT_LOT_SIZE" default:"1"`
 }
 
+const (
+	defaultJWTSecret = "supersecretkey"
+	envDevelopment   = "development"
+)
+
 // New Config constructor.
 func New() *Config {
 	return &Config{}
@@ -27,3 +135,31 @@ func (r *Config) Init() {
 		log.Fatalf("failed to load configuration: %s", err)
 	}
 }
+
+// env — окружение для сообщений; без APP_ENV это production.
+func (r *Config) env() string {
+	if r.AppEnv == "" {
+		return "production"
+	}
+	return r.AppEnv
+}
+
+// Validate отсекает небезопасную конфигурацию до старта сервера.
+func (r *Config) Validate() error {
+	if r.AppEnv != envDevelopment && (r.JWTAlgorithm == "" || r.JWTAlgorithm == "HS256") && r.JWTSecret == defaultJWTSecret {
+		return errors.New("JWT_SECRET must be changed from the default unless APP_ENV=development (APP_ENV=" + r.env() + ")")
+	}
+	if r.AuditQueueSize < 1 || r.AuditFlushSeconds < 1 {
+		return errors.New("AUDIT_QUEUE_SIZE and AUDIT_FLUSH_SECONDS must be positive")
+	}
+	switch r.MailerDriver {
+	case "smtp":
+		if r.SMTPHost == "" {
+			return errors.New("SMTP_HOST is required when MAILER=smtp")
+		}
+	case "file", "log":
+	default:
+		return errors.New("MAILER must be smtp, file or log")
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestValidateJWTSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		alg     string
+		secret  string
+		wantErr bool
+	}{
+		{"unset env is production", "", "HS256", defaultJWTSecret, true},
+		{"production", "production", "HS256", defaultJWTSecret, true},
+		{"staging", "staging", "", defaultJWTSecret, true},
+		{"explicit development", "development", "HS256", defaultJWTSecret, false},
+		{"custom secret without env", "", "HS256", "another-secret", false},
+		{"asymmetric keys ignore secret", "", "RS256", defaultJWTSecret, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{AppEnv: tt.env, JWTAlgorithm: tt.alg, JWTSecret: tt.secret, MailerDriver: "log", AuditQueueSize: 1, AuditFlushSeconds: 1}
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -15,7 +15,63 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/history/": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "JSON Web Key Set (RFC 7517). Tokens carry the key id in the kid header. Empty when tokens are signed with HS256.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Public keys for verifying access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_auth.JWKS"
+                        }
+                    }
+                }
+            }
+        },
+        "/2fa": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "two-factor"
+                ],
+                "summary": "Two-factor authentication status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.TwoFactorStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/2fa/confirm": {
             "post": {
                 "security": [
                     {
@@ -29,25 +85,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "history"
+                    "two-factor"
                 ],
-                "summary": "Add history record",
+                "summary": "Confirm TOTP enrollment with the first code and get recovery codes",
                 "parameters": [
                     {
-                        "description": "History payload",
+                        "description": "Code from the authenticator app",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
+                            "$ref": "#/definitions/internal_handler.TOTPCodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.HistoryCreatedResponse"
+                            "$ref": "#/definitions/internal_handler.RecoveryCodesResponse"
                         }
                     },
                     "400": {
@@ -62,6 +118,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -71,28 +139,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/history/me": {
-            "get": {
+        "/2fa/disable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "history"
+                    "two-factor"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.TOTPCodeRequest"
+                        }
+                    }
                 ],
-                "summary": "Get history (admin can pass user_id, trader gets own)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
-                            }
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
                         }
                     },
                     "400": {
@@ -107,6 +186,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -116,46 +207,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/history/user/{user_id}": {
-            "get": {
+        "/2fa/enroll": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Returns a new secret and an otpauth:// URI for the authenticator app. Two-factor is enabled only after /2fa/confirm; calling this again replaces an unconfirmed secret.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "history"
-                ],
-                "summary": "Get history (admin can pass user_id, trader gets own)",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID (admin only)",
-                        "name": "user_id",
-                        "in": "path"
-                    }
+                    "two-factor"
                 ],
+                "summary": "Start TOTP enrollment",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
-                            }
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.TOTPEnrollment"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -165,12 +246,24 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/login": {
+        "/2fa/recovery-codes": {
             "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Previously issued recovery codes stop working.",
                 "consumes": [
                     "application/json"
                 ],
@@ -178,17 +271,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "two-factor"
                 ],
-                "summary": "Login",
+                "summary": "Replace recovery codes",
                 "parameters": [
                     {
-                        "description": "Login payload",
+                        "description": "TOTP or recovery code",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.LoginRequest"
+                            "$ref": "#/definitions/internal_handler.TOTPCodeRequest"
                         }
                     }
                 ],
@@ -196,7 +289,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.LoginResponse"
+                            "$ref": "#/definitions/internal_handler.RecoveryCodesResponse"
                         }
                     },
                     "400": {
@@ -211,6 +304,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -220,7 +325,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/": {
+        "/account/deposit": {
             "post": {
                 "security": [
                     {
@@ -234,25 +339,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "account"
                 ],
-                "summary": "Create order",
+                "summary": "Deposit funds",
                 "parameters": [
                     {
-                        "description": "Order payload",
+                        "type": "string",
+                        "description": "Idempotency key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Deposit payload",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.CreateOrderRequest"
+                            "$ref": "#/definitions/internal_handler.FundsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.OrderCreatedResponse"
+                            "$ref": "#/definitions/internal_handler.FundsResponse"
                         }
                     },
                     "400": {
@@ -267,6 +378,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -276,7 +393,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/me": {
+        "/account/ledger": {
             "get": {
                 "security": [
                     {
@@ -287,25 +404,19 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "account"
                 ],
-                "summary": "Get orders (admin can pass user_id, trader gets own)",
+                "summary": "Get my ledger entries",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Order"
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.LedgerEntry"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
@@ -321,7 +432,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/user/{user_id}": {
+        "/account/reconcile": {
             "get": {
                 "security": [
                     {
@@ -332,35 +443,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
-                ],
-                "summary": "Get orders (admin can pass user_id, trader gets own)",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID (admin only)",
-                        "name": "user_id",
-                        "in": "path"
-                    }
+                    "account"
                 ],
+                "summary": "Reconcile user balances against the ledger (ledger:reconcile)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Order"
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.BalanceMismatch"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -374,8 +477,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/orders/{id}/status": {
-            "put": {
+        "/account/withdraw": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -388,24 +491,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "orders"
+                    "account"
                 ],
-                "summary": "Update order status (admin or owner)",
+                "summary": "Withdraw funds",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Order ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "string",
+                        "description": "Idempotency key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     },
                     {
-                        "description": "New status",
+                        "type": "string",
+                        "description": "TOTP or recovery code, required when two-factor is enabled",
+                        "name": "X-TOTP-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Withdraw payload",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.UpdateOrderStatusRequest"
+                            "$ref": "#/definitions/internal_handler.FundsRequest"
                         }
                     }
                 ],
@@ -413,7 +521,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                            "$ref": "#/definitions/internal_handler.FundsResponse"
                         }
                     },
                     "400": {
@@ -428,14 +536,47 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "api-keys"
+                ],
+                "summary": "List my active API keys",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.APIKey"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -447,15 +588,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/portfolio/": {
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "The key is returned only once. Send it in the X-API-Key header instead of a Bearer token. Scopes: read, trade, withdraw. A key never gets more permissions than its owner's role.",
                 "consumes": [
                     "application/json"
                 ],
@@ -463,25 +603,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "portfolio"
+                    "api-keys"
                 ],
-                "summary": "Create or update portfolio",
+                "summary": "Create a personal API key",
                 "parameters": [
                     {
-                        "description": "Payload",
+                        "type": "string",
+                        "description": "TOTP or recovery code, required when two-factor is enabled",
+                        "name": "X-TOTP-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Key parameters",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.CreateOrUpdatePortfolioRequest"
+                            "$ref": "#/definitions/internal_handler.CreateAPIKeyRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                            "$ref": "#/definitions/internal_handler.CreateAPIKeyResponse"
                         }
                     },
                     "400": {
@@ -496,6 +642,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -505,8 +663,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/portfolio/me": {
-            "get": {
+        "/api-keys/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -516,17 +674,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "portfolio"
+                    "api-keys"
+                ],
+                "summary": "Revoke one of my API keys",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get my portfolio",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Portfolio"
-                            }
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
                     "401": {
@@ -535,6 +705,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -544,40 +720,76 @@ const docTemplate = `{
                 }
             }
         },
-        "/portfolio/{user_id}/{stock_id}": {
+        "/audit": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Keyset pagination: pass next_before_id from the previous page as before_id.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "portfolio"
+                    "audit"
                 ],
-                "summary": "Get portfolio record (admin can specify user_id, trader gets own)",
+                "summary": "List audit log entries, newest first (audit:read)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID (admin only)",
-                        "name": "user_id",
-                        "in": "path"
+                        "description": "Actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. USER_UPDATE",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target type, e.g. user",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target ID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "From time (RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "To time (RFC3339)",
+                        "name": "to",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Stock ID",
-                        "name": "stock_id",
-                        "in": "path",
-                        "required": true
+                        "description": "Return entries with a smaller ID",
+                        "name": "before_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Portfolio"
+                            "$ref": "#/definitions/internal_handler.AuditLogResponse"
                         }
                     },
                     "400": {
@@ -592,6 +804,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -601,38 +819,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/register": {
-            "post": {
-                "consumes": [
-                    "application/json"
+        "/audit/verify": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Recomputes every entry hash; broken_at points to the first entry that was altered or follows a removed one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "audit"
                 ],
-                "summary": "Register new user",
-                "parameters": [
-                    {
-                        "description": "Register payload",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
+                "summary": "Verify the audit log hash chain (audit:read)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.RegisterRequest"
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.AuditVerification"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.RegisterResponse"
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -646,28 +862,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/stocks/": {
-            "get": {
+        "/email/verification": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "Previously sent links stop working. Trading is not allowed until the email is verified.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stocks"
+                    "auth"
                 ],
-                "summary": "Get all stocks",
+                "summary": "Send the email verification link again",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
-                            }
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
                         }
                     },
                     "401": {
@@ -676,6 +890,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -683,13 +903,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/email/verify": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -697,25 +914,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stocks"
+                    "auth"
                 ],
-                "summary": "Create stock (admin)",
+                "summary": "Confirm the email address with the token from the verification link",
                 "parameters": [
                     {
-                        "description": "Stock payload",
+                        "description": "Token from the link",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
+                            "$ref": "#/definitions/internal_handler.EmailTokenRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.IDResponse"
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
                         }
                     },
                     "400": {
@@ -724,18 +941,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -745,34 +950,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/stocks/{id}": {
-            "get": {
+        "/history/": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stocks"
+                    "history"
                 ],
-                "summary": "Get stock by ID",
+                "summary": "Add history record",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Stock ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "History payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
+                            "$ref": "#/definitions/internal_handler.HistoryCreatedResponse"
                         }
                     },
                     "400": {
@@ -787,12 +997,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -800,46 +1004,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/history/me": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "stocks"
-                ],
-                "summary": "Update stock (admin)",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Stock ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Partial update payload (symbol/name/price)",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
-                    }
+                    "history"
                 ],
+                "summary": "Get history (history:read:any can pass user_id, others get own)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
+                            }
                         }
                     },
                     "400": {
@@ -854,18 +1042,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -873,8 +1049,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/history/user/{user_id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -884,23 +1062,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "stocks"
+                    "history"
                 ],
-                "summary": "Delete stock (admin)",
+                "summary": "Get history (history:read:any can pass user_id, others get own)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Stock ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "User ID (requires history:read:any)",
+                        "name": "user_id",
+                        "in": "path"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.History"
+                            }
                         }
                     },
                     "400": {
@@ -915,12 +1095,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.ErrorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -930,7 +1104,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/all": {
+        "/invites": {
             "get": {
                 "security": [
                     {
@@ -941,16 +1115,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invites"
                 ],
-                "summary": "Get all users (admin)",
+                "summary": "List invites, newest first (users:admin)",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.User"
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Invite"
                             }
                         }
                     },
@@ -973,27 +1147,52 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/me": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "description": "The code is returned only once; the new user passes it as invite_code to /register. With email set, the code is also emailed and works only for that address.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invites"
                 ],
-                "summary": "Get my profile",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Create an invite code for a role (users:admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TOTP or recovery code, required when two-factor is enabled",
+                        "name": "X-TOTP-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Invite parameters",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.GetMeResponse"
+                            "$ref": "#/definitions/internal_handler.CreateInviteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.CreateInviteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
                     "401": {
@@ -1002,8 +1201,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -1011,8 +1216,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
+        "/invites/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
@@ -1022,13 +1227,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "invites"
                 ],
-                "summary": "Get user by ID (admin)",
+                "summary": "Revoke an unused invite (users:admin)",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "User ID",
+                        "description": "Invite ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1038,7 +1243,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.User"
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
                         }
                     },
                     "400": {
@@ -1052,15 +1257,83 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
                     }
                 }
-            },
-            "put": {
-                "security": [
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Repeated failures for the same email or from the same IP lock login temporarily, with the lockout doubling each time.\nIf two-factor authentication is enabled, responds 202 with a challenge token instead of tokens; finish with /login/2fa.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "description": "Login payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.LoginRequest"
+                        }
                     }
                 ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.LoginChallengeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed attempts; see the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login/2fa": {
+            "post": {
+                "description": "The code is a TOTP from the authenticator app or one of the recovery codes.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1068,24 +1341,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Update user (admin)",
+                "summary": "Second login step: exchange the challenge token and a two-factor code for tokens",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Update payload",
+                        "description": "Challenge and code",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.UpdateUserRequest"
+                            "$ref": "#/definitions/internal_handler.LoginTwoFactorRequest"
                         }
                     }
                 ],
@@ -1093,7 +1359,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                            "$ref": "#/definitions/internal_handler.LoginResponse"
                         }
                     },
                     "400": {
@@ -1102,8 +1368,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -1115,27 +1387,33 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "auth"
                 ],
-                "summary": "Delete user (admin)",
+                "summary": "Logout: revoke the current access token and its refresh token session",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Refresh token of the session",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.LogoutRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1145,8 +1423,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.MessageResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.ErrorResponse"
                         }
@@ -1159,72 +1437,2141 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "github_com_Skapar_backend_internal_models_entities.History": {
-            "type": "object",
-            "properties": {
-                "action": {
-                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.HistoryAction"
-                },
-                "amount": {
-                    "type": "number"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "details": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
-                "order_id": {
-                    "type": "integer"
-                },
-                "stock_id": {
-                    "type": "integer"
-                },
-                "user_id": {
-                    "type": "integer"
+        },
+        "/orders/": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create order",
+                "parameters": [
+                    {
+                        "description": "Order payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.CreateOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.OrderCreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
                 }
             }
         },
-        "github_com_Skapar_backend_internal_models_entities.HistoryAction": {
-            "type": "string",
-            "enum": [
-                "BUY",
-                "SELL",
-                "BALANCE_UPDATE",
-                "DEPOSIT",
-                "WITHDRAW"
-            ],
-            "x-enum-varnames": [
-                "ActionBuy",
-                "ActionSell",
-                "ActionBalanceUpdate",
-                "ActionDeposit",
-                "ActionWithdraw"
-            ]
-        },
-        "github_com_Skapar_backend_internal_models_entities.Order": {
-            "type": "object",
-            "properties": {
-                "created_at": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "integer"
-                },
+        "/orders/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get orders (orders:read:any can pass user_id, others get own)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/user/{user_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get orders (orders:read:any can pass user_id, others get own)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID (requires orders:read:any)",
+                        "name": "user_id",
+                        "in": "path"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Order"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel order (owner or orders:write:any)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/orders/{id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Update order status (owner may only cancel; orders:write:any allows any order and status)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.UpdateOrderStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Always responds 202, whether or not the email is registered.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a password reset link",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "All sessions of the user are revoked.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Set a new password with the token from the reset link",
+                "parameters": [
+                    {
+                        "description": "Token and new password",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/permissions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List all permissions that can be granted to roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.PermissionInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Adjust a position directly (requires portfolio:write:any)",
+                "parameters": [
+                    {
+                        "description": "Payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.CreateOrUpdatePortfolioRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/cost-basis": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Choose how sold shares are matched against tax lots",
+                "parameters": [
+                    {
+                        "description": "FIFO, LIFO or AVERAGE",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.CostBasisMethodRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Get my portfolio with cost basis and unrealized P\u0026L",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.PortfolioValuation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/portfolio/{user_id}/{stock_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "portfolio"
+                ],
+                "summary": "Get portfolio record (portfolio:read:any can specify user_id, others get own)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID (requires portfolio:read:any)",
+                        "name": "user_id",
+                        "in": "path"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Stock ID",
+                        "name": "stock_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Portfolio"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/register": {
+            "post": {
+                "description": "Creates a TRADER account. Other roles are granted only through an invite code or by an administrator.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register new user",
+                "parameters": [
+                    {
+                        "description": "Register payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List roles with their permissions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.RoleInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/roles/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The permission list replaces the current one. Other instances pick up the change within PERMISSION_CACHE_SECONDS.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a role or replace its permissions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name, e.g. SUPPORT",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role description and permissions",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.SetRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.RoleInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stocks/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Get all stocks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Create stock (stocks:write)",
+                "parameters": [
+                    {
+                        "description": "Stock payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.IDResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stocks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Get stock by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Stock"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Update stock (stocks:write)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Partial update payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.UpdateStockRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Delete stock (stocks:write)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stocks/{id}/candles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stocks"
+                ],
+                "summary": "Get OHLCV candles for a stock",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Stock ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "1m",
+                        "description": "Bar width: 1m, 5m, 1h or 1d",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 start (default: 100 bars before to)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 end, exclusive (default: now)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Candle"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stream/prices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Same events as the WebSocket price channels. Send Last-Event-ID (or last_event_id) to resume after a reconnect;\nevents still in the short replay buffer are sent first. The token may be passed as access_token query param.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "stream"
+                ],
+                "summary": "Server-Sent Events stream of price ticks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma-separated symbols, e.g. AAPL,MSFT",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Same as Last-Event-ID, for clients that cannot set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "JWT, for clients that cannot set headers",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.StreamEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/token/refresh": {
+            "post": {
+                "description": "The refresh token is single-use: a new one is returned every time. Presenting an already used token revokes the whole session.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Exchange a refresh token for a new token pair",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.RefreshRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The role must exist. A verification email is sent to the address.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user with any role (users:admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TOTP or recovery code, required when two-factor is enabled",
+                        "name": "X-TOTP-Code",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ProvisionUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/all": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get all users (users:read:any)",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.User"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get my profile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.GetMeResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID (users:read:any)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user (users:admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user (users:admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Clears the password lockout for the user's email and the two-factor lockout. IP lockouts expire on their own.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Clear login lockouts of a user (users:admin)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upgrade to WebSocket. The token may be sent as Authorization header or access_token query param.\nThe private channel user:{id} with order and fill events is joined automatically.\nClient messages: {\"action\":\"subscribe\"|\"unsubscribe\",\"symbols\":[\"AAPL\"]}. Server messages: StreamEvent or {\"type\":\"subscribed\"|\"error\"}.",
+                "tags": [
+                    "stream"
+                ],
+                "summary": "WebSocket for price ticks and own order updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT, for clients that cannot set headers",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.StreamEvent"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "github_com_Skapar_backend_internal_auth.JWK": {
+            "type": "object",
+            "properties": {
+                "alg": {
+                    "type": "string"
+                },
+                "crv": {
+                    "type": "string"
+                },
+                "e": {
+                    "type": "string"
+                },
+                "kid": {
+                    "type": "string"
+                },
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_auth.JWKS": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Skapar_backend_internal_auth.JWK"
+                    }
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.APIKey": {
+            "type": "object",
+            "properties": {
+                "allowed_ips": {
+                    "description": "IP или CIDR; пусто — без ограничений",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "по нему ключ узнают в списке",
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.AuditAction": {
+            "type": "string",
+            "enum": [
+                "USER_CREATE",
+                "USER_UPDATE",
+                "USER_DELETE",
+                "USER_UNLOCK",
+                "ADMIN_BOOTSTRAP",
+                "INVITE_CREATE",
+                "INVITE_REVOKE",
+                "ROLE_UPDATE",
+                "STOCK_CREATE",
+                "STOCK_UPDATE",
+                "STOCK_DELETE",
+                "PORTFOLIO_SET",
+                "ORDER_STATUS_SET",
+                "REGISTER",
+                "LOGIN",
+                "LOGIN_FAILED",
+                "LOGIN_LOCKOUT",
+                "LOGOUT",
+                "REFRESH_TOKEN_REUSE",
+                "TWO_FACTOR_ENABLE",
+                "TWO_FACTOR_DISABLE",
+                "TWO_FACTOR_FAILED",
+                "RECOVERY_CODES_RESET",
+                "API_KEY_CREATE",
+                "API_KEY_REVOKE",
+                "EMAIL_VERIFY",
+                "PASSWORD_RESET"
+            ],
+            "x-enum-comments": {
+                "AuditOrderStatusSet": "смена статуса чужой заявки"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "смена статуса чужой заявки",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "AuditUserCreate",
+                "AuditUserUpdate",
+                "AuditUserDelete",
+                "AuditUserUnlock",
+                "AuditAdminBootstrap",
+                "AuditInviteCreate",
+                "AuditInviteRevoke",
+                "AuditRoleUpdate",
+                "AuditStockCreate",
+                "AuditStockUpdate",
+                "AuditStockDelete",
+                "AuditPortfolioSet",
+                "AuditOrderStatusSet",
+                "AuditRegister",
+                "AuditLogin",
+                "AuditLoginFailed",
+                "AuditLoginLockout",
+                "AuditLogout",
+                "AuditRefreshReuse",
+                "AuditTwoFactorEnable",
+                "AuditTwoFactorDisable",
+                "AuditTwoFactorFailed",
+                "AuditRecoveryCodesReset",
+                "AuditAPIKeyCreate",
+                "AuditAPIKeyRevoke",
+                "AuditEmailVerify",
+                "AuditPasswordReset"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.AuditEntry": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.AuditAction"
+                },
+                "actor_id": {
+                    "description": "nil — система или анонимный запрос",
+                    "type": "integer"
+                },
+                "actor_role": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "только изменившиеся поля",
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "string"
+                },
+                "target_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.AuditVerification": {
+            "type": "object",
+            "properties": {
+                "broken_at": {
+                    "description": "первая запись, не сходящаяся с цепочкой",
+                    "type": "integer"
+                },
+                "checked": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.BalanceMismatch": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string"
+                },
+                "ledger_balance": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.Candle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "string"
+                },
+                "high": {
+                    "type": "string"
+                },
+                "low": {
+                    "type": "string"
+                },
+                "open": {
+                    "type": "string"
+                },
+                "open_time": {
+                    "type": "string"
+                },
+                "volume": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.CostBasisMethod": {
+            "type": "string",
+            "enum": [
+                "FIFO",
+                "LIFO",
+                "AVERAGE"
+            ],
+            "x-enum-comments": {
+                "CostBasisAverage": "по средней цене всех открытых лотов",
+                "CostBasisFIFO": "сначала самые старые лоты",
+                "CostBasisLIFO": "сначала самые новые лоты"
+            },
+            "x-enum-descriptions": [
+                "сначала самые старые лоты",
+                "сначала самые новые лоты",
+                "по средней цене всех открытых лотов"
+            ],
+            "x-enum-varnames": [
+                "CostBasisFIFO",
+                "CostBasisLIFO",
+                "CostBasisAverage"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.History": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.HistoryAction"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "order_id": {
+                    "type": "integer"
+                },
+                "realized_pnl": {
+                    "description": "только у SELL",
+                    "type": "string"
+                },
+                "stock_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.HistoryAction": {
+            "type": "string",
+            "enum": [
+                "BUY",
+                "SELL",
+                "BALANCE_UPDATE",
+                "DEPOSIT",
+                "WITHDRAW",
+                "CANCEL",
+                "EXPIRE",
+                "STOP_TRIGGERED",
+                "LOGIN_LOCKOUT",
+                "LOGIN_UNLOCK"
+            ],
+            "x-enum-varnames": [
+                "ActionBuy",
+                "ActionSell",
+                "ActionBalanceUpdate",
+                "ActionDeposit",
+                "ActionWithdraw",
+                "ActionCancel",
+                "ActionExpire",
+                "ActionStopTriggered",
+                "ActionLoginLockout",
+                "ActionLoginUnlock"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.Invite": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "integer"
+                },
+                "email": {
+                    "description": "пусто — код подходит для любого адреса",
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Role"
+                },
+                "used_at": {
+                    "type": "string"
+                },
+                "used_by": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.JournalKind": {
+            "type": "string",
+            "enum": [
+                "OPENING",
+                "DEPOSIT",
+                "WITHDRAW",
+                "TRADE",
+                "ADJUSTMENT"
+            ],
+            "x-enum-varnames": [
+                "JournalOpening",
+                "JournalDeposit",
+                "JournalWithdraw",
+                "JournalTrade",
+                "JournalAdjustment"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.LedgerEntry": {
+            "type": "object",
+            "properties": {
+                "account_code": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "journal_id": {
+                    "type": "integer"
+                },
+                "journal_kind": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.JournalKind"
+                },
+                "reference": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.Order": {
+            "type": "object",
+            "properties": {
+                "activated_at": {
+                    "type": "string"
+                },
+                "avg_fill_price": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "filled_quantity": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.OrderKind"
+                },
+                "limit_price": {
+                    "type": "string"
+                },
                 "order_type": {
                     "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.OrderType"
                 },
                 "price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "status": {
                     "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.OrderStatus"
@@ -1232,6 +3579,15 @@ const docTemplate = `{
                 "stock_id": {
                     "type": "integer"
                 },
+                "time_in_force": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.TimeInForce"
+                },
+                "trailing_offset": {
+                    "type": "string"
+                },
+                "trigger_price": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -1240,17 +3596,52 @@ const docTemplate = `{
                 }
             }
         },
+        "github_com_Skapar_backend_internal_models_entities.OrderKind": {
+            "type": "string",
+            "enum": [
+                "MARKET",
+                "LIMIT",
+                "STOP",
+                "STOP_LIMIT",
+                "TRAILING_STOP"
+            ],
+            "x-enum-comments": {
+                "OrderStop": "по срабатыванию становится MARKET",
+                "OrderStopLimit": "по срабатыванию становится LIMIT",
+                "OrderTrailingStop": "STOP, у которого trigger следует за ценой"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "по срабатыванию становится MARKET",
+                "по срабатыванию становится LIMIT",
+                "STOP, у которого trigger следует за ценой"
+            ],
+            "x-enum-varnames": [
+                "OrderMarket",
+                "OrderLimit",
+                "OrderStop",
+                "OrderStopLimit",
+                "OrderTrailingStop"
+            ]
+        },
         "github_com_Skapar_backend_internal_models_entities.OrderStatus": {
             "type": "string",
             "enum": [
-                "PENDING",
-                "COMPLETED",
-                "FAILED"
+                "NEW",
+                "PARTIALLY_FILLED",
+                "FILLED",
+                "CANCELLED",
+                "REJECTED",
+                "EXPIRED"
             ],
             "x-enum-varnames": [
-                "OrderPending",
-                "OrderCompleted",
-                "OrderFailed"
+                "OrderNew",
+                "OrderPartiallyFilled",
+                "OrderFilled",
+                "OrderCancelled",
+                "OrderRejected",
+                "OrderExpired"
             ]
         },
         "github_com_Skapar_backend_internal_models_entities.OrderType": {
@@ -1264,18 +3655,182 @@ const docTemplate = `{
                 "OrderSell"
             ]
         },
+        "github_com_Skapar_backend_internal_models_entities.Permission": {
+            "type": "string",
+            "enum": [
+                "users:read:any",
+                "users:admin",
+                "stocks:read",
+                "stocks:write",
+                "orders:read",
+                "orders:read:any",
+                "orders:write",
+                "orders:write:any",
+                "portfolio:read",
+                "portfolio:read:any",
+                "portfolio:write",
+                "portfolio:write:any",
+                "history:read",
+                "history:read:any",
+                "history:write",
+                "history:write:any",
+                "account:read",
+                "account:write",
+                "ledger:reconcile",
+                "roles:admin",
+                "audit:read"
+            ],
+            "x-enum-comments": {
+                "PermOrdersWriteAny": "в т.ч. любой переход статуса, а не только отмена",
+                "PermPortfolioWriteAny": "ручная правка позиций"
+            },
+            "x-enum-descriptions": [
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "в т.ч. любой переход статуса, а не только отмена",
+                "",
+                "",
+                "",
+                "ручная правка позиций",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                ""
+            ],
+            "x-enum-varnames": [
+                "PermUsersReadAny",
+                "PermUsersAdmin",
+                "PermStocksRead",
+                "PermStocksWrite",
+                "PermOrdersRead",
+                "PermOrdersReadAny",
+                "PermOrdersWrite",
+                "PermOrdersWriteAny",
+                "PermPortfolioRead",
+                "PermPortfolioReadAny",
+                "PermPortfolioWrite",
+                "PermPortfolioWriteAny",
+                "PermHistoryRead",
+                "PermHistoryReadAny",
+                "PermHistoryWrite",
+                "PermHistoryWriteAny",
+                "PermAccountRead",
+                "PermAccountWrite",
+                "PermLedgerReconcile",
+                "PermRolesAdmin",
+                "PermAuditRead"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.PermissionInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Permission"
+                }
+            }
+        },
         "github_com_Skapar_backend_internal_models_entities.Portfolio": {
             "type": "object",
             "properties": {
+                "available_quantity": {
+                    "description": "quantity - locked_quantity",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "locked_quantity": {
+                    "description": "под открытыми SELL-заявками",
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "string"
+                },
+                "stock_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.PortfolioValuation": {
+            "type": "object",
+            "properties": {
+                "cost_basis": {
+                    "type": "string"
+                },
+                "market_value": {
+                    "type": "string"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Position"
+                    }
+                },
+                "unrealized_pnl": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.Position": {
+            "type": "object",
+            "properties": {
+                "available_quantity": {
+                    "description": "quantity - locked_quantity",
+                    "type": "string"
+                },
+                "avg_cost": {
+                    "type": "string"
+                },
+                "cost_basis": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "locked_quantity": {
+                    "description": "под открытыми SELL-заявками",
+                    "type": "string"
+                },
+                "market_price": {
+                    "type": "string"
+                },
+                "market_value": {
+                    "type": "string"
+                },
                 "quantity": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "stock_id": {
                     "type": "integer"
                 },
+                "symbol": {
+                    "type": "string"
+                },
+                "unrealized_pnl": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 },
@@ -1287,34 +3842,157 @@ const docTemplate = `{
                 }
             }
         },
-        "github_com_Skapar_backend_internal_models_entities.Role": {
+        "github_com_Skapar_backend_internal_models_entities.Role": {
+            "type": "string",
+            "enum": [
+                "TRADER",
+                "ADMIN"
+            ],
+            "x-enum-varnames": [
+                "RoleTrader",
+                "RoleAdmin"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.RoleInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Role"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Permission"
+                    }
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.Stock": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "lot_size": {
+                    "description": "шаг количества",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tick_size": {
+                    "description": "шаг цены",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.StreamEvent": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.StreamEventType"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.StreamEventType": {
             "type": "string",
             "enum": [
-                "TRADER",
-                "ADMIN"
+                "price",
+                "order",
+                "fill"
+            ],
+            "x-enum-comments": {
+                "EventFill": "исполнение заявки пользователя",
+                "EventOrder": "смена статуса или исполнения заявки",
+                "EventPrice": "изменение цены акции"
+            },
+            "x-enum-descriptions": [
+                "изменение цены акции",
+                "смена статуса или исполнения заявки",
+                "исполнение заявки пользователя"
             ],
             "x-enum-varnames": [
-                "RoleTrader",
-                "RoleAdmin"
+                "EventPrice",
+                "EventOrder",
+                "EventFill"
             ]
         },
-        "github_com_Skapar_backend_internal_models_entities.Stock": {
+        "github_com_Skapar_backend_internal_models_entities.TOTPEnrollment": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "price": {
-                    "type": "number"
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXP"
                 },
-                "symbol": {
-                    "type": "string"
+                "uri": {
+                    "type": "string",
+                    "example": "otpauth://totp/Stock:test@mail.com?secret=JBSWY3DPEHPK3PXP\u0026issuer=Stock"
+                }
+            }
+        },
+        "github_com_Skapar_backend_internal_models_entities.TimeInForce": {
+            "type": "string",
+            "enum": [
+                "GTC",
+                "DAY",
+                "IOC",
+                "FOK",
+                "GTD"
+            ],
+            "x-enum-comments": {
+                "TimeInForceDAY": "до закрытия торговой сессии",
+                "TimeInForceFOK": "исполнить целиком или отменить",
+                "TimeInForceGTC": "до отмены",
+                "TimeInForceGTD": "до expires_at",
+                "TimeInForceIOC": "исполнить что можно, остаток отменить"
+            },
+            "x-enum-descriptions": [
+                "до отмены",
+                "до закрытия торговой сессии",
+                "исполнить что можно, остаток отменить",
+                "исполнить целиком или отменить",
+                "до expires_at"
+            ],
+            "x-enum-varnames": [
+                "TimeInForceGTC",
+                "TimeInForceDAY",
+                "TimeInForceIOC",
+                "TimeInForceFOK",
+                "TimeInForceGTD"
+            ]
+        },
+        "github_com_Skapar_backend_internal_models_entities.TwoFactorStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
                 },
-                "updated_at": {
-                    "type": "string"
+                "recovery_codes_left": {
+                    "type": "integer"
                 }
             }
         },
@@ -1322,7 +4000,10 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "balance": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "costBasisMethod": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.CostBasisMethod"
                 },
                 "createdAt": {
                     "type": "string"
@@ -1330,23 +4011,133 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "emailVerifiedAt": {
+                    "description": "nil — email не подтверждён, торговать нельзя",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
                 "password": {
                     "type": "string"
                 },
+                "reservedBalance": {
+                    "description": "зарезервировано под открытые BUY-заявки",
+                    "type": "string"
+                },
                 "role": {
                     "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Role"
                 }
             }
         },
+        "internal_handler.AuditLogResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.AuditEntry"
+                    }
+                },
+                "next_before_id": {
+                    "description": "before_id следующей страницы; нет — записей больше нет",
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "internal_handler.CostBasisMethodRequest": {
+            "type": "object",
+            "properties": {
+                "method": {
+                    "description": "FIFO, LIFO или AVERAGE",
+                    "type": "string",
+                    "example": "FIFO"
+                }
+            }
+        },
+        "internal_handler.CreateAPIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "allowed_ips": {
+                    "description": "IP или CIDR",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "203.0.113.7"
+                    ]
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "market-maker-bot"
+                },
+                "scopes": {
+                    "description": "read, trade, withdraw",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "read",
+                        "trade"
+                    ]
+                }
+            }
+        },
+        "internal_handler.CreateAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.APIKey"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "sk_1a2b3c4d5e6f_..."
+                }
+            }
+        },
+        "internal_handler.CreateInviteRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "если задан, код отправляется письмом и подходит только для этого адреса",
+                    "type": "string",
+                    "example": "new-admin@mail.com"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "ADMIN"
+                },
+                "ttl_hours": {
+                    "type": "integer",
+                    "example": 72
+                }
+            }
+        },
+        "internal_handler.CreateInviteResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "inv_..."
+                },
+                "invite": {
+                    "$ref": "#/definitions/github_com_Skapar_backend_internal_models_entities.Invite"
+                }
+            }
+        },
         "internal_handler.CreateOrUpdatePortfolioRequest": {
             "type": "object",
             "properties": {
                 "quantity": {
-                    "type": "number",
-                    "example": 2
+                    "type": "string",
+                    "example": "2"
                 },
                 "stock_id": {
                     "type": "integer",
@@ -1361,14 +4152,43 @@ const docTemplate = `{
         "internal_handler.CreateOrderRequest": {
             "type": "object",
             "properties": {
+                "expire_at": {
+                    "description": "обязателен для GTD",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "MARKET (по умолчанию), LIMIT, STOP, STOP_LIMIT, TRAILING_STOP",
+                    "type": "string",
+                    "example": "LIMIT"
+                },
+                "limit_price": {
+                    "description": "обязателен для LIMIT и STOP_LIMIT",
+                    "type": "string",
+                    "example": "101.5"
+                },
                 "quantity": {
-                    "type": "integer",
-                    "example": 2
+                    "type": "string",
+                    "example": "2"
                 },
                 "stock_id": {
                     "type": "integer",
                     "example": 4
                 },
+                "time_in_force": {
+                    "description": "GTC (по умолчанию), DAY, IOC, FOK, GTD",
+                    "type": "string",
+                    "example": "GTC"
+                },
+                "trailing_offset": {
+                    "description": "обязателен для TRAILING_STOP",
+                    "type": "string",
+                    "example": "2"
+                },
+                "trigger_price": {
+                    "description": "обязателен для STOP и STOP_LIMIT",
+                    "type": "string",
+                    "example": "99"
+                },
                 "type": {
                     "description": "BUY или SELL",
                     "type": "string",
@@ -1376,6 +4196,15 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handler.EmailTokenRequest": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
         "internal_handler.ErrorResponse": {
             "type": "object",
             "properties": {
@@ -1385,16 +4214,63 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handler.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "test@mail.com"
+                }
+            }
+        },
+        "internal_handler.FundsRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "1000"
+                }
+            }
+        },
+        "internal_handler.FundsResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "6000"
+                },
+                "journal_id": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
         "internal_handler.GetMeResponse": {
             "type": "object",
             "properties": {
+                "available": {
+                    "description": "balance - reserved",
+                    "type": "string",
+                    "example": "750"
+                },
                 "balance": {
-                    "type": "number",
-                    "example": 1000
+                    "description": "всего на счёте",
+                    "type": "string",
+                    "example": "1000"
                 },
                 "email": {
                     "type": "string",
                     "example": "test@mail.com"
+                },
+                "email_verified": {
+                    "description": "без подтверждённого email торговать нельзя",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reserved": {
+                    "description": "под открытыми заявками",
+                    "type": "string",
+                    "example": "250"
                 }
             }
         },
@@ -1420,6 +4296,24 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handler.LoginChallengeResponse": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                },
+                "expiresIn": {
+                    "description": "unix-время истечения challenge",
+                    "type": "integer",
+                    "example": 1730000300
+                },
+                "two_factor_required": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
         "internal_handler.LoginRequest": {
             "type": "object",
             "properties": {
@@ -1437,15 +4331,46 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "expiresIn": {
+                    "description": "unix-время истечения access-токена",
                     "type": "integer",
                     "example": 1730000000
                 },
+                "refresh_expires_in": {
+                    "type": "integer",
+                    "example": 1732592000
+                },
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wEAAAB..."
+                },
                 "token": {
                     "type": "string",
                     "example": "eyJhbGciOi..."
                 }
             }
         },
+        "internal_handler.LoginTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                },
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "internal_handler.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wEAAAB..."
+                }
+            }
+        },
         "internal_handler.MessageResponse": {
             "type": "object",
             "properties": {
@@ -1465,15 +4390,19 @@ const docTemplate = `{
                 "order_id": {
                     "type": "integer",
                     "example": 123
+                },
+                "status": {
+                    "type": "string",
+                    "example": "FILLED"
                 }
             }
         },
-        "internal_handler.RegisterRequest": {
+        "internal_handler.ProvisionUserRequest": {
             "type": "object",
             "properties": {
                 "email": {
                     "type": "string",
-                    "example": "test@mail.com"
+                    "example": "support@mail.com"
                 },
                 "password": {
                     "type": "string",
@@ -1481,7 +4410,48 @@ const docTemplate = `{
                 },
                 "role": {
                     "type": "string",
-                    "example": "TRADER"
+                    "example": "SUPPORT"
+                }
+            }
+        },
+        "internal_handler.RecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abcde-fghij",
+                        "klmno-pqrst"
+                    ]
+                }
+            }
+        },
+        "internal_handler.RefreshRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string",
+                    "example": "3q2-7wEAAAB..."
+                }
+            }
+        },
+        "internal_handler.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "test@mail.com"
+                },
+                "invite_code": {
+                    "type": "string",
+                    "example": "inv_..."
+                },
+                "password": {
+                    "type": "string",
+                    "example": "123456"
                 }
             }
         },
@@ -1498,12 +4468,78 @@ const docTemplate = `{
                 }
             }
         },
+        "internal_handler.ResetPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newpass123"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "eyJhbGciOi..."
+                }
+            }
+        },
+        "internal_handler.SetRoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Read-only access to customer data"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read:any",
+                        "orders:read:any"
+                    ]
+                }
+            }
+        },
+        "internal_handler.TOTPCodeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
         "internal_handler.UpdateOrderStatusRequest": {
             "type": "object",
             "properties": {
                 "status": {
                     "type": "string",
-                    "example": "COMPLETED"
+                    "example": "CANCELLED"
+                }
+            }
+        },
+        "internal_handler.UpdateStockRequest": {
+            "type": "object",
+            "properties": {
+                "lot_size": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Apple Inc."
+                },
+                "price": {
+                    "type": "string",
+                    "example": "187.25"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "AAPL"
+                },
+                "tick_size": {
+                    "type": "string",
+                    "example": "0.01"
                 }
             }
         },
@@ -1511,8 +4547,8 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "balance": {
-                    "type": "number",
-                    "example": 5000
+                    "type": "string",
+                    "example": "5000"
                 },
                 "email": {
                     "type": "string",
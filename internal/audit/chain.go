@@ -0,0 +1,151 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+// Hash — хэш записи вместе с хэшем предыдущей. JSON приводится к каноническому виду,
+// потому что jsonb в Postgres хранит его в своём формате.
+func Hash(e *entities.AuditEntry) (string, error) {
+	before, err := Canonical(e.Before)
+	if err != nil {
+		return "", err
+	}
+	after, err := Canonical(e.After)
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(struct {
+		PrevHash   string          `json:"prev_hash"`
+		ActorID    *int64          `json:"actor_id"`
+		ActorRole  string          `json:"actor_role"`
+		Action     string          `json:"action"`
+		TargetType string          `json:"target_type"`
+		TargetID   string          `json:"target_id"`
+		Before     json.RawMessage `json:"before"`
+		After      json.RawMessage `json:"after"`
+		IP         string          `json:"ip"`
+		RequestID  string          `json:"request_id"`
+		CreatedAt  string          `json:"created_at"`
+	}{
+		PrevHash:   e.PrevHash,
+		ActorID:    e.ActorID,
+		ActorRole:  e.ActorRole,
+		Action:     string(e.Action),
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     before,
+		After:      after,
+		IP:         e.IP,
+		RequestID:  e.RequestID,
+		CreatedAt:  e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+var (
+	ErrPrevHashMismatch = errors.New("prev_hash does not match the previous entry")
+	ErrHashMismatch     = errors.New("entry content does not match its hash")
+)
+
+// Verify проверяет, что запись продолжает цепочку после записи с хэшем prevHash
+// и что её содержимое сходится с сохранённым хэшем.
+func Verify(e *entities.AuditEntry, prevHash string) error {
+	if e.PrevHash != prevHash {
+		return ErrPrevHashMismatch
+	}
+	hash, err := Hash(e)
+	if err != nil {
+		return errors.New("entry cannot be hashed: " + err.Error())
+	}
+	if hash != e.Hash {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// Canonical — JSON с отсортированными ключами и без пробелов; пустое значение — null.
+// Числа сохраняются как записаны, деньги (decimal) и так приходят строками.
+func Canonical(raw json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	v, err := decode(raw)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Diff оставляет в снимках до и после только изменившиеся поля. Снимок nil — объекта
+// не было (создание) или не стало (удаление); тогда другой снимок пишется целиком.
+func Diff(before, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	b, err := toMap(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := toMap(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if b != nil && a != nil {
+		for k, bv := range b {
+			if av, ok := a[k]; ok && reflect.DeepEqual(av, bv) {
+				delete(a, k)
+				delete(b, k)
+			}
+		}
+	}
+	return marshalMap(b), marshalMap(a), nil
+}
+
+func toMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decode(raw)
+	if err != nil || decoded == nil {
+		return nil, err
+	}
+	m, ok := decoded.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{"value": decoded}, nil
+	}
+	return m, nil
+}
+
+func marshalMap(m map[string]interface{}) json.RawMessage {
+	if m == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(m) // значения уже прошли через json, ошибки быть не может
+	return raw
+}
+
+func decode(raw []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,251 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+type snapshot struct {
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Balance string `json:"balance"`
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     interface{}
+		after      interface{}
+		wantBefore string
+		wantAfter  string
+	}{
+		{
+			name:       "only changed fields kept",
+			before:     snapshot{Email: "a@x", Role: "USER", Balance: "10"},
+			after:      snapshot{Email: "a@x", Role: "ADMIN", Balance: "10"},
+			wantBefore: `{"role":"USER"}`,
+			wantAfter:  `{"role":"ADMIN"}`,
+		},
+		{
+			name:      "create keeps full after",
+			after:     snapshot{Email: "a@x", Role: "USER", Balance: "0"},
+			wantAfter: `{"balance":"0","email":"a@x","role":"USER"}`,
+		},
+		{
+			name:       "delete keeps full before",
+			before:     snapshot{Email: "a@x", Role: "USER", Balance: "0"},
+			wantBefore: `{"balance":"0","email":"a@x","role":"USER"}`,
+		},
+		{
+			name:       "no changes",
+			before:     snapshot{Email: "a@x"},
+			after:      snapshot{Email: "a@x"},
+			wantBefore: `{}`,
+			wantAfter:  `{}`,
+		},
+		{
+			name:       "added and removed keys",
+			before:     map[string]interface{}{"a": 1, "b": 2},
+			after:      map[string]interface{}{"b": 2, "c": 3},
+			wantBefore: `{"a":1}`,
+			wantAfter:  `{"c":3}`,
+		},
+		{
+			name:       "scalar snapshots wrapped",
+			before:     "old",
+			after:      "new",
+			wantBefore: `{"value":"old"}`,
+			wantAfter:  `{"value":"new"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before, after, err := Diff(tt.before, tt.after)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(before) != tt.wantBefore {
+				t.Errorf("before = %s, want %s", before, tt.wantBefore)
+			}
+			if string(after) != tt.wantAfter {
+				t.Errorf("after = %s, want %s", after, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{``, `null`},
+		{`  `, `null`},
+		{`{"b": 1, "a": {"d": 2, "c": 3}}`, `{"a":{"c":3,"d":2},"b":1}`},
+		{`{"n": 1.50}`, `{"n":1.50}`},
+		{`[3, 1]`, `[3,1]`},
+	}
+	for _, tt := range tests {
+		got, err := Canonical(json.RawMessage(tt.in))
+		if err != nil {
+			t.Fatalf("Canonical(%q): %v", tt.in, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Canonical(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+// chain строит цепочку из n записей так, как её пишет auditTx.
+func chain(t *testing.T, n int) []*entities.AuditEntry {
+	t.Helper()
+	actor := int64(1)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var (
+		entries []*entities.AuditEntry
+		prev    string
+	)
+	for i := 0; i < n; i++ {
+		before, after, err := Diff(snapshot{Role: "USER"}, snapshot{Role: "ADMIN", Balance: string(rune('0' + i))})
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := &entities.AuditEntry{
+			ID:         int64(i + 1),
+			ActorID:    &actor,
+			ActorRole:  "ADMIN",
+			Action:     entities.AuditUserUpdate,
+			TargetType: "user",
+			TargetID:   "42",
+			Before:     before,
+			After:      after,
+			IP:         "203.0.113.7",
+			RequestID:  "req",
+			PrevHash:   prev,
+			CreatedAt:  at.Add(time.Duration(i) * time.Second),
+		}
+		if e.Hash, err = Hash(e); err != nil {
+			t.Fatal(err)
+		}
+		prev = e.Hash
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// verifyChain — то же, что делает VerifyAuditLog: возвращает id первой битой записи.
+func verifyChain(entries []*entities.AuditEntry) (int64, error) {
+	prev := ""
+	for _, e := range entries {
+		if err := Verify(e, prev); err != nil {
+			return e.ID, err
+		}
+		prev = e.Hash
+	}
+	return 0, nil
+}
+
+func TestVerifyChain(t *testing.T) {
+	tests := []struct {
+		name       string
+		tamper     func(entries []*entities.AuditEntry) []*entities.AuditEntry
+		wantBroken int64
+		wantErr    error
+	}{
+		{
+			name:   "intact chain",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry { return e },
+		},
+		{
+			name: "edited payload",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				e[1].After = json.RawMessage(`{"role":"USER"}`)
+				return e
+			},
+			wantBroken: 2,
+			wantErr:    ErrHashMismatch,
+		},
+		{
+			name: "edited actor",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				other := int64(2)
+				e[2].ActorID = &other
+				return e
+			},
+			wantBroken: 3,
+			wantErr:    ErrHashMismatch,
+		},
+		{
+			name: "edited timestamp",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				e[0].CreatedAt = e[0].CreatedAt.Add(time.Nanosecond)
+				return e
+			},
+			wantBroken: 1,
+			wantErr:    ErrHashMismatch,
+		},
+		{
+			name: "rehashed entry breaks the next link",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				e[1].IP = "198.51.100.1"
+				e[1].Hash, _ = Hash(e[1])
+				return e
+			},
+			wantBroken: 3,
+			wantErr:    ErrPrevHashMismatch,
+		},
+		{
+			name: "deleted entry",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				return append(e[:1], e[2:]...)
+			},
+			wantBroken: 3,
+			wantErr:    ErrPrevHashMismatch,
+		},
+		{
+			name: "reordered entries",
+			tamper: func(e []*entities.AuditEntry) []*entities.AuditEntry {
+				e[1], e[2] = e[2], e[1]
+				return e
+			},
+			wantBroken: 3,
+			wantErr:    ErrPrevHashMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken, err := verifyChain(tt.tamper(chain(t, 4)))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if broken != tt.wantBroken {
+				t.Errorf("broken at %d, want %d", broken, tt.wantBroken)
+			}
+		})
+	}
+}
+
+func TestHashIgnoresJSONFormatting(t *testing.T) {
+	e := &entities.AuditEntry{
+		Action:    entities.AuditUserUpdate,
+		After:     json.RawMessage(`{"role":"ADMIN","balance":"1"}`),
+		CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("UTC+5", 5*3600)),
+	}
+	want, err := Hash(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// так jsonb возвращает тот же объект, а pgx — время в UTC
+	e.After = json.RawMessage(`{"balance": "1", "role": "ADMIN"}`)
+	e.CreatedAt = e.CreatedAt.UTC()
+	got, err := Hash(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("hash changed after reformatting: %s != %s", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+// Package audit — журнал привилегированных действий: кто, что, над чем и откуда.
+// Записи связаны в цепочку хэшей, поэтому правку или удаление строки задним числом видно при проверке.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// GinKey — ключ Meta в gin.Context: через Value gin отдаёт значения из c.Set только по строковому ключу.
+const GinKey = "auditMeta"
+
+type metaKey struct{}
+
+const maxRequestIDLen = 64
+
+// Meta — исполнитель и источник запроса. Кладётся в контекст на входе HTTP или gRPC,
+// исполнитель дописывается после аутентификации.
+type Meta struct {
+	ActorID   *int64
+	ActorRole string
+	IP        string
+	RequestID string
+}
+
+// WithMeta кладёт m в контекст.
+func WithMeta(ctx context.Context, m *Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, m)
+}
+
+// MetaFrom достаёт Meta из контекста; без неё — пустая Meta (фоновые задачи, воркер).
+func MetaFrom(ctx context.Context) *Meta {
+	if m, ok := ctx.Value(metaKey{}).(*Meta); ok && m != nil {
+		return m
+	}
+	if m, ok := ctx.Value(GinKey).(*Meta); ok && m != nil {
+		return m
+	}
+	return &Meta{}
+}
+
+// SetActor запоминает аутентифицированного пользователя.
+func (m *Meta) SetActor(userID int64, role string) {
+	m.ActorID = &userID
+	m.ActorRole = role
+}
+
+// Detach переносит Meta в новый контекст для фоновой записи, когда запрос уже завершится.
+func Detach(ctx context.Context) context.Context {
+	m := *MetaFrom(ctx)
+	return WithMeta(context.Background(), &m)
+}
+
+// RequestID возвращает id запроса от клиента, если он короткий и из безопасных символов
+// (значение уходит в журнал как есть), иначе генерирует новый.
+func RequestID(raw string) string {
+	if raw == "" || len(raw) > maxRequestIDLen {
+		return uuid.NewString()
+	}
+	for _, r := range raw {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return uuid.NewString()
+		}
+	}
+	return raw
+}
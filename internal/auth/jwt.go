@@ -1,19 +1,34 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type Claims struct {
 	UserID int64  `json:"user_id"`
 	Role   string `json:"role"`
+	// Purpose задан только у служебных токенов (например, второй шаг логина);
+	// access-токеном такой токен не принимается.
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Назначения служебных токенов.
+const (
+	PurposeLogin2FA      = "login_2fa"      // между вводом пароля и кодом второго фактора
+	PurposeVerifyEmail   = "verify_email"   // ссылка подтверждения email
+	PurposeResetPassword = "reset_password" // ссылка сброса пароля
+)
+
 func HashPassword(password string) (string, error) {
 	b, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(b), err
@@ -24,34 +39,114 @@ func CheckPasswordHash(hash, password string) bool {
 	return err == nil
 }
 
-func GenerateToken(secret string, ttlMinutes int, userID int64, role string) (string, error) {
+func GenerateToken(keys *KeySet, ttlMinutes int, userID int64, role string) (string, error) {
 	now := time.Now()
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(), // jti — по нему токен отзывается до истечения
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Duration(ttlMinutes) * time.Minute)),
 			Issuer:    "stock-trading",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return keys.sign(claims)
 }
 
-func ParseToken(secret, tokenStr string) (*Claims, error) {
-	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
-	token, err := parser.ParseWithClaims(tokenStr, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+func ParseToken(keys *KeySet, tokenStr string) (*Claims, error) {
+	token, err := keys.parse(tokenStr, &Claims{})
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Purpose == "" {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
+
+// GenerateChallengeToken выпускает короткоживущий служебный токен с назначением purpose.
+// Claims возвращаются, чтобы вызывающий мог запомнить jti и срок действия.
+func GenerateChallengeToken(keys *KeySet, ttl time.Duration, userID int64, purpose string) (string, *Claims, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			Issuer:    "stock-trading",
+		},
+	}
+
+	token, err := keys.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// ParseChallengeToken проверяет служебный токен и его назначение.
+func ParseChallengeToken(keys *KeySet, tokenStr, purpose string) (*Claims, error) {
+	token, err := keys.parse(tokenStr, &Claims{})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid && claims.Purpose == purpose {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
+// GenerateRefreshToken возвращает непрозрачный refresh-токен для клиента и его хэш для хранения.
+func GenerateRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// APIKeyPrefix отличает API-ключи от JWT и refresh-токенов в логах и заголовках.
+const APIKeyPrefix = "sk_"
+
+// GenerateAPIKey возвращает ключ вида sk_<prefix>_<secret>, его отображаемый префикс и хэш.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	p := make([]byte, 6)
+	if _, err = rand.Read(p); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+	prefix = hex.EncodeToString(p)
+	key = APIKeyPrefix + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashToken(key), nil
+}
+
+// InviteCodePrefix отличает коды приглашений от остальных токенов.
+const InviteCodePrefix = "inv_"
+
+// GenerateInviteCode возвращает код приглашения и его хэш для хранения.
+func GenerateInviteCode() (code, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", err
+	}
+	code = InviteCodePrefix + base64.RawURLEncoding.EncodeToString(b)
+	return code, HashToken(code), nil
+}
+
+// HashToken — в БД хранятся только хэши токенов.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
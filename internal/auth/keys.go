@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// KeySetConfig — откуда брать ключи. Для HS256 нужен только Secret; для RS256/EdDSA —
+// приватный ключ подписи и, опционально, каталог публичных ключей проверки.
+type KeySetConfig struct {
+	Algorithm           string
+	Secret              string
+	SigningKeyFile      string // PEM приватного ключа
+	SigningKeyID        string // kid; по умолчанию — имя файла без расширения
+	VerificationKeysDir string // *.pem публичные ключи, kid = имя файла без расширения
+}
+
+// KeySet подписывает токены текущим ключом и проверяет их любым активным ключом по kid.
+//
+// Ротация без простоя: положить новый публичный ключ в каталог проверки (его увидят
+// все инстансы и JWKS), затем переключить ключ подписи; старый публичный ключ удалить,
+// когда истекут выданные им токены. Reload перечитывает файлы на лету.
+type KeySet struct {
+	cfg KeySetConfig
+
+	mu      sync.RWMutex
+	method  jwt.SigningMethod
+	kid     string
+	signKey interface{}
+	verify  map[string]interface{}
+}
+
+func NewKeySet(cfg *KeySetConfig) (*KeySet, error) {
+	k := &KeySet{cfg: *cfg}
+	if err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload перечитывает ключи с диска. При ошибке остаются прежние ключи.
+func (k *KeySet) Reload() error {
+	method, kid, signKey, verify, err := loadKeys(&k.cfg)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.method, k.kid, k.signKey, k.verify = method, kid, signKey, verify
+	k.mu.Unlock()
+	return nil
+}
+
+func (k *KeySet) sign(claims jwt.Claims) (string, error) {
+	k.mu.RLock()
+	method, kid, key := k.method, k.kid, k.signKey
+	k.mu.RUnlock()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
+
+func (k *KeySet) parse(tokenStr string, claims jwt.Claims) (*jwt.Token, error) {
+	k.mu.RLock()
+	method, verify := k.method, k.verify
+	k.mu.RUnlock()
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{method.Alg()}))
+	return parser.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := verify[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	})
+}
+
+// JWK — публичный ключ в формате RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS возвращает публичные ключи проверки. Для HS256 список пуст: секрет не публикуется.
+func (k *KeySet) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	out := JWKS{Keys: []JWK{}}
+	for kid, key := range k.verify {
+		switch pub := key.(type) {
+		case *rsa.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "RSA", Kid: kid, Use: "sig", Alg: AlgRS256,
+				N: b64(pub.N.Bytes()),
+				E: b64(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			out.Keys = append(out.Keys, JWK{
+				Kty: "OKP", Kid: kid, Use: "sig", Alg: AlgEdDSA, Crv: "Ed25519",
+				X: b64(pub),
+			})
+		}
+	}
+	sort.Slice(out.Keys, func(i, j int) bool { return out.Keys[i].Kid < out.Keys[j].Kid })
+	return out
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func loadKeys(cfg *KeySetConfig) (jwt.SigningMethod, string, interface{}, map[string]interface{}, error) {
+	switch cfg.Algorithm {
+	case AlgHS256, "":
+		if cfg.Secret == "" {
+			return nil, "", nil, nil, errors.New("jwt: HS256 requires a secret")
+		}
+		secret := []byte(cfg.Secret)
+		// токены HS256 выпускались без kid
+		return jwt.SigningMethodHS256, "", secret, map[string]interface{}{"": secret}, nil
+	case AlgRS256, AlgEdDSA:
+	default:
+		return nil, "", nil, nil, fmt.Errorf("jwt: unsupported algorithm %q", cfg.Algorithm)
+	}
+
+	if cfg.SigningKeyFile == "" {
+		return nil, "", nil, nil, fmt.Errorf("jwt: %s requires a signing key file", cfg.Algorithm)
+	}
+	pemBytes, err := os.ReadFile(cfg.SigningKeyFile)
+	if err != nil {
+		return nil, "", nil, nil, fmt.Errorf("jwt: read signing key: %w", err)
+	}
+
+	var (
+		method  jwt.SigningMethod
+		signKey crypto.Signer
+	)
+	if cfg.Algorithm == AlgRS256 {
+		method = jwt.SigningMethodRS256
+		signKey, err = jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+	} else {
+		method = jwt.SigningMethodEdDSA
+		var key crypto.PrivateKey
+		if key, err = jwt.ParseEdPrivateKeyFromPEM(pemBytes); err == nil {
+			signKey = key.(crypto.Signer)
+		}
+	}
+	if err != nil {
+		return nil, "", nil, nil, fmt.Errorf("jwt: parse signing key: %w", err)
+	}
+
+	kid := cfg.SigningKeyID
+	if kid == "" {
+		kid = keyIDFromFile(cfg.SigningKeyFile)
+	}
+
+	verify := map[string]interface{}{kid: signKey.Public()}
+	if cfg.VerificationKeysDir != "" {
+		files, err := filepath.Glob(filepath.Join(cfg.VerificationKeysDir, "*.pem"))
+		if err != nil {
+			return nil, "", nil, nil, err
+		}
+		for _, f := range files {
+			pemBytes, err := os.ReadFile(f)
+			if err != nil {
+				return nil, "", nil, nil, fmt.Errorf("jwt: read verification key: %w", err)
+			}
+			var pub interface{}
+			if cfg.Algorithm == AlgRS256 {
+				pub, err = jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+			} else {
+				pub, err = jwt.ParseEdPublicKeyFromPEM(pemBytes)
+			}
+			if err != nil {
+				return nil, "", nil, nil, fmt.Errorf("jwt: parse verification key %s: %w", f, err)
+			}
+			verify[keyIDFromFile(f)] = pub
+		}
+	}
+
+	return method, kid, signKey, verify, nil
+}
+
+func keyIDFromFile(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// SecretBox шифрует секреты, которые нужно уметь прочитать обратно (TOTP), AES-256-GCM.
+// В отличие от паролей и токенов хэш тут не подходит.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox принимает ключ в base64 (32 байта).
+func NewSecretBox(keyB64 string) (*SecretBox, error) {
+	key, err := base64.StdEncoding.DecodeString(keyB64)
+	if err != nil {
+		return nil, errors.New("encryption key must be base64")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+// Seal возвращает nonce || ciphertext.
+func (b *SecretBox) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (b *SecretBox) Open(sealed []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(sealed) < n {
+		return nil, errors.New("ciphertext too short")
+	}
+	return b.aead.Open(nil, sealed[:n], sealed[n:], nil)
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// секрет из приложения D RFC 6238 (SHA1): "12345678901234567890"
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateTOTP(t *testing.T) {
+	// коды RFC 6238 — восьмизначные, здесь их последние шесть цифр
+	at := time.Unix(1111111109, 0) // шаг 37037036, код 081804
+	step := at.Unix() / int64(TOTPPeriod.Seconds())
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		now      time.Time
+		wantStep int64
+		wantOK   bool
+	}{
+		{"rfc vector", rfcSecret, "081804", at, step, true},
+		{"rfc vector at t=59", rfcSecret, "287082", time.Unix(59, 0), 1, true},
+		{"lowercase secret", strings.ToLower(rfcSecret), "081804", at, step, true},
+		{"previous step accepted", rfcSecret, "081804", at.Add(TOTPPeriod), step, true},
+		{"next step accepted", rfcSecret, "081804", at.Add(-TOTPPeriod), step, true},
+		{"two steps late rejected", rfcSecret, "081804", at.Add(2 * TOTPPeriod), 0, false},
+		{"wrong code", rfcSecret, "081805", at, 0, false},
+		{"short code", rfcSecret, "81804", at, 0, false},
+		{"long code", rfcSecret, "0081804", at, 0, false},
+		{"bad secret", "not base32!", "081804", at, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, ok := ValidateTOTP(tt.secret, tt.code, tt.now)
+			if ok != tt.wantOK || gotStep != tt.wantStep {
+				t.Errorf("ValidateTOTP = (%d, %v), want (%d, %v)", gotStep, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGeneratedSecretValidates(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	step := now.Unix() / int64(TOTPPeriod.Seconds())
+	if got, ok := ValidateTOTP(secret, totpCode(key, step), now); !ok || got != step {
+		t.Errorf("ValidateTOTP = (%d, %v), want (%d, true)", got, ok, step)
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"abcde-fghij", "abcdefghij"},
+		{" ABCDE-FGHIJ ", "abcdefghij"},
+		{"abcde fghij", "abcdefghij"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeRecoveryCode(tt.in); got != tt.want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
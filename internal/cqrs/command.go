@@ -2,8 +2,10 @@ package cqrs
 
 import (
 	"context"
+	"time"
 
 	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
 )
 
 type Command interface {
@@ -11,15 +13,48 @@ type Command interface {
 	UpdateUser(ctx context.Context, user *entities.User) error
 	DeleteUser(ctx context.Context, id int64) error
 
+	RegisterUser(ctx context.Context, user *entities.User, inviteCode string) (int64, error)
+	ProvisionUser(ctx context.Context, user *entities.User) (int64, error)
+	CreateInvite(ctx context.Context, inv *entities.Invite, ttl time.Duration) (string, error)
+	RevokeInvite(ctx context.Context, id int64) error
+
 	CreateStock(ctx context.Context, stock *entities.Stock) (int64, error)
 	UpdateStock(ctx context.Context, stock *entities.Stock) error
 	DeleteStock(ctx context.Context, id int64) error
 
 	CreateOrder(ctx context.Context, order *entities.Order) (int64, error)
 	UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error
+	CancelOrder(ctx context.Context, orderID int64) error
 	ExecuteOrder(ctx context.Context, order *entities.Order) error
 
 	CreateOrUpdatePortfolio(ctx context.Context, p *entities.Portfolio) error
+	SetCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error
 
 	AddHistoryRecord(ctx context.Context, h *entities.History) (int64, error)
+
+	Deposit(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error)
+	Withdraw(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error)
+
+	CheckCredentials(ctx context.Context, email, password, clientIP string) (*entities.User, error)
+	UnlockUser(ctx context.Context, userID int64) error
+	SendVerificationEmail(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string)
+	ResetPassword(ctx context.Context, token, passwordHash string) error
+	IssueTokens(ctx context.Context, user *entities.User) (*entities.TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*entities.TokenPair, error)
+	RevokeTokens(ctx context.Context, userID int64, jti string, accessExpiresAt time.Time, refreshToken string) error
+
+	SetRolePermissions(ctx context.Context, role *entities.RoleInfo) error
+
+	CreateAPIKey(ctx context.Context, k *entities.APIKey) (string, error)
+	RevokeAPIKey(ctx context.Context, userID, id int64) error
+
+	EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	BeginLogin(ctx context.Context, user *entities.User) (*entities.TokenPair, *entities.LoginChallenge, error)
+	CompleteLogin(ctx context.Context, challengeToken, code string) (*entities.TokenPair, error)
+	VerifyStepUp(ctx context.Context, userID int64, code string) error
 }
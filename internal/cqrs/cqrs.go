@@ -2,9 +2,11 @@ package cqrs
 
 import (
 	"context"
+	"time"
 
 	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/Skapar/backend/internal/service"
+	"github.com/shopspring/decimal"
 )
 
 type cqrsImpl struct {
@@ -21,6 +23,26 @@ func (c *cqrsImpl) CreateUser(ctx context.Context, user *entities.User) (int64,
 	return c.svc.CreateUser(ctx, user)
 }
 
+func (c *cqrsImpl) RegisterUser(ctx context.Context, user *entities.User, inviteCode string) (int64, error) {
+	return c.svc.RegisterUser(ctx, user, inviteCode)
+}
+
+func (c *cqrsImpl) ProvisionUser(ctx context.Context, user *entities.User) (int64, error) {
+	return c.svc.ProvisionUser(ctx, user)
+}
+
+func (c *cqrsImpl) CreateInvite(ctx context.Context, inv *entities.Invite, ttl time.Duration) (string, error) {
+	return c.svc.CreateInvite(ctx, inv, ttl)
+}
+
+func (c *cqrsImpl) RevokeInvite(ctx context.Context, id int64) error {
+	return c.svc.RevokeInvite(ctx, id)
+}
+
+func (c *cqrsImpl) ListInvites(ctx context.Context) ([]*entities.Invite, error) {
+	return c.svc.ListInvites(ctx)
+}
+
 func (c *cqrsImpl) UpdateUser(ctx context.Context, user *entities.User) error {
 	return c.svc.UpdateUser(ctx, user)
 }
@@ -49,6 +71,10 @@ func (c *cqrsImpl) UpdateOrderStatus(ctx context.Context, orderID int64, status
 	return c.svc.UpdateOrderStatus(ctx, orderID, status)
 }
 
+func (c *cqrsImpl) CancelOrder(ctx context.Context, orderID int64) error {
+	return c.svc.CancelOrder(ctx, orderID)
+}
+
 func (c *cqrsImpl) ExecuteOrder(ctx context.Context, order *entities.Order) error {
 	return c.svc.ExecuteOrder(ctx, order)
 }
@@ -57,10 +83,98 @@ func (c *cqrsImpl) CreateOrUpdatePortfolio(ctx context.Context, p *entities.Port
 	return c.svc.CreateOrUpdatePortfolio(ctx, p)
 }
 
+func (c *cqrsImpl) SetCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error {
+	return c.svc.SetCostBasisMethod(ctx, userID, method)
+}
+
 func (c *cqrsImpl) AddHistoryRecord(ctx context.Context, h *entities.History) (int64, error) {
 	return c.svc.AddHistoryRecord(ctx, h)
 }
 
+func (c *cqrsImpl) Deposit(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error) {
+	return c.svc.Deposit(ctx, userID, amount)
+}
+
+func (c *cqrsImpl) Withdraw(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error) {
+	return c.svc.Withdraw(ctx, userID, amount)
+}
+
+func (c *cqrsImpl) CheckCredentials(ctx context.Context, email, password, clientIP string) (*entities.User, error) {
+	return c.svc.CheckCredentials(ctx, email, password, clientIP)
+}
+
+func (c *cqrsImpl) UnlockUser(ctx context.Context, userID int64) error {
+	return c.svc.UnlockUser(ctx, userID)
+}
+
+func (c *cqrsImpl) SendVerificationEmail(ctx context.Context, userID int64) error {
+	return c.svc.SendVerificationEmail(ctx, userID)
+}
+
+func (c *cqrsImpl) VerifyEmail(ctx context.Context, token string) error {
+	return c.svc.VerifyEmail(ctx, token)
+}
+
+func (c *cqrsImpl) RequestPasswordReset(ctx context.Context, email string) {
+	c.svc.RequestPasswordReset(ctx, email)
+}
+
+func (c *cqrsImpl) ResetPassword(ctx context.Context, token, passwordHash string) error {
+	return c.svc.ResetPassword(ctx, token, passwordHash)
+}
+
+func (c *cqrsImpl) IssueTokens(ctx context.Context, user *entities.User) (*entities.TokenPair, error) {
+	return c.svc.IssueTokens(ctx, user)
+}
+
+func (c *cqrsImpl) RefreshTokens(ctx context.Context, refreshToken string) (*entities.TokenPair, error) {
+	return c.svc.RefreshTokens(ctx, refreshToken)
+}
+
+func (c *cqrsImpl) RevokeTokens(ctx context.Context, userID int64, jti string, accessExpiresAt time.Time, refreshToken string) error {
+	return c.svc.RevokeTokens(ctx, userID, jti, accessExpiresAt, refreshToken)
+}
+
+func (c *cqrsImpl) SetRolePermissions(ctx context.Context, role *entities.RoleInfo) error {
+	return c.svc.SetRolePermissions(ctx, role)
+}
+
+func (c *cqrsImpl) CreateAPIKey(ctx context.Context, k *entities.APIKey) (string, error) {
+	return c.svc.CreateAPIKey(ctx, k)
+}
+
+func (c *cqrsImpl) RevokeAPIKey(ctx context.Context, userID, id int64) error {
+	return c.svc.RevokeAPIKey(ctx, userID, id)
+}
+
+func (c *cqrsImpl) EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error) {
+	return c.svc.EnrollTOTP(ctx, userID)
+}
+
+func (c *cqrsImpl) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	return c.svc.ConfirmTOTP(ctx, userID, code)
+}
+
+func (c *cqrsImpl) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	return c.svc.DisableTOTP(ctx, userID, code)
+}
+
+func (c *cqrsImpl) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	return c.svc.RegenerateRecoveryCodes(ctx, userID, code)
+}
+
+func (c *cqrsImpl) BeginLogin(ctx context.Context, user *entities.User) (*entities.TokenPair, *entities.LoginChallenge, error) {
+	return c.svc.BeginLogin(ctx, user)
+}
+
+func (c *cqrsImpl) CompleteLogin(ctx context.Context, challengeToken, code string) (*entities.TokenPair, error) {
+	return c.svc.CompleteLogin(ctx, challengeToken, code)
+}
+
+func (c *cqrsImpl) VerifyStepUp(ctx context.Context, userID int64, code string) error {
+	return c.svc.VerifyStepUp(ctx, userID, code)
+}
+
 // Queries
 func (c *cqrsImpl) GetUserByID(ctx context.Context, id int64) (*entities.User, error) {
 	return c.svc.GetUserByID(ctx, id)
@@ -98,6 +212,58 @@ func (c *cqrsImpl) GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*
 	return c.svc.GetPortfoliosByUserID(ctx, userID)
 }
 
+func (c *cqrsImpl) GetPortfolioValuation(ctx context.Context, userID int64) (*entities.PortfolioValuation, error) {
+	return c.svc.GetPortfolioValuation(ctx, userID)
+}
+
 func (c *cqrsImpl) GetHistoryByUserID(ctx context.Context, userID int64) ([]*entities.History, error) {
 	return c.svc.GetHistoryByUserID(ctx, userID)
 }
+
+func (c *cqrsImpl) GetLedgerEntries(ctx context.Context, userID int64) ([]*entities.LedgerEntry, error) {
+	return c.svc.GetLedgerEntries(ctx, userID)
+}
+
+func (c *cqrsImpl) ReconcileLedger(ctx context.Context) ([]*entities.BalanceMismatch, error) {
+	return c.svc.ReconcileLedger(ctx)
+}
+
+func (c *cqrsImpl) GetAuditLog(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error) {
+	return c.svc.GetAuditLog(ctx, f)
+}
+
+func (c *cqrsImpl) VerifyAuditLog(ctx context.Context) (*entities.AuditVerification, error) {
+	return c.svc.VerifyAuditLog(ctx)
+}
+
+func (c *cqrsImpl) GetCandles(ctx context.Context, stockID int64, interval entities.CandleInterval, from, to time.Time) ([]*entities.Candle, error) {
+	return c.svc.GetCandles(ctx, stockID, interval, from, to)
+}
+
+func (c *cqrsImpl) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	return c.svc.IsTokenRevoked(ctx, jti)
+}
+
+func (c *cqrsImpl) GetRolePermissions(ctx context.Context, role entities.Role) (entities.PermissionSet, error) {
+	return c.svc.GetRolePermissions(ctx, role)
+}
+
+func (c *cqrsImpl) ListRoles(ctx context.Context) ([]*entities.RoleInfo, error) {
+	return c.svc.ListRoles(ctx)
+}
+
+func (c *cqrsImpl) ListPermissions(ctx context.Context) ([]*entities.PermissionInfo, error) {
+	return c.svc.ListPermissions(ctx)
+}
+
+func (c *cqrsImpl) ListAPIKeys(ctx context.Context, userID int64) ([]*entities.APIKey, error) {
+	return c.svc.ListAPIKeys(ctx, userID)
+}
+
+func (c *cqrsImpl) AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*entities.APIKey, *entities.User, error) {
+	return c.svc.AuthenticateAPIKey(ctx, key, clientIP)
+}
+
+func (c *cqrsImpl) GetTwoFactorStatus(ctx context.Context, userID int64) (*entities.TwoFactorStatus, error) {
+	return c.svc.GetTwoFactorStatus(ctx, userID)
+}
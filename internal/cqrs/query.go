@@ -2,6 +2,7 @@ package cqrs
 
 import (
 	"context"
+	"time"
 
 	"github.com/Skapar/backend/internal/models/entities"
 )
@@ -10,15 +11,35 @@ type Query interface {
 	GetUserByID(ctx context.Context, id int64) (*entities.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*entities.User, error)
 	GetAllUsers(ctx context.Context) ([]*entities.User, error)
+	ListInvites(ctx context.Context) ([]*entities.Invite, error)
 
 	GetStockByID(ctx context.Context, id int64) (*entities.Stock, error)
 	GetAllStocks(ctx context.Context) ([]*entities.Stock, error)
+	GetCandles(ctx context.Context, stockID int64, interval entities.CandleInterval, from, to time.Time) ([]*entities.Candle, error)
 
 	GetOrdersByUserID(ctx context.Context, userID int64) ([]*entities.Order, error)
 	GetOrderByID(ctx context.Context, orderID int64) (*entities.Order, error)
 
 	GetPortfolio(ctx context.Context, userID, stockID int64) (*entities.Portfolio, error)
 	GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*entities.Portfolio, error)
+	GetPortfolioValuation(ctx context.Context, userID int64) (*entities.PortfolioValuation, error)
 
 	GetHistoryByUserID(ctx context.Context, userID int64) ([]*entities.History, error)
+
+	GetLedgerEntries(ctx context.Context, userID int64) ([]*entities.LedgerEntry, error)
+	ReconcileLedger(ctx context.Context) ([]*entities.BalanceMismatch, error)
+
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+
+	GetRolePermissions(ctx context.Context, role entities.Role) (entities.PermissionSet, error)
+	ListRoles(ctx context.Context) ([]*entities.RoleInfo, error)
+	ListPermissions(ctx context.Context) ([]*entities.PermissionInfo, error)
+
+	ListAPIKeys(ctx context.Context, userID int64) ([]*entities.APIKey, error)
+	AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*entities.APIKey, *entities.User, error)
+
+	GetTwoFactorStatus(ctx context.Context, userID int64) (*entities.TwoFactorStatus, error)
+
+	GetAuditLog(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error)
+	VerifyAuditLog(ctx context.Context) (*entities.AuditVerification, error)
 }
@@ -0,0 +1,150 @@
+package grpcserver
+
+import (
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"github.com/shopspring/decimal"
+)
+
+func unix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func unixPtr(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return unix(*t)
+}
+
+func decimalPtr(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func int64Ptr(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
+
+func toUser(u *entities.User) *pb.User {
+	return &pb.User{
+		Id:              u.ID,
+		Email:           u.Email,
+		Role:            string(u.Role),
+		Balance:         u.Balance.String(),
+		ReservedBalance: u.ReservedBalance.String(),
+		CostBasisMethod: string(u.CostBasisMethod),
+		CreatedAt:       unix(u.CreatedAt),
+	}
+}
+
+func toStock(s *entities.Stock) *pb.Stock {
+	return &pb.Stock{
+		Id:        s.ID,
+		Symbol:    s.Symbol,
+		Name:      s.Name,
+		Price:     s.Price.String(),
+		TickSize:  s.TickSize.String(),
+		LotSize:   s.LotSize.String(),
+		UpdatedAt: unix(s.UpdatedAt),
+	}
+}
+
+func toCandle(c *entities.Candle) *pb.Candle {
+	return &pb.Candle{
+		OpenTime: unix(c.OpenTime),
+		Open:     c.Open.String(),
+		High:     c.High.String(),
+		Low:      c.Low.String(),
+		Close:    c.Close.String(),
+		Volume:   c.Volume.String(),
+	}
+}
+
+func toOrder(o *entities.Order) *pb.Order {
+	return &pb.Order{
+		Id:             o.ID,
+		UserId:         o.UserID,
+		StockId:        o.StockID,
+		Type:           string(o.OrderType),
+		Kind:           string(o.Kind),
+		Status:         string(o.Status),
+		Quantity:       o.Quantity.String(),
+		Price:          o.Price.String(),
+		LimitPrice:     decimalPtr(o.LimitPrice),
+		TriggerPrice:   decimalPtr(o.TriggerPrice),
+		TrailingOffset: decimalPtr(o.TrailingOffset),
+		TimeInForce:    string(o.TimeInForce),
+		ExpiresAt:      unixPtr(o.ExpiresAt),
+		FilledQuantity: o.FilledQuantity.String(),
+		AvgFillPrice:   o.AvgFillPrice.String(),
+		CreatedAt:      unix(o.CreatedAt),
+		UpdatedAt:      unix(o.UpdatedAt),
+	}
+}
+
+func toPortfolio(p *entities.Portfolio) *pb.Portfolio {
+	return &pb.Portfolio{
+		Id:                p.ID,
+		UserId:            p.UserID,
+		StockId:           p.StockID,
+		Quantity:          p.Quantity.String(),
+		LockedQuantity:    p.LockedQuantity.String(),
+		AvailableQuantity: p.AvailableQuantity.String(),
+		UpdatedAt:         unix(p.UpdatedAt),
+	}
+}
+
+func toValuation(v *entities.PortfolioValuation) *pb.PortfolioValuation {
+	out := &pb.PortfolioValuation{
+		Positions:     make([]*pb.Position, 0, len(v.Positions)),
+		CostBasis:     v.CostBasis.String(),
+		MarketValue:   v.MarketValue.String(),
+		UnrealizedPnl: v.UnrealizedPnL.String(),
+	}
+	for _, p := range v.Positions {
+		out.Positions = append(out.Positions, &pb.Position{
+			Portfolio:     toPortfolio(&p.Portfolio),
+			Symbol:        p.Symbol,
+			AvgCost:       p.AvgCost.String(),
+			CostBasis:     p.CostBasis.String(),
+			MarketPrice:   p.MarketPrice.String(),
+			MarketValue:   p.MarketValue.String(),
+			UnrealizedPnl: p.UnrealizedPnL.String(),
+		})
+	}
+	return out
+}
+
+func toHistory(h *entities.History) *pb.History {
+	return &pb.History{
+		Id:          h.ID,
+		UserId:      h.UserID,
+		OrderId:     int64Ptr(h.OrderID),
+		StockId:     int64Ptr(h.StockID),
+		Action:      string(h.Action),
+		Details:     h.Details,
+		Amount:      h.Amount.String(),
+		RealizedPnl: decimalPtr(h.RealizedPnL),
+		CreatedAt:   unix(h.CreatedAt),
+	}
+}
+
+func toLoginResponse(pair *entities.TokenPair) *pb.LoginResponse {
+	return &pb.LoginResponse{
+		Token:            pair.AccessToken,
+		ExpiresIn:        pair.AccessExpiresAt.Unix(),
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresIn: pair.RefreshExpiresAt.Unix(),
+	}
+}
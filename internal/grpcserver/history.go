@@ -0,0 +1,51 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"github.com/shopspring/decimal"
+)
+
+func (s *Server) AddHistory(ctx context.Context, req *pb.AddHistoryRequest) (*pb.AddHistoryResponse, error) {
+	amount, err := parseDecimal("amount", req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &entities.History{
+		UserID:  scopedUserID(ctx, req.UserId, entities.PermHistoryWriteAny),
+		Action:  entities.HistoryAction(req.Action),
+		Details: req.Details,
+		Amount:  decimal.Zero,
+	}
+	if req.OrderId != 0 {
+		rec.OrderID = &req.OrderId
+	}
+	if req.StockId != 0 {
+		rec.StockID = &req.StockId
+	}
+	if amount != nil {
+		rec.Amount = *amount
+	}
+
+	id, err := s.cmd.AddHistoryRecord(ctx, rec)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to add history record")
+	}
+	return &pb.AddHistoryResponse{HistoryId: id}, nil
+}
+
+func (s *Server) ListHistory(ctx context.Context, req *pb.ListHistoryRequest) (*pb.ListHistoryResponse, error) {
+	history, err := s.query.GetHistoryByUserID(ctx, scopedUserID(ctx, req.UserId, entities.PermHistoryReadAny))
+	if err != nil {
+		return nil, s.toStatus(err, "failed to get history")
+	}
+
+	resp := &pb.ListHistoryResponse{History: make([]*pb.History, 0, len(history))}
+	for _, h := range history {
+		resp.History = append(resp.History, toHistory(h))
+	}
+	return resp, nil
+}
@@ -0,0 +1,208 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/Skapar/backend/internal/audit"
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	claimsKey      struct{}
+	permissionsKey struct{}
+)
+
+// publicMethods доступны без токена.
+var publicMethods = map[string]bool{
+	pb.StockService_CreateUser_FullMethodName:     true,
+	pb.StockService_Login_FullMethodName:          true,
+	pb.StockService_LoginTwoFactor_FullMethodName: true,
+	pb.StockService_RefreshToken_FullMethodName:   true,
+}
+
+// methodPermissions — права, которые требует метод; как RequirePermission на маршрутах REST.
+// Методы без записи доступны любому пользователю с валидным токеном.
+var methodPermissions = map[string]entities.Permission{
+	pb.StockService_GetUser_FullMethodName:    entities.PermUsersReadAny,
+	pb.StockService_ListUsers_FullMethodName:  entities.PermUsersReadAny,
+	pb.StockService_UpdateUser_FullMethodName: entities.PermUsersAdmin,
+	pb.StockService_DeleteUser_FullMethodName: entities.PermUsersAdmin,
+
+	pb.StockService_CreateStock_FullMethodName: entities.PermStocksWrite,
+	pb.StockService_GetStock_FullMethodName:    entities.PermStocksRead,
+	pb.StockService_ListStocks_FullMethodName:  entities.PermStocksRead,
+	pb.StockService_UpdateStock_FullMethodName: entities.PermStocksWrite,
+	pb.StockService_DeleteStock_FullMethodName: entities.PermStocksWrite,
+	pb.StockService_GetCandles_FullMethodName:  entities.PermStocksRead,
+
+	pb.StockService_CreateOrder_FullMethodName:       entities.PermOrdersWrite,
+	pb.StockService_ListOrders_FullMethodName:        entities.PermOrdersRead,
+	pb.StockService_UpdateOrderStatus_FullMethodName: entities.PermOrdersWrite,
+	pb.StockService_CancelOrder_FullMethodName:       entities.PermOrdersWrite,
+
+	pb.StockService_GetPortfolio_FullMethodName:       entities.PermPortfolioRead,
+	pb.StockService_UpdatePortfolio_FullMethodName:    entities.PermPortfolioWriteAny,
+	pb.StockService_GetMyPortfolio_FullMethodName:     entities.PermPortfolioRead,
+	pb.StockService_SetCostBasisMethod_FullMethodName: entities.PermPortfolioWrite,
+
+	pb.StockService_AddHistory_FullMethodName:  entities.PermHistoryWrite,
+	pb.StockService_ListHistory_FullMethodName: entities.PermHistoryRead,
+
+	pb.StockService_StreamQuotes_FullMethodName:   entities.PermStocksRead,
+	pb.StockService_StreamMyOrders_FullMethodName: entities.PermOrdersRead,
+}
+
+// UnaryAuthInterceptor проверяет JWT из metadata "authorization: Bearer <token>".
+func (s *Server) UnaryAuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// StreamAuthInterceptor — то же для стриминговых методов.
+func (s *Server) StreamAuthInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	var tokenStr, apiKey, requestID string
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-request-id"); len(values) > 0 {
+		requestID = values[0]
+	}
+	meta := &audit.Meta{IP: peerIP(ctx), RequestID: audit.RequestID(requestID)}
+	ctx = audit.WithMeta(ctx, meta)
+	if publicMethods[method] {
+		return ctx, nil
+	}
+
+	if md != nil {
+		if values := md.Get("authorization"); len(values) > 0 {
+			tokenStr = strings.TrimPrefix(values[0], "Bearer ")
+		}
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			apiKey = values[0]
+		}
+	}
+
+	var (
+		claims *auth.Claims
+		perms  entities.PermissionSet
+		err    error
+	)
+	switch {
+	case tokenStr != "":
+		claims, perms, err = s.authenticateToken(ctx, tokenStr)
+	case apiKey != "":
+		claims, perms, err = s.authenticateAPIKey(ctx, apiKey)
+	default:
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if required, ok := methodPermissions[method]; ok && !perms.Has(required) {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+	meta.SetActor(claims.UserID, claims.Role)
+
+	ctx = context.WithValue(ctx, claimsKey{}, claims)
+	return context.WithValue(ctx, permissionsKey{}, perms), nil
+}
+
+func (s *Server) authenticateToken(ctx context.Context, tokenStr string) (*auth.Claims, entities.PermissionSet, error) {
+	claims, err := auth.ParseToken(s.keys, tokenStr)
+	if err != nil {
+		return nil, nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	revoked, err := s.query.IsTokenRevoked(ctx, claims.ID)
+	if err != nil {
+		return nil, nil, status.Error(codes.Unavailable, "token revocation check unavailable")
+	}
+	if revoked {
+		return nil, nil, status.Error(codes.Unauthenticated, "token revoked")
+	}
+
+	perms, err := s.query.GetRolePermissions(ctx, entities.Role(claims.Role))
+	if err != nil {
+		return nil, nil, status.Error(codes.Unavailable, "permission check unavailable")
+	}
+	return claims, perms, nil
+}
+
+// authenticateAPIKey — как X-API-Key в REST: права роли урезаются скоупами ключа.
+// Claims без jti: отзывать через Logout нечего, ключ отзывается отдельно.
+func (s *Server) authenticateAPIKey(ctx context.Context, apiKey string) (*auth.Claims, entities.PermissionSet, error) {
+	key, user, err := s.query.AuthenticateAPIKey(ctx, apiKey, peerIP(ctx))
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidAPIKey) {
+			return nil, nil, status.Error(codes.Unauthenticated, "invalid api key")
+		}
+		return nil, nil, status.Error(codes.Unavailable, "api key check unavailable")
+	}
+
+	perms, err := s.query.GetRolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, nil, status.Error(codes.Unavailable, "permission check unavailable")
+	}
+	return &auth.Claims{UserID: user.ID, Role: string(user.Role)}, key.Restrict(perms), nil
+}
+
+// authStream подменяет контекст стрима на контекст с claims.
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (a *authStream) Context() context.Context {
+	return a.ctx
+}
+
+// caller возвращает пользователя из токена и его права; интерцептор гарантирует их наличие.
+func caller(ctx context.Context) (userID int64, perms entities.PermissionSet) {
+	perms, _ = ctx.Value(permissionsKey{}).(entities.PermissionSet)
+	claims, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	if claims == nil {
+		return 0, perms
+	}
+	return claims.UserID, perms
+}
+
+// scopedUserID — как в REST: с правом anyPerm можно указать чужой user_id, иначе всегда свой.
+func scopedUserID(ctx context.Context, requested int64, anyPerm entities.Permission) int64 {
+	userID, perms := caller(ctx)
+	if requested != 0 && perms.Has(anyPerm) {
+		return requested
+	}
+	return userID
+}
+
+// peerIP — адрес клиента из соединения, без порта.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,181 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	if req.Type != "BUY" && req.Type != "SELL" {
+		return nil, status.Error(codes.InvalidArgument, "type must be BUY or SELL")
+	}
+	quantity, err := parseDecimal("quantity", req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	if quantity == nil || !quantity.IsPositive() {
+		return nil, status.Error(codes.InvalidArgument, "quantity must be positive")
+	}
+
+	userID, _ := caller(ctx)
+	order := entities.Order{
+		UserID:    userID,
+		StockID:   req.StockId,
+		Quantity:  *quantity,
+		OrderType: entities.OrderType(req.Type),
+		Status:    entities.OrderNew,
+	}
+	if order.LimitPrice, err = parseDecimal("limit_price", req.LimitPrice); err != nil {
+		return nil, err
+	}
+	if order.TriggerPrice, err = parseDecimal("trigger_price", req.TriggerPrice); err != nil {
+		return nil, err
+	}
+	if order.TrailingOffset, err = parseDecimal("trailing_offset", req.TrailingOffset); err != nil {
+		return nil, err
+	}
+
+	order.Kind = entities.OrderKind(strings.ToUpper(req.Kind))
+	if order.Kind == "" {
+		order.Kind = entities.OrderMarket
+	}
+	if msg := validateOrderKind(&order); msg != "" {
+		return nil, status.Error(codes.InvalidArgument, msg)
+	}
+
+	order.TimeInForce = entities.TimeInForce(strings.ToUpper(req.TimeInForce))
+	if order.TimeInForce == "" {
+		order.TimeInForce = entities.TimeInForceGTC
+	}
+	if !order.TimeInForce.Valid() {
+		return nil, status.Error(codes.InvalidArgument, "time_in_force must be GTC, DAY, IOC, FOK or GTD")
+	}
+	if order.TimeInForce == entities.TimeInForceGTD {
+		expireAt := time.Unix(req.ExpireAt, 0)
+		if req.ExpireAt == 0 || !expireAt.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "expire_at must be in the future for GTD orders")
+		}
+		order.ExpiresAt = &expireAt
+	}
+
+	stock, err := s.query.GetStockByID(ctx, order.StockID)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to fetch stock")
+	}
+
+	order.Price = stock.Price.Mul(order.Quantity)
+	if order.LimitPrice != nil {
+		order.Price = order.LimitPrice.Mul(order.Quantity)
+	}
+
+	id, err := s.cmd.CreateOrder(ctx, &order)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to create order")
+	}
+
+	order.ID = id
+	if err := s.cmd.ExecuteOrder(ctx, &order); err != nil {
+		return nil, s.toStatus(err, "failed to execute order")
+	}
+
+	return &pb.CreateOrderResponse{OrderId: id, Status: string(order.Status)}, nil
+}
+
+func (s *Server) ListOrders(ctx context.Context, req *pb.ListOrdersRequest) (*pb.ListOrdersResponse, error) {
+	orders, err := s.query.GetOrdersByUserID(ctx, scopedUserID(ctx, req.UserId, entities.PermOrdersReadAny))
+	if err != nil {
+		return nil, s.toStatus(err, "failed to get orders")
+	}
+
+	resp := &pb.ListOrdersResponse{Orders: make([]*pb.Order, 0, len(orders))}
+	for _, o := range orders {
+		resp.Orders = append(resp.Orders, toOrder(o))
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.UpdateOrderStatusResponse, error) {
+	orderStatus := entities.OrderStatus(strings.ToUpper(req.Status))
+	if !orderStatus.Valid() {
+		return nil, status.Error(codes.InvalidArgument, "unknown status")
+	}
+
+	if err := s.checkOrderOwner(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	// без orders:write:any заявку можно только отменить
+	if _, perms := caller(ctx); !perms.Has(entities.PermOrdersWriteAny) && orderStatus != entities.OrderCancelled {
+		return nil, status.Error(codes.PermissionDenied, "access denied")
+	}
+
+	if err := s.cmd.UpdateOrderStatus(ctx, req.Id, orderStatus); err != nil {
+		return nil, s.toStatus(err, "failed to update status")
+	}
+	return &pb.UpdateOrderStatusResponse{}, nil
+}
+
+func (s *Server) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	if err := s.checkOrderOwner(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	if err := s.cmd.CancelOrder(ctx, req.Id); err != nil {
+		return nil, s.toStatus(err, "failed to cancel order")
+	}
+	return &pb.CancelOrderResponse{}, nil
+}
+
+// checkOrderOwner пропускает владельца заявки и обладателя orders:write:any.
+func (s *Server) checkOrderOwner(ctx context.Context, orderID int64) error {
+	order, err := s.query.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return s.toStatus(err, "order not found")
+	}
+
+	userID, perms := caller(ctx)
+	if !perms.CanAccess(userID, order.UserID, entities.PermOrdersWriteAny) {
+		return status.Error(codes.PermissionDenied, "access denied")
+	}
+	return nil
+}
+
+// validateOrderKind проверяет цены, обязательные для kind, и обнуляет лишние.
+// Возвращает текст ошибки или пустую строку.
+func validateOrderKind(o *entities.Order) string {
+	positive := func(v *decimal.Decimal) bool { return v != nil && v.IsPositive() }
+
+	switch o.Kind {
+	case entities.OrderMarket:
+		o.LimitPrice, o.TriggerPrice, o.TrailingOffset = nil, nil, nil
+	case entities.OrderLimit:
+		if !positive(o.LimitPrice) {
+			return "limit_price must be positive for LIMIT orders"
+		}
+		o.TriggerPrice, o.TrailingOffset = nil, nil
+	case entities.OrderStop:
+		if !positive(o.TriggerPrice) {
+			return "trigger_price must be positive for STOP orders"
+		}
+		o.LimitPrice, o.TrailingOffset = nil, nil
+	case entities.OrderStopLimit:
+		if !positive(o.TriggerPrice) || !positive(o.LimitPrice) {
+			return "trigger_price and limit_price must be positive for STOP_LIMIT orders"
+		}
+		o.TrailingOffset = nil
+	case entities.OrderTrailingStop:
+		if !positive(o.TrailingOffset) {
+			return "trailing_offset must be positive for TRAILING_STOP orders"
+		}
+		o.LimitPrice, o.TriggerPrice = nil, nil
+	default:
+		return "kind must be MARKET, LIMIT, STOP, STOP_LIMIT or TRAILING_STOP"
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetPortfolio(ctx context.Context, req *pb.GetPortfolioRequest) (*pb.Portfolio, error) {
+	p, err := s.query.GetPortfolio(ctx, scopedUserID(ctx, req.UserId, entities.PermPortfolioReadAny), req.StockId)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to get portfolio")
+	}
+	if p == nil {
+		return nil, status.Error(codes.NotFound, "no portfolio record found")
+	}
+	return toPortfolio(p), nil
+}
+
+func (s *Server) UpdatePortfolio(ctx context.Context, req *pb.UpdatePortfolioRequest) (*pb.UpdatePortfolioResponse, error) {
+	quantity, err := parseDecimal("quantity", req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	if req.StockId == 0 || quantity == nil || quantity.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "stock_id and quantity are required")
+	}
+
+	if err := s.cmd.CreateOrUpdatePortfolio(ctx, &entities.Portfolio{
+		UserID:   scopedUserID(ctx, req.UserId, entities.PermPortfolioWriteAny),
+		StockID:  req.StockId,
+		Quantity: *quantity,
+	}); err != nil {
+		return nil, s.toStatus(err, "failed to update portfolio")
+	}
+	return &pb.UpdatePortfolioResponse{}, nil
+}
+
+func (s *Server) GetMyPortfolio(ctx context.Context, _ *pb.GetMyPortfolioRequest) (*pb.PortfolioValuation, error) {
+	userID, _ := caller(ctx)
+
+	valuation, err := s.query.GetPortfolioValuation(ctx, userID)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to fetch portfolio")
+	}
+	return toValuation(valuation), nil
+}
+
+func (s *Server) SetCostBasisMethod(ctx context.Context, req *pb.SetCostBasisMethodRequest) (*pb.SetCostBasisMethodResponse, error) {
+	userID, _ := caller(ctx)
+
+	if err := s.cmd.SetCostBasisMethod(ctx, userID, entities.CostBasisMethod(req.Method)); err != nil {
+		return nil, s.toStatus(err, "failed to update cost basis method")
+	}
+	return &pb.SetCostBasisMethodResponse{}, nil
+}
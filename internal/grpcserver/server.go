@@ -0,0 +1,90 @@
+package grpcserver
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/stream"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/Skapar/backend/pkg/logger"
+	pb "github.com/Skapar/backend/proto"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server — gRPC-реализация StockService поверх тех же cqrs.Command/cqrs.Query, что и REST.
+type Server struct {
+	pb.UnimplementedStockServiceServer
+
+	cmd   cqrs.Command
+	query cqrs.Query
+	hub   *stream.Hub
+	keys  *auth.KeySet
+	log   logger.Logger
+
+	// done закрывается при остановке: стримы завершаются сами, и GracefulStop не ждёт их до таймаута
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type ServerConfig struct {
+	Command cqrs.Command
+	Query   cqrs.Query
+	Hub     *stream.Hub // тот же хаб, что у WebSocket и SSE
+	Keys    *auth.KeySet
+	Log     logger.Logger
+}
+
+func NewServer(c *ServerConfig) *Server {
+	return &Server{
+		cmd:   c.Command,
+		query: c.Query,
+		hub:   c.Hub,
+		keys:  c.Keys,
+		log:   c.Log,
+		done:  make(chan struct{}),
+	}
+}
+
+// CloseStreams завершает открытые стримы; вызывается перед GracefulStop.
+func (s *Server) CloseStreams() {
+	s.closeOnce.Do(func() { close(s.done) })
+}
+
+// toStatus мапит доменные ошибки в gRPC-коды; msg — префикс для клиента.
+func (s *Server) toStatus(err error, msg string) error {
+	var transitionErr *entities.OrderTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		return status.Error(codes.FailedPrecondition, msg+": "+err.Error())
+	case errors.Is(err, entities.ErrEmailNotVerified):
+		return status.Error(codes.PermissionDenied, msg+": "+err.Error())
+	case errors.Is(err, database.ErrNoRows):
+		return status.Error(codes.NotFound, msg+": not found")
+	case errors.Is(err, entities.ErrInsufficientFunds), errors.Is(err, entities.ErrInsufficientShares),
+		errors.Is(err, entities.ErrInvalidTickSize), errors.Is(err, entities.ErrInvalidLotSize),
+		errors.Is(err, entities.ErrInvalidAmount), errors.Is(err, entities.ErrInvalidCostBasisMethod),
+		errors.Is(err, entities.ErrInvalidCandleInterval), errors.Is(err, entities.ErrInvalidCandleRange),
+		errors.Is(err, entities.ErrUnknownRole):
+		return status.Error(codes.InvalidArgument, msg+": "+err.Error())
+	default:
+		s.log.Errorf("gRPC %s: %v", msg, err)
+		return status.Error(codes.Internal, msg)
+	}
+}
+
+// parseDecimal разбирает необязательное десятичное поле: пустая строка — nil.
+func parseDecimal(field, v string) (*decimal.Decimal, error) {
+	if v == "" {
+		return nil, nil
+	}
+	d, err := decimal.NewFromString(v)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %s", field, v)
+	}
+	return &d, nil
+}
@@ -0,0 +1,166 @@
+package grpcserver
+
+import (
+	"context"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxCandles ограничивает число баров в одном ответе, как в REST.
+const maxCandles = 5000
+
+func (s *Server) CreateStock(ctx context.Context, req *pb.CreateStockRequest) (*pb.CreateStockResponse, error) {
+	price, err := parseDecimal("price", req.Price)
+	if err != nil {
+		return nil, err
+	}
+	tickSize, err := parseDecimal("tick_size", req.TickSize)
+	if err != nil {
+		return nil, err
+	}
+	lotSize, err := parseDecimal("lot_size", req.LotSize)
+	if err != nil {
+		return nil, err
+	}
+	if req.Symbol == "" || req.Name == "" || price == nil || !price.IsPositive() {
+		return nil, status.Error(codes.InvalidArgument, "symbol, name and positive price are required")
+	}
+
+	stock := &entities.Stock{
+		Symbol:    req.Symbol,
+		Name:      req.Name,
+		Price:     *price,
+		UpdatedAt: time.Now(),
+	}
+	// нулевые шаги сервис заменит значениями по умолчанию
+	if tickSize != nil {
+		stock.TickSize = *tickSize
+	}
+	if lotSize != nil {
+		stock.LotSize = *lotSize
+	}
+
+	id, err := s.cmd.CreateStock(ctx, stock)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to create stock")
+	}
+	return &pb.CreateStockResponse{Id: id}, nil
+}
+
+func (s *Server) GetStock(ctx context.Context, req *pb.GetStockRequest) (*pb.Stock, error) {
+	stock, err := s.query.GetStockByID(ctx, req.Id)
+	if err != nil {
+		return nil, s.toStatus(err, "stock not found")
+	}
+	return toStock(stock), nil
+}
+
+func (s *Server) ListStocks(ctx context.Context, _ *pb.ListStocksRequest) (*pb.ListStocksResponse, error) {
+	stocks, err := s.query.GetAllStocks(ctx)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to fetch stocks")
+	}
+
+	resp := &pb.ListStocksResponse{Stocks: make([]*pb.Stock, 0, len(stocks))}
+	for _, st := range stocks {
+		resp.Stocks = append(resp.Stocks, toStock(st))
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateStock(ctx context.Context, req *pb.UpdateStockRequest) (*pb.UpdateStockResponse, error) {
+	price, err := parseDecimal("price", req.Price)
+	if err != nil {
+		return nil, err
+	}
+	tickSize, err := parseDecimal("tick_size", req.TickSize)
+	if err != nil {
+		return nil, err
+	}
+	lotSize, err := parseDecimal("lot_size", req.LotSize)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.query.GetStockByID(ctx, req.Id)
+	if err != nil {
+		return nil, s.toStatus(err, "stock not found")
+	}
+
+	if req.Symbol != "" {
+		existing.Symbol = req.Symbol
+	}
+	if req.Name != "" {
+		existing.Name = req.Name
+	}
+	if price != nil {
+		if !price.IsPositive() {
+			return nil, status.Error(codes.InvalidArgument, "price must be positive")
+		}
+		existing.Price = *price
+	}
+	if tickSize != nil {
+		if tickSize.IsNegative() {
+			return nil, status.Error(codes.InvalidArgument, "tick_size must not be negative")
+		}
+		existing.TickSize = *tickSize
+	}
+	if lotSize != nil {
+		if lotSize.IsNegative() {
+			return nil, status.Error(codes.InvalidArgument, "lot_size must not be negative")
+		}
+		existing.LotSize = *lotSize
+	}
+
+	existing.UpdatedAt = time.Now()
+
+	if err := s.cmd.UpdateStock(ctx, existing); err != nil {
+		return nil, s.toStatus(err, "failed to update stock")
+	}
+	return &pb.UpdateStockResponse{}, nil
+}
+
+func (s *Server) DeleteStock(ctx context.Context, req *pb.DeleteStockRequest) (*pb.DeleteStockResponse, error) {
+	if err := s.cmd.DeleteStock(ctx, req.Id); err != nil {
+		return nil, s.toStatus(err, "failed to delete stock")
+	}
+	return &pb.DeleteStockResponse{}, nil
+}
+
+func (s *Server) GetCandles(ctx context.Context, req *pb.GetCandlesRequest) (*pb.GetCandlesResponse, error) {
+	interval := entities.CandleInterval(req.Interval)
+	if interval == "" {
+		interval = entities.Candle1m
+	}
+	width := interval.Duration()
+	if width == 0 {
+		return nil, status.Error(codes.InvalidArgument, entities.ErrInvalidCandleInterval.Error())
+	}
+
+	to := time.Now()
+	if req.To != 0 {
+		to = time.Unix(req.To, 0)
+	}
+	from := to.Add(-100 * width)
+	if req.From != 0 {
+		from = time.Unix(req.From, 0)
+	}
+	if to.Sub(from) > maxCandles*width {
+		return nil, status.Error(codes.InvalidArgument, "range too large: narrow from/to or use a wider interval")
+	}
+
+	candles, err := s.query.GetCandles(ctx, req.StockId, interval, from, to)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to fetch candles")
+	}
+
+	resp := &pb.GetCandlesResponse{Candles: make([]*pb.Candle, 0, len(candles))}
+	for _, c := range candles {
+		resp.Candles = append(resp.Candles, toCandle(c))
+	}
+	return resp, nil
+}
@@ -0,0 +1,155 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/stream"
+	pb "github.com/Skapar/backend/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) StreamQuotes(req *pb.StreamQuotesRequest, ss grpc.ServerStreamingServer[pb.Quote]) error {
+	channels, err := s.priceChannels(ss.Context(), req.Symbols)
+	if err != nil {
+		return err
+	}
+
+	return s.pump(ss.Context(), channels, req.LastEventId, func(ev *entities.StreamEvent) error {
+		var price entities.PriceEvent
+		if err := json.Unmarshal(ev.Data, &price); err != nil {
+			s.log.Warnf("grpc stream: bad price event %s: %v", ev.ID, err)
+			return nil
+		}
+		return ss.Send(&pb.Quote{
+			EventId: ev.ID,
+			StockId: price.StockID,
+			Symbol:  price.Symbol,
+			Price:   price.Price.String(),
+			Time:    unix(ev.Time),
+		})
+	})
+}
+
+func (s *Server) StreamMyOrders(req *pb.StreamMyOrdersRequest, ss grpc.ServerStreamingServer[pb.OrderUpdate]) error {
+	userID, _ := caller(ss.Context())
+	channels := []string{entities.UserChannel(userID)}
+
+	return s.pump(ss.Context(), channels, req.LastEventId, func(ev *entities.StreamEvent) error {
+		update := &pb.OrderUpdate{EventId: ev.ID, Type: string(ev.Type), Time: unix(ev.Time)}
+
+		var err error
+		switch ev.Type {
+		case entities.EventOrder:
+			var o entities.OrderEvent
+			if err = json.Unmarshal(ev.Data, &o); err == nil {
+				update.Order = &pb.OrderStatusEvent{
+					OrderId:        o.OrderID,
+					StockId:        o.StockID,
+					Type:           string(o.Type),
+					Kind:           string(o.Kind),
+					Status:         string(o.Status),
+					Quantity:       o.Quantity.String(),
+					FilledQuantity: o.FilledQuantity.String(),
+					AvgFillPrice:   o.AvgFillPrice.String(),
+				}
+			}
+		case entities.EventFill:
+			var f entities.FillEvent
+			if err = json.Unmarshal(ev.Data, &f); err == nil {
+				update.Fill = &pb.FillEvent{
+					TradeId:  f.TradeID,
+					OrderId:  f.OrderID,
+					StockId:  f.StockID,
+					Side:     string(f.Side),
+					Price:    f.Price.String(),
+					Quantity: f.Quantity.String(),
+				}
+			}
+		default:
+			return nil
+		}
+		if err != nil {
+			s.log.Warnf("grpc stream: bad %s event %s: %v", ev.Type, ev.ID, err)
+			return nil
+		}
+		return ss.Send(update)
+	})
+}
+
+// pump отправляет события каналов из хаба, пока клиент не отключится.
+// Send блокируется, когда клиент не успевает читать; события копятся в буфере подписки,
+// а при его переполнении хаб закрывает подписку — клиент получит ResourceExhausted
+// и переподключится с last_event_id, как SSE.
+func (s *Server) pump(ctx context.Context, channels []string, lastID string, send func(*entities.StreamEvent) error) error {
+	// подписываемся до чтения буфера, чтобы не потерять события между ними
+	sub := s.hub.Subscribe()
+	sub.Join(channels...)
+	defer sub.Close()
+
+	if lastID != "" {
+		backlog, err := s.hub.Replay(ctx, lastID, channels)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		for _, ev := range backlog {
+			if err := send(ev); err != nil {
+				return err
+			}
+			lastID = ev.ID
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "subscriber too slow, reconnect with last_event_id")
+			}
+			// событие могло уже уйти из буфера докачки
+			if lastID != "" && ev.ID != "" && !stream.EventAfter(ev.ID, lastID) {
+				continue
+			}
+			if err := send(ev); err != nil {
+				return err
+			}
+			if ev.ID != "" {
+				lastID = ev.ID
+			}
+		}
+	}
+}
+
+// priceChannels проверяет, что тикеры существуют, и возвращает их каналы.
+func (s *Server) priceChannels(ctx context.Context, symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "symbols are required")
+	}
+
+	stocks, err := s.query.GetAllStocks(ctx)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to fetch stocks")
+	}
+	known := make(map[string]struct{}, len(stocks))
+	for _, st := range stocks {
+		known[strings.ToUpper(st.Symbol)] = struct{}{}
+	}
+
+	channels := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if _, ok := known[sym]; !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown symbol %q", sym)
+		}
+		channels = append(channels, entities.PriceChannel(sym))
+	}
+	return channels, nil
+}
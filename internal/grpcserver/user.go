@@ -0,0 +1,210 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	pb "github.com/Skapar/backend/proto"
+	"github.com/shopspring/decimal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if req.Email == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	}
+	if !entities.ValidEmail(req.Email) {
+		return nil, status.Error(codes.InvalidArgument, entities.ErrInvalidEmail.Error())
+	}
+	if len(req.Password) < 6 {
+		return nil, status.Error(codes.InvalidArgument, "password must be at least 6 characters")
+	}
+	// роль выдаётся только приглашением или администратором
+	if role := entities.Role(strings.ToUpper(req.Role)); role != "" && role != entities.RoleTrader {
+		return nil, status.Error(codes.PermissionDenied, "role cannot be chosen at registration, use an invite code")
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to hash password")
+	}
+
+	id, err := s.cmd.RegisterUser(ctx, &entities.User{
+		Email:    req.Email,
+		Password: hashedPassword,
+		Balance:  decimal.Zero,
+	}, req.InviteCode)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidInvite) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, s.toStatus(err, "failed to create user")
+	}
+
+	return &pb.CreateUserResponse{UserId: id}, nil
+}
+
+func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	if req.Email == "" || req.Password == "" {
+		return nil, status.Error(codes.InvalidArgument, "email and password are required")
+	}
+
+	user, err := s.cmd.CheckCredentials(ctx, req.Email, req.Password, peerIP(ctx))
+	if err != nil {
+		var locked *entities.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			return nil, status.Errorf(codes.ResourceExhausted, "%s (retry after %s)", err, locked.RetryAfter)
+		case errors.Is(err, entities.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, s.toStatus(err, "failed to check credentials")
+	}
+
+	pair, challenge, err := s.cmd.BeginLogin(ctx, user)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to generate token")
+	}
+	if challenge != nil {
+		return &pb.LoginResponse{
+			TwoFactorRequired:  true,
+			ChallengeToken:     challenge.Token,
+			ChallengeExpiresIn: challenge.ExpiresAt.Unix(),
+		}, nil
+	}
+	return toLoginResponse(pair), nil
+}
+
+func (s *Server) LoginTwoFactor(ctx context.Context, req *pb.LoginTwoFactorRequest) (*pb.LoginResponse, error) {
+	if req.ChallengeToken == "" || req.Code == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_token and code are required")
+	}
+
+	pair, err := s.cmd.CompleteLogin(ctx, req.ChallengeToken, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidTwoFactorCode), errors.Is(err, entities.ErrInvalidLoginChallenge):
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		case errors.Is(err, entities.ErrTwoFactorNotEnabled):
+			return nil, status.Error(codes.Unauthenticated, entities.ErrInvalidLoginChallenge.Error())
+		case errors.Is(err, entities.ErrTwoFactorLocked):
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, s.toStatus(err, "failed to generate token")
+	}
+	return toLoginResponse(pair), nil
+}
+
+func (s *Server) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.LoginResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	pair, err := s.cmd.RefreshTokens(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidRefreshToken) || errors.Is(err, entities.ErrRefreshTokenReused) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return nil, s.toStatus(err, "failed to refresh token")
+	}
+	return toLoginResponse(pair), nil
+}
+
+func (s *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	claims, _ := ctx.Value(claimsKey{}).(*auth.Claims)
+	if claims == nil {
+		return nil, status.Error(codes.Unauthenticated, "missing token")
+	}
+
+	var accessExpiresAt time.Time
+	if claims.ExpiresAt != nil {
+		accessExpiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.cmd.RevokeTokens(ctx, claims.UserID, claims.ID, accessExpiresAt, req.RefreshToken); err != nil {
+		return nil, s.toStatus(err, "failed to logout")
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *Server) GetMe(ctx context.Context, _ *pb.GetMeRequest) (*pb.GetMeResponse, error) {
+	userID, _ := caller(ctx)
+
+	user, err := s.query.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, s.toStatus(err, "user not found")
+	}
+
+	return &pb.GetMeResponse{
+		Email:     user.Email,
+		Balance:   user.Balance.String(),
+		Reserved:  user.ReservedBalance.String(),
+		Available: user.AvailableBalance().String(),
+	}, nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	user, err := s.query.GetUserByID(ctx, req.Id)
+	if err != nil {
+		return nil, s.toStatus(err, "user not found")
+	}
+	return toUser(user), nil
+}
+
+func (s *Server) ListUsers(ctx context.Context, _ *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	users, err := s.query.GetAllUsers(ctx)
+	if err != nil {
+		return nil, s.toStatus(err, "failed to get users")
+	}
+
+	resp := &pb.ListUsersResponse{Users: make([]*pb.User, 0, len(users))}
+	for _, u := range users {
+		resp.Users = append(resp.Users, toUser(u))
+	}
+	return resp, nil
+}
+
+func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	balance, err := parseDecimal("balance", req.Balance)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.query.GetUserByID(ctx, req.Id)
+	if err != nil {
+		return nil, s.toStatus(err, "user not found")
+	}
+
+	if req.Email != "" {
+		user.Email = req.Email
+	}
+	if req.Password != "" {
+		hashed, hashErr := auth.HashPassword(req.Password)
+		if hashErr != nil {
+			return nil, status.Error(codes.Internal, "failed to hash password")
+		}
+		user.Password = hashed
+	}
+	if req.Role != "" {
+		user.Role = entities.Role(strings.ToUpper(req.Role))
+	}
+	if balance != nil {
+		user.Balance = *balance
+	}
+
+	if err := s.cmd.UpdateUser(ctx, user); err != nil {
+		return nil, s.toStatus(err, "failed to update user")
+	}
+	return &pb.UpdateUserResponse{}, nil
+}
+
+func (s *Server) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.DeleteUserResponse, error) {
+	if err := s.cmd.DeleteUser(ctx, req.Id); err != nil {
+		return nil, s.toStatus(err, "failed to delete user")
+	}
+	return &pb.DeleteUserResponse{}, nil
+}
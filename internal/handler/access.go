@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// permissions — права текущего пользователя, их кладёт AuthMiddleware.
+func permissions(c *gin.Context) entities.PermissionSet {
+	v, _ := c.Get("permissions")
+	perms, _ := v.(entities.PermissionSet)
+	return perms
+}
+
+// canAccess — свой ресурс или чужой при наличии права anyPerm.
+func canAccess(c *gin.Context, ownerID int64, anyPerm entities.Permission) bool {
+	return permissions(c).CanAccess(c.GetInt64("userID"), ownerID, anyPerm)
+}
+
+// scopedUserID — user_id из пути, если есть право anyPerm; иначе всегда свой.
+func scopedUserID(c *gin.Context, anyPerm entities.Permission) (int64, error) {
+	tokenUserID := c.GetInt64("userID")
+
+	userIDStr := c.Param("user_id")
+	if userIDStr == "" || !permissions(c).Has(anyPerm) {
+		return tokenUserID, nil
+	}
+	return strconv.ParseInt(userIDStr, 10, 64)
+}
+
+// targetUserID — user_id из тела запроса, если есть право anyPerm и он задан; иначе свой.
+func targetUserID(c *gin.Context, requested int64, anyPerm entities.Permission) int64 {
+	if requested != 0 && permissions(c).Has(anyPerm) {
+		return requested
+	}
+	return c.GetInt64("userID")
+}
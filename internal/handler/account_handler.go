@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+type AccountHandler struct {
+	cmd   cqrs.Command
+	query cqrs.Query
+}
+
+func NewAccountHandler(cmd cqrs.Command, query cqrs.Query) *AccountHandler {
+	return &AccountHandler{cmd: cmd, query: query}
+}
+
+// Deposit godoc
+// @Summary Deposit funds
+// @Tags account
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param body body FundsRequest true "Deposit payload"
+// @Success 200 {object} FundsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /account/deposit [post]
+func (h *AccountHandler) Deposit(c *gin.Context) {
+	h.moveFunds(c, h.cmd.Deposit)
+}
+
+// Withdraw godoc
+// @Summary Withdraw funds
+// @Tags account
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Idempotency key"
+// @Param X-TOTP-Code header string false "TOTP or recovery code, required when two-factor is enabled"
+// @Param body body FundsRequest true "Withdraw payload"
+// @Success 200 {object} FundsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /account/withdraw [post]
+func (h *AccountHandler) Withdraw(c *gin.Context) {
+	h.moveFunds(c, h.cmd.Withdraw)
+}
+
+func (h *AccountHandler) moveFunds(c *gin.Context, move func(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error)) {
+	var req FundsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+	if !req.Amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "amount must be greater than zero"})
+		return
+	}
+
+	userID := c.GetInt64("userID")
+
+	journalID, err := move(c, userID, req.Amount)
+	if err != nil {
+		c.JSON(accountErrorStatus(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := h.query.GetUserByID(c, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, FundsResponse{JournalID: journalID, Balance: user.Balance})
+}
+
+// GetLedger godoc
+// @Summary Get my ledger entries
+// @Tags account
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.LedgerEntry
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /account/ledger [get]
+func (h *AccountHandler) GetLedger(c *gin.Context) {
+	entries, err := h.query.GetLedgerEntries(c, c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get ledger: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// Reconcile godoc
+// @Summary Reconcile user balances against the ledger (ledger:reconcile)
+// @Tags account
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.BalanceMismatch
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /account/reconcile [get]
+func (h *AccountHandler) Reconcile(c *gin.Context) {
+	mismatches, err := h.query.ReconcileLedger(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, mismatches)
+}
+
+// accountErrorStatus мапит доменные ошибки движения средств в HTTP-статус.
+func accountErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, entities.ErrInsufficientFunds), errors.Is(err, entities.ErrInvalidAmount):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	cmd   cqrs.Command
+	query cqrs.Query
+}
+
+func NewAPIKeyHandler(cmd cqrs.Command, query cqrs.Query) *APIKeyHandler {
+	return &APIKeyHandler{cmd: cmd, query: query}
+}
+
+// CreateAPIKey godoc
+// @Summary Create a personal API key
+// @Description The key is returned only once. Send it in the X-API-Key header instead of a Bearer token. Scopes: read, trade, withdraw. A key never gets more permissions than its owner's role.
+// @Tags api-keys
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-TOTP-Code header string false "TOTP or recovery code, required when two-factor is enabled"
+// @Param body body CreateAPIKeyRequest true "Key parameters"
+// @Success 201 {object} CreateAPIKeyResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
+		return
+	}
+	if len(req.Name) > 100 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: name is too long"})
+		return
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: expires_at must be in the future"})
+		return
+	}
+
+	key := &entities.APIKey{
+		UserID:     c.GetInt64("userID"),
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+	}
+
+	plain, err := h.cmd.CreateAPIKey(c, key)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidAPIKeyScope), errors.Is(err, entities.ErrInvalidAllowedIP):
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, entities.ErrTooManyAPIKeys):
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create api key: " + err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateAPIKeyResponse{Key: plain, APIKey: key})
+}
+
+// ListAPIKeys godoc
+// @Summary List my active API keys
+// @Tags api-keys
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.APIKey
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	keys, err := h.query.ListAPIKeys(c, c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get api keys: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke one of my API keys
+// @Tags api-keys
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "API key ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid api key ID"})
+		return
+	}
+
+	if err := h.cmd.RevokeAPIKey(c, c.GetInt64("userID"), id); err != nil {
+		if errors.Is(err, entities.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to revoke api key: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "api key revoked"})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	query cqrs.Query
+}
+
+func NewAuditHandler(query cqrs.Query) *AuditHandler {
+	return &AuditHandler{query: query}
+}
+
+// ListAuditLog godoc
+// @Summary List audit log entries, newest first (audit:read)
+// @Description Keyset pagination: pass next_before_id from the previous page as before_id.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query int false "Actor user ID"
+// @Param action query string false "Action, e.g. USER_UPDATE"
+// @Param target_type query string false "Target type, e.g. user"
+// @Param target_id query string false "Target ID"
+// @Param from query string false "From time (RFC3339)"
+// @Param to query string false "To time (RFC3339)"
+// @Param before_id query int false "Return entries with a smaller ID"
+// @Param limit query int false "Page size (default 50, max 500)"
+// @Success 200 {object} AuditLogResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit [get]
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	f := &entities.AuditFilter{
+		Action:     entities.AuditAction(strings.ToUpper(c.Query("action"))),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+
+	if v := c.Query("actor_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid actor_id"})
+			return
+		}
+		f.ActorID = &id
+	}
+	var err error
+	if f.From, err = queryTime(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid from: expected RFC3339"})
+		return
+	}
+	if f.To, err = queryTime(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid to: expected RFC3339"})
+		return
+	}
+	if v := c.Query("before_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid before_id"})
+			return
+		}
+		f.BeforeID = id
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid limit"})
+			return
+		}
+		f.Limit = n
+	}
+
+	entries, err := h.query.GetAuditLog(c, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get audit log: " + err.Error()})
+		return
+	}
+
+	resp := AuditLogResponse{Entries: entries}
+	// полная страница — возможно, есть ещё; сервис уже подставил лимит по умолчанию
+	if len(entries) > 0 && len(entries) == f.Limit {
+		resp.NextBeforeID = entries[len(entries)-1].ID
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyAuditLog godoc
+// @Summary Verify the audit log hash chain (audit:read)
+// @Description Recomputes every entry hash; broken_at points to the first entry that was altered or follows a removed one.
+// @Tags audit
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entities.AuditVerification
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /audit/verify [get]
+func (h *AuditHandler) VerifyAuditLog(c *gin.Context) {
+	res, err := h.query.VerifyAuditLog(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to verify audit log: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// queryTime читает необязательный RFC3339-параметр; без него — nil.
+func queryTime(c *gin.Context, name string) (*time.Time, error) {
+	v := c.Query(name)
+	if v == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
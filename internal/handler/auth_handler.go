@@ -1,33 +1,35 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
-	"github.com/Skapar/backend/config"
 	"github.com/Skapar/backend/internal/auth"
 	"github.com/Skapar/backend/internal/cqrs"
 	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 type AuthHandler struct {
 	cmd   cqrs.Command
 	query cqrs.Query
-	cfg   *config.Config
+	keys  *auth.KeySet
 }
 
-func NewAuthHandler(cmd cqrs.Command, query cqrs.Query, cfg *config.Config) *AuthHandler {
+func NewAuthHandler(cmd cqrs.Command, query cqrs.Query, keys *auth.KeySet) *AuthHandler {
 	return &AuthHandler{
 		cmd:   cmd,
 		query: query,
-		cfg:   cfg,
+		keys:  keys,
 	}
 }
 
 // Register godoc
 // @Summary Register new user
+// @Description Creates a TRADER account. Other roles are granted only through an invite code or by an administrator.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -45,20 +47,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	}
 
 	// Валидация (так как RegisterRequest не содержит binding теги)
-	if req.Email == "" || req.Password == "" || req.Role == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: email, password, role are required"})
+	if req.Email == "" || req.Password == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: email and password are required"})
 		return
 	}
-	if len(req.Password) < 6 {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: password must be at least 6 characters"})
+	if !entities.ValidEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + entities.ErrInvalidEmail.Error()})
 		return
 	}
-	roleUpper := strings.ToUpper(req.Role)
-	if roleUpper != "TRADER" && roleUpper != "ADMIN" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: role must be TRADER or ADMIN"})
+	if len(req.Password) < 6 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: password must be at least 6 characters"})
 		return
 	}
-
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to hash password"})
@@ -68,12 +68,15 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	user := &entities.User{
 		Email:    req.Email,
 		Password: hashedPassword,
-		Role:     entities.Role(roleUpper),
-		Balance:  0,
+		Balance:  decimal.Zero,
 	}
 
-	id, err := h.cmd.CreateUser(c, user)
+	id, err := h.cmd.RegisterUser(c, user, req.InviteCode)
 	if err != nil {
+		if errors.Is(err, entities.ErrInvalidInvite) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -86,13 +89,17 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Login
+// @Description Repeated failures for the same email or from the same IP lock login temporarily, with the lockout doubling each time.
+// @Description If two-factor authentication is enabled, responds 202 with a challenge token instead of tokens; finish with /login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param body body LoginRequest true "Login payload"
 // @Success 200 {object} LoginResponse
+// @Success 202 {object} LoginChallengeResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse "Too many failed attempts; see the Retry-After header"
 // @Failure 500 {object} ErrorResponse
 // @Router /login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -108,31 +115,259 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.query.GetUserByEmail(c, req.Email)
+	user, err := h.cmd.CheckCredentials(c, req.Email, req.Password, c.ClientIP())
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid credentials"})
+		var locked *entities.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(int(locked.RetryAfter.Seconds())))
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, entities.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to check credentials"})
+		}
+		return
+	}
+
+	pair, challenge, err := h.cmd.BeginLogin(c, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate token"})
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusAccepted, LoginChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challenge.Token,
+			ExpiresIn:         challenge.ExpiresAt.Unix(),
+		})
 		return
 	}
 
-	if !auth.CheckPasswordHash(user.Password, req.Password) {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "invalid credentials"})
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// LoginTwoFactor godoc
+// @Summary Second login step: exchange the challenge token and a two-factor code for tokens
+// @Description The code is a TOTP from the authenticator app or one of the recovery codes.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body LoginTwoFactorRequest true "Challenge and code"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var req LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: challenge_token and code are required"})
 		return
 	}
 
-	token, err := auth.GenerateToken(
-		h.cfg.JWTSecret,
-		h.cfg.JWTTTLMinutes,
-		user.ID,
-		string(user.Role),
-	)
+	pair, err := h.cmd.CompleteLogin(c, req.ChallengeToken, req.Code)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to generate token"})
+		if errors.Is(err, entities.ErrTwoFactorNotEnabled) {
+			// фактор отключили, пока шёл логин: challenge больше не действует
+			err = entities.ErrInvalidLoginChallenge
+		}
+		twoFactorError(c, err, "failed to generate token")
 		return
 	}
 
-	// Возвращаем токен + expiresIn (unix time)
-	c.JSON(http.StatusOK, gin.H{
-		"token":     token,
-		"expiresIn": time.Now().Add(time.Duration(h.cfg.JWTTTLMinutes) * time.Minute).Unix(),
-	})
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// Refresh godoc
+// @Summary Exchange a refresh token for a new token pair
+// @Description The refresh token is single-use: a new one is returned every time. Presenting an already used token revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body RefreshRequest true "Refresh token"
+// @Success 200 {object} LoginResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /token/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: refresh_token is required"})
+		return
+	}
+
+	pair, err := h.cmd.RefreshTokens(c, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidRefreshToken) || errors.Is(err, entities.ErrRefreshTokenReused) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to refresh token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(pair))
+}
+
+// Logout godoc
+// @Summary Logout: revoke the current access token and its refresh token session
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body LogoutRequest false "Refresh token of the session"
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req LogoutRequest
+	// тело необязательно
+	_ = c.ShouldBindJSON(&req)
+
+	expiresAt, _ := c.Get("tokenExpiresAt")
+	accessExpiresAt, _ := expiresAt.(time.Time)
+
+	if err := h.cmd.RevokeTokens(c, c.GetInt64("userID"), c.GetString("jti"), accessExpiresAt, req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "logged out"})
+}
+
+// RequestEmailVerification godoc
+// @Summary Send the email verification link again
+// @Description Previously sent links stop working. Trading is not allowed until the email is verified.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} MessageResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /email/verification [post]
+func (h *AuthHandler) RequestEmailVerification(c *gin.Context) {
+	if err := h.cmd.SendVerificationEmail(c, c.GetInt64("userID")); err != nil {
+		if errors.Is(err, entities.ErrEmailAlreadyVerified) {
+			c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to send verification email"})
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "verification email sent"})
+}
+
+// VerifyEmail godoc
+// @Summary Confirm the email address with the token from the verification link
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body EmailTokenRequest true "Token from the link"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /email/verify [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: token is required"})
+		return
+	}
+
+	if err := h.cmd.VerifyEmail(c, req.Token); err != nil {
+		if errors.Is(err, entities.ErrInvalidEmailToken) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to verify email"})
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "email verified"})
+}
+
+// ForgotPassword godoc
+// @Summary Request a password reset link
+// @Description Always responds 202, whether or not the email is registered.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ForgotPasswordRequest true "Account email"
+// @Success 202 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Router /password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: email is required"})
+		return
+	}
+
+	h.cmd.RequestPasswordReset(c, req.Email)
+	c.JSON(http.StatusAccepted, MessageResponse{Message: "if the account exists, a reset link has been sent"})
+}
+
+// ResetPassword godoc
+// @Summary Set a new password with the token from the reset link
+// @Description All sessions of the user are revoked.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param body body ResetPasswordRequest true "Token and new password"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /password/reset [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: token and password are required"})
+		return
+	}
+	if len(req.Password) < 6 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: password must be at least 6 characters"})
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to hash password"})
+		return
+	}
+
+	if err := h.cmd.ResetPassword(c, req.Token, hashedPassword); err != nil {
+		if errors.Is(err, entities.ErrInvalidEmailToken) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to reset password"})
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "password updated, please log in again"})
+}
+
+// JWKS godoc
+// @Summary Public keys for verifying access tokens
+// @Description JSON Web Key Set (RFC 7517). Tokens carry the key id in the kid header. Empty when tokens are signed with HS256.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} auth.JWKS
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	// клиенты кэшируют ключи; короткий срок, чтобы ротация подхватывалась быстро
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
+
+func tokenResponse(pair *entities.TokenPair) LoginResponse {
+	return LoginResponse{
+		Token:            pair.AccessToken,
+		ExpiresIn:        pair.AccessExpiresAt.Unix(),
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresIn: pair.RefreshExpiresAt.Unix(),
+	}
 }
@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/Skapar/backend/internal/cqrs"
 	"github.com/Skapar/backend/internal/models/entities"
@@ -37,19 +36,8 @@ func (h *HistoryHandler) AddHistory(c *gin.Context) {
 		return
 	}
 
-	uid, _ := c.Get("userID")
-	tokenUserID := uid.(int64)
-	role, _ := c.Get("role")
-	tokenUserRole := role.(string)
-
-	if tokenUserRole != "ADMIN" {
-		rec.UserID = tokenUserID
-	} else {
-		// admin если не указал user_id — записываем на себя
-		if rec.UserID == 0 {
-			rec.UserID = tokenUserID
-		}
-	}
+	// чужой user_id — только с history:write:any, иначе запись на себя
+	rec.UserID = targetUserID(c, rec.UserID, entities.PermHistoryWriteAny)
 
 	id, err := h.cmd.AddHistoryRecord(c, &rec)
 	if err != nil {
@@ -61,11 +49,11 @@ func (h *HistoryHandler) AddHistory(c *gin.Context) {
 }
 
 // GetHistoryByUser godoc
-// @Summary Get history (admin can pass user_id, trader gets own)
+// @Summary Get history (history:read:any can pass user_id, others get own)
 // @Tags history
 // @Security BearerAuth
 // @Produce json
-// @Param user_id path int false "User ID (admin only)"
+// @Param user_id path int false "User ID (requires history:read:any)"
 // @Success 200 {array} entities.History
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -73,26 +61,10 @@ func (h *HistoryHandler) AddHistory(c *gin.Context) {
 // @Router /history/user/{user_id} [get]
 // @Router /history/me [get]
 func (h *HistoryHandler) GetHistoryByUser(c *gin.Context) {
-	uid, _ := c.Get("userID")
-	tokenUserID := uid.(int64)
-	role, _ := c.Get("role")
-	tokenUserRole := role.(string)
-
-	var userID int64
-	if tokenUserRole == "ADMIN" {
-		userIDStr := c.Param("user_id")
-		if userIDStr != "" {
-			var err error
-			userID, err = strconv.ParseInt(userIDStr, 10, 64)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
-				return
-			}
-		} else {
-			userID = tokenUserID
-		}
-	} else {
-		userID = tokenUserID
+	userID, err := scopedUserID(c, entities.PermHistoryReadAny)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
+		return
 	}
 
 	history, err := h.query.GetHistoryByUserID(c, userID)
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// maxInviteTTLHours — дольше месяца приглашение не живёт.
+const maxInviteTTLHours = 30 * 24
+
+type InviteHandler struct {
+	cmd   cqrs.Command
+	query cqrs.Query
+}
+
+func NewInviteHandler(cmd cqrs.Command, query cqrs.Query) *InviteHandler {
+	return &InviteHandler{cmd: cmd, query: query}
+}
+
+// CreateInvite godoc
+// @Summary Create an invite code for a role (users:admin)
+// @Description The code is returned only once; the new user passes it as invite_code to /register. With email set, the code is also emailed and works only for that address.
+// @Tags invites
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-TOTP-Code header string false "TOTP or recovery code, required when two-factor is enabled"
+// @Param body body CreateInviteRequest true "Invite parameters"
+// @Success 201 {object} CreateInviteResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invites [post]
+func (h *InviteHandler) CreateInvite(c *gin.Context) {
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
+		return
+	}
+	if req.Role == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: role is required"})
+		return
+	}
+	if req.Email != "" && !entities.ValidEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + entities.ErrInvalidEmail.Error()})
+		return
+	}
+	if req.TTLHours < 0 || req.TTLHours > maxInviteTTLHours {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: ttl_hours must be between 0 (default) and " + strconv.Itoa(maxInviteTTLHours)})
+		return
+	}
+
+	createdBy := c.GetInt64("userID")
+	inv := &entities.Invite{
+		Role:      entities.Role(strings.ToUpper(req.Role)),
+		Email:     req.Email,
+		CreatedBy: &createdBy,
+	}
+
+	code, err := h.cmd.CreateInvite(c, inv, time.Duration(req.TTLHours)*time.Hour)
+	if err != nil {
+		if errors.Is(err, entities.ErrUnknownRole) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create invite: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateInviteResponse{Code: code, Invite: inv})
+}
+
+// ListInvites godoc
+// @Summary List invites, newest first (users:admin)
+// @Tags invites
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.Invite
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invites [get]
+func (h *InviteHandler) ListInvites(c *gin.Context) {
+	invites, err := h.query.ListInvites(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to list invites: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, invites)
+}
+
+// RevokeInvite godoc
+// @Summary Revoke an unused invite (users:admin)
+// @Tags invites
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Invite ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /invites/{id} [delete]
+func (h *InviteHandler) RevokeInvite(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid invite id"})
+		return
+	}
+
+	if err := h.cmd.RevokeInvite(c, id); err != nil {
+		if errors.Is(err, entities.ErrInviteNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to revoke invite: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "invite revoked"})
+}
@@ -1,12 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/Skapar/backend/internal/cqrs"
 	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 type OrderHandler struct {
@@ -40,33 +44,57 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "type must be BUY or SELL"})
 		return
 	}
+	if !req.Quantity.IsPositive() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "quantity must be positive"})
+		return
+	}
 
-	uid, ok := c.Get("userID")
-	if !ok {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "missing user_id"})
+	kind := entities.OrderKind(strings.ToUpper(req.Kind))
+	if kind == "" {
+		kind = entities.OrderMarket
+	}
+	if msg := validateOrderKind(kind, &req); msg != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: msg})
 		return
 	}
-	tokenUserID := uid.(int64)
 
-	roleVal, ok := c.Get("role")
+	tif := entities.TimeInForce(strings.ToUpper(req.TimeInForce))
+	if tif == "" {
+		tif = entities.TimeInForceGTC
+	}
+	if !tif.Valid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "time_in_force must be GTC, DAY, IOC, FOK or GTD"})
+		return
+	}
+	if tif == entities.TimeInForceGTD {
+		if req.ExpireAt == nil || !req.ExpireAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "expire_at must be in the future for GTD orders"})
+			return
+		}
+	} else {
+		req.ExpireAt = nil
+	}
+
+	uid, ok := c.Get("userID")
 	if !ok {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "missing role"})
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "missing user_id"})
 		return
 	}
-	tokenUserRole := roleVal.(string)
+	tokenUserID := uid.(int64)
 
 	order := entities.Order{
-		UserID:    tokenUserID,
-		StockID:   req.StockID,
-		Quantity:  float64(req.Quantity),
-		OrderType: entities.OrderType(req.Type),
-		Status:    entities.OrderStatus("PENDING"),
-	}
+		UserID:     tokenUserID,
+		StockID:    req.StockID,
+		Quantity:   req.Quantity,
+		OrderType:  entities.OrderType(req.Type),
+		Kind:       kind,
+		LimitPrice: req.LimitPrice,
+		Status:     entities.OrderNew,
 
-	// admin может создавать на другого пользователя — если вам надо, добавим позже.
-	// сейчас оставим как было: не admin -> всегда свой user_id
-	if tokenUserRole == "ADMIN" {
-		// оставляем UserID = tokenUserID
+		TriggerPrice:   req.TriggerPrice,
+		TrailingOffset: req.TrailingOffset,
+		TimeInForce:    tif,
+		ExpiresAt:      req.ExpireAt,
 	}
 
 	stock, err := h.query.GetStockByID(c, order.StockID)
@@ -75,28 +103,37 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 		return
 	}
 
-	order.Price = stock.Price * float64(order.Quantity)
+	order.Price = stock.Price.Mul(order.Quantity)
+	if order.LimitPrice != nil {
+		order.Price = order.LimitPrice.Mul(order.Quantity)
+	}
 
 	id, err := h.cmd.CreateOrder(c, &order)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to create order: " + err.Error()})
+		c.JSON(orderErrorStatus(err), ErrorResponse{Error: "failed to create order: " + err.Error()})
 		return
 	}
 
 	order.ID = id
 	if err := h.cmd.ExecuteOrder(c, &order); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to execute order: " + err.Error()})
+		c.JSON(orderErrorStatus(err), ErrorResponse{Error: "failed to execute order: " + err.Error()})
 		return
 	}
 
+	message := "order executed successfully"
+	if order.Status.IsOpen() {
+		message = "order placed"
+	}
+
 	c.JSON(http.StatusCreated, OrderCreatedResponse{
-		Message: "order executed successfully",
+		Message: message,
 		OrderID: id,
+		Status:  string(order.Status),
 	})
 }
 
 // UpdateOrderStatus godoc
-// @Summary Update order status (admin or owner)
+// @Summary Update order status (owner may only cancel; orders:write:any allows any order and status)
 // @Tags orders
 // @Security BearerAuth
 // @Accept json
@@ -108,6 +145,7 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 // @Failure 401 {object} ErrorResponse
 // @Failure 403 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /orders/{id}/status [put]
 func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
@@ -128,49 +166,81 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 		return
 	}
 
-	uid, ok := c.Get("userID")
-	if !ok {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "missing user_id"})
+	if !canAccess(c, order.UserID, entities.PermOrdersWriteAny) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "access denied"})
 		return
 	}
-	tokenUserID := uid.(int64)
 
-	roleVal, ok := c.Get("role")
-	if !ok {
-		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: "missing role"})
+	var body UpdateOrderStatusRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
 		return
 	}
-	tokenUserRole := roleVal.(string)
-
-	if tokenUserRole != "ADMIN" && tokenUserID != order.UserID {
+	status := entities.OrderStatus(strings.ToUpper(body.Status))
+	if !status.Valid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: unknown status"})
+		return
+	}
+	// без orders:write:any заявку можно только отменить
+	if !permissions(c).Has(entities.PermOrdersWriteAny) && status != entities.OrderCancelled {
 		c.JSON(http.StatusForbidden, ErrorResponse{Error: "access denied"})
 		return
 	}
 
-	var body UpdateOrderStatusRequest
-	if err := c.ShouldBindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
+	if err := h.cmd.UpdateOrderStatus(c, orderID, status); err != nil {
+		c.JSON(orderErrorStatus(err), ErrorResponse{Error: "failed to update status: " + err.Error()})
 		return
 	}
-	if body.Status == "" {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: status is required"})
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "order status updated"})
+}
+
+// CancelOrder godoc
+// @Summary Cancel order (owner or orders:write:any)
+// @Tags orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Order ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /orders/{id}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orderID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid order ID"})
+		return
+	}
+
+	order, err := h.query.GetOrderByID(c, orderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, ErrorResponse{Error: "order not found"})
 		return
 	}
 
-	if err := h.cmd.UpdateOrderStatus(c, orderID, entities.OrderStatus(body.Status)); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update status: " + err.Error()})
+	if !canAccess(c, order.UserID, entities.PermOrdersWriteAny) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Error: "access denied"})
 		return
 	}
 
-	c.JSON(http.StatusOK, MessageResponse{Message: "order status updated"})
+	if err := h.cmd.CancelOrder(c, orderID); err != nil {
+		c.JSON(orderErrorStatus(err), ErrorResponse{Error: "failed to cancel order: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "order cancelled"})
 }
 
 // GetOrdersByUser godoc
-// @Summary Get orders (admin can pass user_id, trader gets own)
+// @Summary Get orders (orders:read:any can pass user_id, others get own)
 // @Tags orders
 // @Security BearerAuth
 // @Produce json
-// @Param user_id path int false "User ID (admin only)"
+// @Param user_id path int false "User ID (requires orders:read:any)"
 // @Success 200 {array} entities.Order
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -178,26 +248,10 @@ func (h *OrderHandler) UpdateOrderStatus(c *gin.Context) {
 // @Router /orders/user/{user_id} [get]
 // @Router /orders/me [get]
 func (h *OrderHandler) GetOrdersByUser(c *gin.Context) {
-	uid, _ := c.Get("userID")
-	tokenUserID := uid.(int64)
-	role, _ := c.Get("role")
-	tokenUserRole := role.(string)
-
-	var userID int64
-	if tokenUserRole == "ADMIN" {
-		userIDStr := c.Param("user_id")
-		if userIDStr != "" {
-			var err error
-			userID, err = strconv.ParseInt(userIDStr, 10, 64)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
-				return
-			}
-		} else {
-			userID = tokenUserID
-		}
-	} else {
-		userID = tokenUserID
+	userID, err := scopedUserID(c, entities.PermOrdersReadAny)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
+		return
 	}
 
 	orders, err := h.query.GetOrdersByUserID(c, userID)
@@ -208,3 +262,54 @@ func (h *OrderHandler) GetOrdersByUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, orders)
 }
+
+// orderErrorStatus мапит доменные ошибки заявок в HTTP-статус.
+func orderErrorStatus(err error) int {
+	var transitionErr *entities.OrderTransitionError
+	switch {
+	case errors.As(err, &transitionErr):
+		return http.StatusConflict
+	case errors.Is(err, entities.ErrEmailNotVerified):
+		return http.StatusForbidden
+	case errors.Is(err, entities.ErrInsufficientFunds), errors.Is(err, entities.ErrInsufficientShares),
+		errors.Is(err, entities.ErrInvalidTickSize), errors.Is(err, entities.ErrInvalidLotSize):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// validateOrderKind проверяет цены, обязательные для kind, и обнуляет лишние.
+// Возвращает текст ошибки или пустую строку.
+func validateOrderKind(kind entities.OrderKind, req *CreateOrderRequest) string {
+	positive := func(v *decimal.Decimal) bool { return v != nil && v.IsPositive() }
+
+	switch kind {
+	case entities.OrderMarket:
+		req.LimitPrice, req.TriggerPrice, req.TrailingOffset = nil, nil, nil
+	case entities.OrderLimit:
+		if !positive(req.LimitPrice) {
+			return "limit_price must be positive for LIMIT orders"
+		}
+		req.TriggerPrice, req.TrailingOffset = nil, nil
+	case entities.OrderStop:
+		if !positive(req.TriggerPrice) {
+			return "trigger_price must be positive for STOP orders"
+		}
+		req.LimitPrice, req.TrailingOffset = nil, nil
+	case entities.OrderStopLimit:
+		if !positive(req.TriggerPrice) || !positive(req.LimitPrice) {
+			return "trigger_price and limit_price must be positive for STOP_LIMIT orders"
+		}
+		req.TrailingOffset = nil
+	case entities.OrderTrailingStop:
+		if !positive(req.TrailingOffset) {
+			return "trailing_offset must be positive for TRAILING_STOP orders"
+		}
+		// триггер считается от текущей цены при размещении
+		req.LimitPrice, req.TriggerPrice = nil, nil
+	default:
+		return "kind must be MARKET, LIMIT, STOP, STOP_LIMIT or TRAILING_STOP"
+	}
+	return ""
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,11 +20,11 @@ func NewPortfolioHandler(cmd cqrs.Command, query cqrs.Query) *PortfolioHandler {
 }
 
 // GetPortfolio godoc
-// @Summary Get portfolio record (admin can specify user_id, trader gets own)
+// @Summary Get portfolio record (portfolio:read:any can specify user_id, others get own)
 // @Tags portfolio
 // @Security BearerAuth
 // @Produce json
-// @Param user_id path int false "User ID (admin only)"
+// @Param user_id path int false "User ID (requires portfolio:read:any)"
 // @Param stock_id path int true "Stock ID"
 // @Success 200 {object} entities.Portfolio
 // @Failure 400 {object} ErrorResponse
@@ -38,25 +39,10 @@ func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
 		return
 	}
 
-	uid, _ := c.Get("userID")
-	tokenUserID := uid.(int64)
-	role, _ := c.Get("role")
-	tokenUserRole := role.(string)
-
-	var userID int64
-	if tokenUserRole == "ADMIN" {
-		userIDStr := c.Param("user_id")
-		if userIDStr != "" {
-			userID, err = strconv.ParseInt(userIDStr, 10, 64)
-			if err != nil {
-				c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
-				return
-			}
-		} else {
-			userID = tokenUserID
-		}
-	} else {
-		userID = tokenUserID
+	userID, err := scopedUserID(c, entities.PermPortfolioReadAny)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user ID"})
+		return
 	}
 
 	p, err := h.query.GetPortfolio(c, userID, stockID)
@@ -75,7 +61,7 @@ func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
 }
 
 // CreateOrUpdatePortfolio godoc
-// @Summary Create or update portfolio
+// @Summary Adjust a position directly (requires portfolio:write:any)
 // @Tags portfolio
 // @Security BearerAuth
 // @Accept json
@@ -84,6 +70,7 @@ func (h *PortfolioHandler) GetPortfolio(c *gin.Context) {
 // @Success 200 {object} MessageResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /portfolio/ [post]
 func (h *PortfolioHandler) CreateOrUpdatePortfolio(c *gin.Context) {
@@ -94,24 +81,13 @@ func (h *PortfolioHandler) CreateOrUpdatePortfolio(c *gin.Context) {
 		return
 	}
 
-	if body.StockID == 0 || body.Quantity == 0 {
+	if body.StockID == 0 || body.Quantity.IsZero() {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: stock_id and quantity are required"})
 		return
 	}
 
-	uid, _ := c.Get("userID")
-	tokenUserID := uid.(int64)
-	role, _ := c.Get("role")
-	tokenUserRole := role.(string)
-
-	if tokenUserRole != "ADMIN" {
-		body.UserID = tokenUserID
-	} else {
-		// admin должен явно указать user_id, иначе пусть по умолчанию будет он сам
-		if body.UserID == 0 {
-			body.UserID = tokenUserID
-		}
-	}
+	// если user_id не указан — позиция самого пользователя
+	body.UserID = targetUserID(c, body.UserID, entities.PermPortfolioWriteAny)
 
 	if err := h.cmd.CreateOrUpdatePortfolio(c, &entities.Portfolio{
 		UserID:   body.UserID,
@@ -126,11 +102,11 @@ func (h *PortfolioHandler) CreateOrUpdatePortfolio(c *gin.Context) {
 }
 
 // GetMyPortfolio godoc
-// @Summary Get my portfolio
+// @Summary Get my portfolio with cost basis and unrealized P&L
 // @Tags portfolio
 // @Security BearerAuth
 // @Produce json
-// @Success 200 {array} entities.Portfolio
+// @Success 200 {object} entities.PortfolioValuation
 // @Failure 401 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /portfolio/me [get]
@@ -138,11 +114,45 @@ func (h *PortfolioHandler) GetMyPortfolio(c *gin.Context) {
 	uid, _ := c.Get("userID")
 	tokenUserID := uid.(int64)
 
-	portfolios, err := h.query.GetPortfoliosByUserID(c, tokenUserID)
+	valuation, err := h.query.GetPortfolioValuation(c, tokenUserID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch portfolio: " + err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, portfolios)
+	c.JSON(http.StatusOK, valuation)
+}
+
+// SetCostBasisMethod godoc
+// @Summary Choose how sold shares are matched against tax lots
+// @Tags portfolio
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body CostBasisMethodRequest true "FIFO, LIFO or AVERAGE"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /portfolio/cost-basis [put]
+func (h *PortfolioHandler) SetCostBasisMethod(c *gin.Context) {
+	var body CostBasisMethodRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid JSON: " + err.Error()})
+		return
+	}
+
+	uid, _ := c.Get("userID")
+	tokenUserID := uid.(int64)
+
+	if err := h.cmd.SetCostBasisMethod(c, tokenUserID, entities.CostBasisMethod(body.Method)); err != nil {
+		if errors.Is(err, entities.ErrInvalidCostBasisMethod) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update cost basis method: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "cost basis method updated"})
 }
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+var roleNameRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]{0,49}$`)
+
+type RoleHandler struct {
+	cmd   cqrs.Command
+	query cqrs.Query
+}
+
+func NewRoleHandler(cmd cqrs.Command, query cqrs.Query) *RoleHandler {
+	return &RoleHandler{cmd: cmd, query: query}
+}
+
+// ListRoles godoc
+// @Summary List roles with their permissions
+// @Tags roles
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.RoleInfo
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.query.ListRoles(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get roles: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// ListPermissions godoc
+// @Summary List all permissions that can be granted to roles
+// @Tags roles
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} entities.PermissionInfo
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /permissions [get]
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	perms, err := h.query.ListPermissions(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get permissions: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, perms)
+}
+
+// SetRole godoc
+// @Summary Create a role or replace its permissions
+// @Description The permission list replaces the current one. Other instances pick up the change within PERMISSION_CACHE_SECONDS.
+// @Tags roles
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param name path string true "Role name, e.g. SUPPORT"
+// @Param body body SetRoleRequest true "Role description and permissions"
+// @Success 200 {object} entities.RoleInfo
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /roles/{name} [put]
+func (h *RoleHandler) SetRole(c *gin.Context) {
+	name := strings.ToUpper(c.Param("name"))
+	if !roleNameRe.MatchString(name) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid role name: use A-Z, 0-9 and _, up to 50 characters"})
+		return
+	}
+
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
+		return
+	}
+
+	role := &entities.RoleInfo{
+		Name:        entities.Role(name),
+		Description: req.Description,
+		Permissions: make([]entities.Permission, 0, len(req.Permissions)),
+	}
+	for _, p := range req.Permissions {
+		role.Permissions = append(role.Permissions, entities.Permission(p))
+	}
+
+	// админ не может отнять у собственной роли управление ролями
+	if role.Name == entities.Role(c.GetString("role")) && !hasPermission(role.Permissions, entities.PermRolesAdmin) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "cannot remove roles:admin from your own role"})
+		return
+	}
+
+	if err := h.cmd.SetRolePermissions(c, role); err != nil {
+		if errors.Is(err, entities.ErrUnknownPermission) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to update role: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func hasPermission(perms []entities.Permission, p entities.Permission) bool {
+	for _, v := range perms {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -26,7 +27,7 @@ func NewStockHandler(cmd cqrs.Command, query cqrs.Query, log logger.Logger) *Sto
 }
 
 // CreateStock godoc
-// @Summary Create stock (admin)
+// @Summary Create stock (stocks:write)
 // @Tags stocks
 // @Security BearerAuth
 // @Accept json
@@ -45,7 +46,7 @@ func (h *StockHandler) CreateStock(c *gin.Context) {
 		return
 	}
 
-	if input.Symbol == "" || input.Name == "" || input.Price <= 0 {
+	if input.Symbol == "" || input.Name == "" || !input.Price.IsPositive() {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "symbol, name and positive price are required"})
 		return
 	}
@@ -117,14 +118,78 @@ func (h *StockHandler) GetAllStocks(c *gin.Context) {
 	c.JSON(http.StatusOK, stocks)
 }
 
+// maxCandles ограничивает число баров в одном ответе.
+const maxCandles = 5000
+
+// GetCandles godoc
+// @Summary Get OHLCV candles for a stock
+// @Tags stocks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Stock ID"
+// @Param interval query string false "Bar width: 1m, 5m, 1h or 1d" default(1m)
+// @Param from query string false "RFC3339 start (default: 100 bars before to)"
+// @Param to query string false "RFC3339 end, exclusive (default: now)"
+// @Success 200 {array} entities.Candle
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /stocks/{id}/candles [get]
+func (h *StockHandler) GetCandles(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid stock ID"})
+		return
+	}
+
+	interval := entities.CandleInterval(c.DefaultQuery("interval", string(entities.Candle1m)))
+	width := interval.Duration()
+	if width == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: entities.ErrInvalidCandleInterval.Error()})
+		return
+	}
+
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid to: expected RFC3339"})
+			return
+		}
+	}
+	from := to.Add(-100 * width)
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid from: expected RFC3339"})
+			return
+		}
+	}
+	if to.Sub(from) > maxCandles*width {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "range too large: narrow from/to or use a wider interval"})
+		return
+	}
+
+	candles, err := h.query.GetCandles(c, id, interval, from, to)
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidCandleRange) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		h.log.Errorf("GetCandles error: %v", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to fetch candles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, candles)
+}
+
 // UpdateStock godoc
-// @Summary Update stock (admin)
+// @Summary Update stock (stocks:write)
 // @Tags stocks
 // @Security BearerAuth
 // @Accept json
 // @Produce json
 // @Param id path int true "Stock ID"
-// @Param body body object true "Partial update payload (symbol/name/price)"
+// @Param body body UpdateStockRequest true "Partial update payload"
 // @Success 200 {object} MessageResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -146,24 +211,38 @@ func (h *StockHandler) UpdateStock(c *gin.Context) {
 		return
 	}
 
-	var input map[string]interface{}
+	var input UpdateStockRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid JSON: " + err.Error()})
 		return
 	}
 
-	if symbol, ok := input["symbol"].(string); ok {
-		existing.Symbol = symbol
+	if input.Symbol != nil {
+		existing.Symbol = *input.Symbol
 	}
-	if name, ok := input["name"].(string); ok {
-		existing.Name = name
+	if input.Name != nil {
+		existing.Name = *input.Name
 	}
-	if price, ok := input["price"].(float64); ok {
-		if price <= 0 {
+	if input.Price != nil {
+		if !input.Price.IsPositive() {
 			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "price must be positive"})
 			return
 		}
-		existing.Price = price
+		existing.Price = *input.Price
+	}
+	if input.TickSize != nil {
+		if input.TickSize.IsNegative() {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "tick_size must not be negative"})
+			return
+		}
+		existing.TickSize = *input.TickSize
+	}
+	if input.LotSize != nil {
+		if input.LotSize.IsNegative() {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "lot_size must not be negative"})
+			return
+		}
+		existing.LotSize = *input.LotSize
 	}
 
 	existing.UpdatedAt = time.Now()
@@ -178,7 +257,7 @@ func (h *StockHandler) UpdateStock(c *gin.Context) {
 }
 
 // DeleteStock godoc
-// @Summary Delete stock (admin)
+// @Summary Delete stock (stocks:write)
 // @Tags stocks
 // @Security BearerAuth
 // @Produce json
@@ -0,0 +1,330 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/stream"
+	"github.com/Skapar/backend/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = wsPongWait * 9 / 10
+	wsMaxMessage = 4096
+)
+
+type StreamHandler struct {
+	query    cqrs.Query
+	hub      *stream.Hub
+	log      logger.Logger
+	upgrader websocket.Upgrader
+}
+
+// NewStreamHandler принимает тот же список origin, что и CORS: браузер не применяет
+// CORS к WebSocket, поэтому проверяем сами.
+func NewStreamHandler(query cqrs.Query, hub *stream.Hub, allowedOrigins []string, log logger.Logger) *StreamHandler {
+	origins := make(map[string]struct{}, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		origins[o] = struct{}{}
+	}
+
+	return &StreamHandler{
+		query: query,
+		hub:   hub,
+		log:   log,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					return true // не браузер
+				}
+				_, ok := origins[origin]
+				return ok
+			},
+		},
+	}
+}
+
+// wsRequest — сообщение клиента: {"action": "subscribe", "symbols": ["AAPL"]}.
+type wsRequest struct {
+	Action  string   `json:"action"` // subscribe, unsubscribe
+	Symbols []string `json:"symbols"`
+}
+
+// wsReply — служебный ответ сервера; события идут как entities.StreamEvent.
+type wsReply struct {
+	Type     string   `json:"type"` // subscribed, error
+	Channels []string `json:"channels,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Connect godoc
+// @Summary WebSocket for price ticks and own order updates
+// @Description Upgrade to WebSocket. The token may be sent as Authorization header or access_token query param.
+// @Description The private channel user:{id} with order and fill events is joined automatically.
+// @Description Client messages: {"action":"subscribe"|"unsubscribe","symbols":["AAPL"]}. Server messages: StreamEvent or {"type":"subscribed"|"error"}.
+// @Tags stream
+// @Security BearerAuth
+// @Param access_token query string false "JWT, for clients that cannot set headers"
+// @Success 101 {object} entities.StreamEvent
+// @Failure 401 {object} ErrorResponse
+// @Router /ws [get]
+func (h *StreamHandler) Connect(c *gin.Context) {
+	uid, _ := c.Get("userID")
+	userID := uid.(int64)
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade уже ответил клиенту
+		h.log.Warnf("ws upgrade failed: %v", err)
+		return
+	}
+
+	sub := h.hub.Subscribe()
+	sub.Join(entities.UserChannel(userID))
+
+	replies := make(chan wsReply, 8)
+	done := make(chan struct{})
+
+	go h.writeLoop(conn, sub, replies, done)
+	h.readLoop(c, conn, sub, replies)
+
+	close(done)
+	sub.Close()
+}
+
+// readLoop разбирает команды клиента до разрыва соединения.
+func (h *StreamHandler) readLoop(c *gin.Context, conn *websocket.Conn, sub *stream.Subscription, replies chan<- wsReply) {
+	conn.SetReadLimit(wsMaxMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var req wsRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				h.log.Warnf("ws read: %v", err)
+			}
+			return
+		}
+
+		reply := h.apply(c, sub, req)
+		select {
+		case replies <- reply:
+		default:
+			// клиент шлёт команды быстрее, чем читает ответы
+		}
+	}
+}
+
+func (h *StreamHandler) apply(c *gin.Context, sub *stream.Subscription, req wsRequest) wsReply {
+	channels, err := h.priceChannels(c, req.Symbols)
+	if err != nil {
+		return wsReply{Type: "error", Error: err.Error()}
+	}
+
+	switch req.Action {
+	case "subscribe":
+		sub.Join(channels...)
+	case "unsubscribe":
+		sub.Leave(channels...)
+	default:
+		return wsReply{Type: "error", Error: "unknown action: " + req.Action}
+	}
+	return wsReply{Type: "subscribed", Channels: sub.Channels()}
+}
+
+// priceChannels проверяет, что тикеры существуют, и возвращает их каналы.
+func (h *StreamHandler) priceChannels(c *gin.Context, symbols []string) ([]string, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("symbols are required")
+	}
+
+	stocks, err := h.query.GetAllStocks(c)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]struct{}, len(stocks))
+	for _, st := range stocks {
+		known[strings.ToUpper(st.Symbol)] = struct{}{}
+	}
+
+	channels := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		sym = strings.ToUpper(strings.TrimSpace(sym))
+		if _, ok := known[sym]; !ok {
+			return nil, fmt.Errorf("unknown symbol %q", sym)
+		}
+		channels = append(channels, entities.PriceChannel(sym))
+	}
+	return channels, nil
+}
+
+// writeLoop — единственный писатель в соединение: события, ответы и ping.
+func (h *StreamHandler) writeLoop(conn *websocket.Conn, sub *stream.Subscription, replies <-chan wsReply, done <-chan struct{}) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = conn.Close()
+	}()
+
+	write := func(v interface{}) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(v) == nil
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// не успеваем доставлять — закрываем, клиент переподключится
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber too slow"),
+					time.Now().Add(wsWriteWait))
+				return
+			}
+			if !write(ev) {
+				return
+			}
+		case reply := <-replies:
+			if !write(reply) {
+				return
+			}
+		case <-ticker.C:
+			_ = conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sseHeartbeat — период комментариев-пингов, чтобы прокси не закрывали простаивающий поток.
+const sseHeartbeat = 15 * time.Second
+
+// Prices godoc
+// @Summary Server-Sent Events stream of price ticks
+// @Description Same events as the WebSocket price channels. Send Last-Event-ID (or last_event_id) to resume after a reconnect;
+// @Description events still in the short replay buffer are sent first. The token may be passed as access_token query param.
+// @Tags stream
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param symbols query string true "Comma-separated symbols, e.g. AAPL,MSFT"
+// @Param Last-Event-ID header string false "ID of the last event received"
+// @Param last_event_id query string false "Same as Last-Event-ID, for clients that cannot set headers"
+// @Param access_token query string false "JWT, for clients that cannot set headers"
+// @Success 200 {object} entities.StreamEvent
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /stream/prices [get]
+func (h *StreamHandler) Prices(c *gin.Context) {
+	var symbols []string
+	for _, s := range strings.Split(c.Query("symbols"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, s)
+		}
+	}
+	channels, err := h.priceChannels(c, symbols)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+
+	// подписываемся до чтения буфера, чтобы не потерять события между ними
+	sub := h.hub.Subscribe()
+	sub.Join(channels...)
+	defer sub.Close()
+
+	var backlog []*entities.StreamEvent
+	if lastID != "" {
+		if backlog, err = h.hub.Replay(c, lastID, channels); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+
+	// поток живёт дольше WriteTimeout сервера
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.log.Warnf("sse: reset write deadline: %v", err)
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, ev := range backlog {
+		if err := writeSSE(c.Writer, ev); err != nil {
+			return
+		}
+		lastID = ev.ID
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return // клиент переподключится с Last-Event-ID
+			}
+			// событие могло уже уйти из буфера докачки
+			if lastID != "" && ev.ID != "" && !stream.EventAfter(ev.ID, lastID) {
+				continue
+			}
+			if err := writeSSE(c.Writer, ev); err != nil {
+				return
+			}
+			if ev.ID != "" {
+				lastID = ev.ID
+			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+func writeSSE(w io.Writer, ev *entities.StreamEvent) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if ev.ID != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", ev.ID); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
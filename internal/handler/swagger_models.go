@@ -1,5 +1,12 @@
 package handler
 
+import (
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
 // =========================
 // Common responses
 // =========================
@@ -21,10 +28,11 @@ type IDResponse struct {
 // Auth
 // =========================
 
+// RegisterRequest — роль не выбирается: без приглашения создаётся TRADER, с ним — роль приглашения.
 type RegisterRequest struct {
-	Email    string `json:"email" example:"test@mail.com"`
-	Password string `json:"password" example:"123456"`
-	Role     string `json:"role" example:"TRADER"`
+	Email      string `json:"email" example:"test@mail.com"`
+	Password   string `json:"password" example:"123456"`
+	InviteCode string `json:"invite_code,omitempty" example:"inv_..."`
 }
 
 type RegisterResponse struct {
@@ -38,8 +46,45 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token     string `json:"token" example:"eyJhbGciOi..."`
-	ExpiresIn int64  `json:"expiresIn" example:"1730000000"`
+	Token            string `json:"token" example:"eyJhbGciOi..."`
+	ExpiresIn        int64  `json:"expiresIn" example:"1730000000"` // unix-время истечения access-токена
+	RefreshToken     string `json:"refresh_token" example:"3q2-7wEAAAB..."`
+	RefreshExpiresIn int64  `json:"refresh_expires_in" example:"1732592000"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" example:"3q2-7wEAAAB..."`
+}
+
+// LoginChallengeResponse — ответ на логин при включённом втором факторе: токены выдаст /login/2fa.
+type LoginChallengeResponse struct {
+	TwoFactorRequired bool   `json:"two_factor_required" example:"true"`
+	ChallengeToken    string `json:"challenge_token" example:"eyJhbGciOi..."`
+	ExpiresIn         int64  `json:"expiresIn" example:"1730000300"` // unix-время истечения challenge
+}
+
+// LoginTwoFactorRequest — code: TOTP из приложения или код восстановления.
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" example:"eyJhbGciOi..."`
+	Code           string `json:"code" example:"123456"`
+}
+
+// LogoutRequest — refresh_token необязателен: без него отзывается только текущий access-токен.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty" example:"3q2-7wEAAAB..."`
+}
+
+type EmailTokenRequest struct {
+	Token string `json:"token" example:"eyJhbGciOi..."`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" example:"test@mail.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" example:"eyJhbGciOi..."`
+	Password string `json:"password" example:"newpass123"`
 }
 
 // =========================
@@ -47,15 +92,103 @@ type LoginResponse struct {
 // =========================
 
 type GetMeResponse struct {
-	Email   string  `json:"email" example:"test@mail.com"`
-	Balance float64 `json:"balance" example:"1000"`
+	Email     string          `json:"email" example:"test@mail.com"`
+	Balance   decimal.Decimal `json:"balance" example:"1000"`  // всего на счёте
+	Reserved  decimal.Decimal `json:"reserved" example:"250"`  // под открытыми заявками
+	Available decimal.Decimal `json:"available" example:"750"` // balance - reserved
+	// без подтверждённого email торговать нельзя
+	EmailVerified bool `json:"email_verified" example:"true"`
 }
 
 type UpdateUserRequest struct {
-	Email    string  `json:"email" example:"new@mail.com"`
-	Password string  `json:"password" example:"newpass123"`
-	Role     string  `json:"role" example:"ADMIN"`
-	Balance  float64 `json:"balance" example:"5000"`
+	Email    string           `json:"email" example:"new@mail.com"`
+	Password string           `json:"password" example:"newpass123"`
+	Role     string           `json:"role" example:"ADMIN"`
+	Balance  *decimal.Decimal `json:"balance,omitempty" example:"5000"`
+}
+
+type ProvisionUserRequest struct {
+	Email    string `json:"email" example:"support@mail.com"`
+	Password string `json:"password" example:"123456"`
+	Role     string `json:"role" example:"SUPPORT"`
+}
+
+type CreateInviteRequest struct {
+	Role     string `json:"role" example:"ADMIN"`
+	Email    string `json:"email,omitempty" example:"new-admin@mail.com"` // если задан, код отправляется письмом и подходит только для этого адреса
+	TTLHours int    `json:"ttl_hours,omitempty" example:"72"`
+}
+
+// CreateInviteResponse — код отдаётся только здесь.
+type CreateInviteResponse struct {
+	Code   string           `json:"code" example:"inv_..."`
+	Invite *entities.Invite `json:"invite"`
+}
+
+// =========================
+// Roles
+// =========================
+
+// SetRoleRequest — полный набор прав роли; отсутствующие в списке права у роли снимаются.
+type SetRoleRequest struct {
+	Description string   `json:"description" example:"Read-only access to customer data"`
+	Permissions []string `json:"permissions" example:"users:read:any,orders:read:any"`
+}
+
+// =========================
+// API keys
+// =========================
+
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" example:"market-maker-bot"`
+	Scopes     []string   `json:"scopes" example:"read,trade"`                 // read, trade, withdraw
+	AllowedIPs []string   `json:"allowed_ips,omitempty" example:"203.0.113.7"` // IP или CIDR
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" example:"2026-12-31T00:00:00Z"`
+}
+
+// CreateAPIKeyResponse — ключ отдаётся только здесь, сохранить его нужно сразу.
+type CreateAPIKeyResponse struct {
+	Key    string           `json:"key" example:"sk_1a2b3c4d5e6f_..."`
+	APIKey *entities.APIKey `json:"api_key"`
+}
+
+// =========================
+// Two-factor
+// =========================
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// RecoveryCodesResponse — коды показываются один раз, каждый действует однократно.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes" example:"abcde-fghij,klmno-pqrst"`
+}
+
+// =========================
+// Account
+// =========================
+
+type FundsRequest struct {
+	Amount decimal.Decimal `json:"amount" example:"1000"`
+}
+
+type FundsResponse struct {
+	JournalID int64           `json:"journal_id" example:"42"`
+	Balance   decimal.Decimal `json:"balance" example:"6000"`
+}
+
+// =========================
+// Stocks
+// =========================
+
+// UpdateStockRequest — частичное обновление: незаданные поля не меняются.
+type UpdateStockRequest struct {
+	Symbol   *string          `json:"symbol,omitempty" example:"AAPL"`
+	Name     *string          `json:"name,omitempty" example:"Apple Inc."`
+	Price    *decimal.Decimal `json:"price,omitempty" example:"187.25"`
+	TickSize *decimal.Decimal `json:"tick_size,omitempty" example:"0.01"`
+	LotSize  *decimal.Decimal `json:"lot_size,omitempty" example:"1"`
 }
 
 // =========================
@@ -63,12 +196,13 @@ type UpdateUserRequest struct {
 // =========================
 
 type UpdateOrderStatusRequest struct {
-	Status string `json:"status" example:"COMPLETED"`
+	Status string `json:"status" example:"CANCELLED"`
 }
 
 type OrderCreatedResponse struct {
 	Message string `json:"message" example:"order executed successfully"`
 	OrderID int64  `json:"order_id" example:"123"`
+	Status  string `json:"status" example:"FILLED"`
 }
 
 // =========================
@@ -76,9 +210,13 @@ type OrderCreatedResponse struct {
 // =========================
 
 type CreateOrUpdatePortfolioRequest struct {
-	UserID   int64   `json:"user_id" example:"1"`
-	StockID  int64   `json:"stock_id" example:"10"`
-	Quantity float64 `json:"quantity" example:"2"`
+	UserID   int64           `json:"user_id" example:"1"`
+	StockID  int64           `json:"stock_id" example:"10"`
+	Quantity decimal.Decimal `json:"quantity" example:"2"`
+}
+
+type CostBasisMethodRequest struct {
+	Method string `json:"method" example:"FIFO"` // FIFO, LIFO или AVERAGE
 }
 
 // =========================
@@ -89,7 +227,24 @@ type HistoryCreatedResponse struct {
 	HistoryID int64 `json:"history_id" example:"55"`
 }
 type CreateOrderRequest struct {
-	StockID  int64  `json:"stock_id" example:"4"`
-	Quantity int    `json:"quantity" example:"2"`
-	Type     string `json:"type" example:"BUY"` // BUY или SELL
+	StockID    int64            `json:"stock_id" example:"4"`
+	Quantity   decimal.Decimal  `json:"quantity" example:"2"`
+	Type       string           `json:"type" example:"BUY"`                    // BUY или SELL
+	Kind       string           `json:"kind,omitempty" example:"LIMIT"`        // MARKET (по умолчанию), LIMIT, STOP, STOP_LIMIT, TRAILING_STOP
+	LimitPrice *decimal.Decimal `json:"limit_price,omitempty" example:"101.5"` // обязателен для LIMIT и STOP_LIMIT
+
+	TriggerPrice   *decimal.Decimal `json:"trigger_price,omitempty" example:"99"`  // обязателен для STOP и STOP_LIMIT
+	TrailingOffset *decimal.Decimal `json:"trailing_offset,omitempty" example:"2"` // обязателен для TRAILING_STOP
+
+	TimeInForce string     `json:"time_in_force,omitempty" example:"GTC"` // GTC (по умолчанию), DAY, IOC, FOK, GTD
+	ExpireAt    *time.Time `json:"expire_at,omitempty"`                   // обязателен для GTD
+}
+
+// =========================
+// Audit
+// =========================
+
+type AuditLogResponse struct {
+	Entries      []*entities.AuditEntry `json:"entries"`
+	NextBeforeID int64                  `json:"next_before_id,omitempty" example:"1200"` // before_id следующей страницы; нет — записей больше нет
 }
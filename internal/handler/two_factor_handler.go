@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Skapar/backend/internal/cqrs"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	cmd   cqrs.Command
+	query cqrs.Query
+}
+
+func NewTwoFactorHandler(cmd cqrs.Command, query cqrs.Query) *TwoFactorHandler {
+	return &TwoFactorHandler{cmd: cmd, query: query}
+}
+
+// GetStatus godoc
+// @Summary Two-factor authentication status
+// @Tags two-factor
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entities.TwoFactorStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *gin.Context) {
+	st, err := h.query.GetTwoFactorStatus(c, c.GetInt64("userID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to get two-factor status: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// Enroll godoc
+// @Summary Start TOTP enrollment
+// @Description Returns a new secret and an otpauth:// URI for the authenticator app. Two-factor is enabled only after /2fa/confirm; calling this again replaces an unconfirmed secret.
+// @Tags two-factor
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} entities.TOTPEnrollment
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	enrollment, err := h.cmd.EnrollTOTP(c, c.GetInt64("userID"))
+	if err != nil {
+		twoFactorError(c, err, "failed to start enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm godoc
+// @Summary Confirm TOTP enrollment with the first code and get recovery codes
+// @Tags two-factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body TOTPCodeRequest true "Code from the authenticator app"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.cmd.ConfirmTOTP(c, c.GetInt64("userID"), code)
+	if err != nil {
+		twoFactorError(c, err, "failed to confirm enrollment")
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Tags two-factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body TOTPCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cmd.DisableTOTP(c, c.GetInt64("userID"), code); err != nil {
+		twoFactorError(c, err, "failed to disable two-factor")
+		return
+	}
+	c.JSON(http.StatusOK, MessageResponse{Message: "two-factor authentication disabled"})
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Replace recovery codes
+// @Description Previously issued recovery codes stop working.
+// @Tags two-factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param body body TOTPCodeRequest true "TOTP or recovery code"
+// @Success 200 {object} RecoveryCodesResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Failure 429 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	code, ok := bindCode(c)
+	if !ok {
+		return
+	}
+
+	codes, err := h.cmd.RegenerateRecoveryCodes(c, c.GetInt64("userID"), code)
+	if err != nil {
+		twoFactorError(c, err, "failed to regenerate recovery codes")
+		return
+	}
+	c.JSON(http.StatusOK, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func bindCode(c *gin.Context) (string, bool) {
+	var req TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: code is required"})
+		return "", false
+	}
+	return req.Code, true
+}
+
+// twoFactorError мапит ошибки второго фактора в HTTP-статусы; msg — для непредвиденных ошибок.
+func twoFactorError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, entities.ErrInvalidTwoFactorCode), errors.Is(err, entities.ErrTwoFactorRequired),
+		errors.Is(err, entities.ErrInvalidLoginChallenge):
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, entities.ErrTwoFactorLocked):
+		c.JSON(http.StatusTooManyRequests, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, entities.ErrTwoFactorAlreadyEnabled), errors.Is(err, entities.ErrTwoFactorNotEnrolled),
+		errors.Is(err, entities.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, entities.ErrTwoFactorUnavailable):
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{Error: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: msg})
+	}
+}
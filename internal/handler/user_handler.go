@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/Skapar/backend/internal/auth"
 	"github.com/Skapar/backend/internal/cqrs"
 	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
 )
 
 type UserHandler struct {
@@ -23,7 +26,7 @@ func NewUserHandler(cmd cqrs.Command, query cqrs.Query) *UserHandler {
 }
 
 // GetUserByID godoc
-// @Summary Get user by ID (admin)
+// @Summary Get user by ID (users:read:any)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
@@ -49,8 +52,66 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// CreateUser godoc
+// @Summary Create a user with any role (users:admin)
+// @Description The role must exist. A verification email is sent to the address.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param X-TOTP-Code header string false "TOTP or recovery code, required when two-factor is enabled"
+// @Param body body ProvisionUserRequest true "User payload"
+// @Success 201 {object} RegisterResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 403 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users [post]
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req ProvisionUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + err.Error()})
+		return
+	}
+	if req.Email == "" || req.Password == "" || req.Role == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: email, password, role are required"})
+		return
+	}
+	if !entities.ValidEmail(req.Email) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: " + entities.ErrInvalidEmail.Error()})
+		return
+	}
+	if len(req.Password) < 6 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid input: password must be at least 6 characters"})
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "failed to hash password"})
+		return
+	}
+
+	id, err := h.cmd.ProvisionUser(c, &entities.User{
+		Email:    req.Email,
+		Password: hashedPassword,
+		Role:     entities.Role(strings.ToUpper(req.Role)),
+		Balance:  decimal.Zero,
+	})
+	if err != nil {
+		if errors.Is(err, entities.ErrUnknownRole) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, RegisterResponse{Message: "user created successfully", UserID: id})
+}
+
 // UpdateUser godoc
-// @Summary Update user (admin)
+// @Summary Update user (users:admin)
 // @Tags users
 // @Security BearerAuth
 // @Accept json
@@ -94,11 +155,17 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 		user.Password = hashed
 	}
 	if req.Role != "" {
-		user.Role = entities.Role(req.Role)
+		user.Role = entities.Role(strings.ToUpper(req.Role))
+	}
+	if req.Balance != nil {
+		user.Balance = *req.Balance
 	}
-	user.Balance = req.Balance
 
 	if err := h.cmd.UpdateUser(c, user); err != nil {
+		if errors.Is(err, entities.ErrUnknownRole) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -107,7 +174,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 }
 
 // DeleteUser godoc
-// @Summary Delete user (admin)
+// @Summary Delete user (users:admin)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
@@ -132,6 +199,32 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, MessageResponse{Message: "user deleted successfully"})
 }
 
+// UnlockUser godoc
+// @Summary Clear login lockouts of a user (users:admin)
+// @Description Clears the password lockout for the user's email and the two-factor lockout. IP lockouts expire on their own.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} MessageResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /users/{id}/unlock [post]
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid user id"})
+		return
+	}
+
+	if err := h.cmd.UnlockUser(c, id); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, MessageResponse{Message: "user unlocked"})
+}
+
 // GetMe godoc
 // @Summary Get my profile
 // @Tags users
@@ -151,13 +244,16 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, GetMeResponse{
-		Email:   user.Email,
-		Balance: user.Balance,
+		Email:         user.Email,
+		Balance:       user.Balance,
+		Reserved:      user.ReservedBalance,
+		Available:     user.AvailableBalance(),
+		EmailVerified: user.EmailVerified(),
 	})
 }
 
 // GetAllUsers godoc
-// @Summary Get all users (admin)
+// @Summary Get all users (users:read:any)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
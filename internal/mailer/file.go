@@ -0,0 +1,55 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/Skapar/backend/pkg/logger"
+)
+
+// FileConfig — письма складываются в Dir как .eml; Dir пустой — письма только пишутся в лог.
+type FileConfig struct {
+	Dir  string
+	From string
+	Log  logger.Logger
+}
+
+type fileMailer struct {
+	dir  string
+	from string
+	log  logger.Logger
+}
+
+func NewFileMailer(cfg *FileConfig) (Mailer, error) {
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0o700); err != nil {
+			return nil, err
+		}
+	}
+	return &fileMailer{dir: cfg.Dir, from: cfg.From, log: cfg.Log}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+func (m *fileMailer) Send(_ context.Context, msg *Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if m.dir == "" {
+		m.log.Infof("mail to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return err
+	}
+	m.log.Infof("mail to %s saved to %s", msg.To, path)
+	return nil
+}
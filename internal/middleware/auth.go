@@ -1,45 +1,134 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
-	"github.com/Skapar/backend/config"
+	"github.com/Skapar/backend/internal/audit"
 	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware(cfg *config.Config, allowedRoles ...string) gin.HandlerFunc {
+// APIKeyHeader — заголовок с персональным API-ключом, альтернатива Bearer JWT.
+const APIKeyHeader = "X-API-Key"
+
+// AccessControl — denylist отозванных токенов, API-ключи и права ролей (реализуется cqrs.Query).
+type AccessControl interface {
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*entities.APIKey, *entities.User, error)
+	GetRolePermissions(ctx context.Context, role entities.Role) (entities.PermissionSet, error)
+}
+
+// AuthMiddleware проверяет Bearer JWT или API-ключ и кладёт в контекст пользователя, его роль и права.
+// Какие права нужны маршруту, задаёт RequirePermission.
+func AuthMiddleware(keys *auth.KeySet, acl AccessControl) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		header := c.GetHeader("Authorization")
-		if header == "" {
+		// access_token из query сюда попадает только через QueryToken и только на потоковых маршрутах
+		tokenStr := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenStr == "" {
+			if apiKey := c.GetHeader(APIKeyHeader); apiKey != "" {
+				authenticateAPIKey(c, acl, apiKey)
+				return
+			}
+		}
+		if tokenStr == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing token"})
 			return
 		}
 
-		tokenStr := strings.TrimPrefix(header, "Bearer ")
-		claims, err := auth.ParseToken(cfg.JWTSecret, tokenStr)
+		claims, err := auth.ParseToken(keys, tokenStr)
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
 			return
 		}
 
-		if len(allowedRoles) > 0 {
-			ok := false
-			for _, role := range allowedRoles {
-				if role == claims.Role {
-					ok = true
-					break
-				}
-			}
-			if !ok {
-				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
-				return
-			}
+		isRevoked, err := acl.IsTokenRevoked(c, claims.ID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "token revocation check unavailable"})
+			return
+		}
+		if isRevoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token revoked"})
+			return
+		}
+
+		perms, err := acl.GetRolePermissions(c, entities.Role(claims.Role))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "permission check unavailable"})
+			return
 		}
 
 		c.Set("userID", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("permissions", perms)
+		c.Set("jti", claims.ID)
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
+		audit.MetaFrom(c).SetActor(claims.UserID, claims.Role)
 		c.Next()
 	}
 }
+
+// authenticateAPIKey — ветка AuthMiddleware для X-API-Key: права роли урезаются скоупами ключа.
+func authenticateAPIKey(c *gin.Context, acl AccessControl, apiKey string) {
+	key, user, err := acl.AuthenticateAPIKey(c, apiKey, c.ClientIP())
+	if err != nil {
+		if errors.Is(err, entities.ErrInvalidAPIKey) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api key"})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "api key check unavailable"})
+		return
+	}
+
+	perms, err := acl.GetRolePermissions(c, user.Role)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "permission check unavailable"})
+		return
+	}
+
+	c.Set("userID", user.ID)
+	c.Set("role", string(user.Role))
+	c.Set("permissions", key.Restrict(perms))
+	c.Set("apiKeyID", key.ID)
+	audit.MetaFrom(c).SetActor(user.ID, string(user.Role))
+	c.Next()
+}
+
+// SessionOnly закрывает маршрут для API-ключей: ключом нельзя выпускать новые ключи.
+// Ставится после AuthMiddleware.
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get("apiKeyID"); ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available with an api key"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission пропускает запрос, только если у пользователя есть все перечисленные права.
+// Ставится после AuthMiddleware.
+func RequirePermission(required ...entities.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		perms := Permissions(c)
+		for _, p := range required {
+			if !perms.Has(p) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "access denied"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// Permissions — права текущего пользователя; пустой набор, если AuthMiddleware не отработал.
+func Permissions(c *gin.Context) entities.PermissionSet {
+	v, _ := c.Get("permissions")
+	perms, _ := v.(entities.PermissionSet)
+	return perms
+}
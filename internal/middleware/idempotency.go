@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyHeader = "Idempotency-Key"
+
+// IdempotencyStore — хранилище ответов по Idempotency-Key (реализуется service.Service).
+type IdempotencyStore interface {
+	BeginIdempotent(ctx context.Context, key, requestHash string, ttl time.Duration) (*entities.IdempotencyRecord, bool, error)
+	CompleteIdempotent(ctx context.Context, rec *entities.IdempotencyRecord, ttl time.Duration) error
+	ReleaseIdempotent(ctx context.Context, key string) error
+}
+
+// Idempotency повторно отдаёт сохранённый ответ на запрос с тем же Idempotency-Key.
+// Должен стоять после AuthMiddleware: ключи разделены по пользователям.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idemKey := c.GetHeader(IdempotencyHeader)
+		if idemKey == "" {
+			c.Next()
+			return
+		}
+		if len(idemKey) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(append([]byte(c.Request.Method+" "+c.FullPath()+"\n"), body...))
+		requestHash := hex.EncodeToString(sum[:])
+		key := fmt.Sprintf("%d:%s", c.GetInt64("userID"), idemKey)
+
+		rec, acquired, err := store.BeginIdempotent(c, key, requestHash, ttl)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "idempotency store unavailable"})
+			return
+		}
+
+		if !acquired {
+			switch {
+			case rec.RequestHash != requestHash:
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different payload"})
+			case rec.InProgress():
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with this Idempotency-Key is already in progress"})
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(rec.StatusCode, rec.ContentType, rec.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		// 5xx не запоминаем — повтор должен выполниться заново
+		if recorder.Status() >= http.StatusInternalServerError {
+			if err := store.ReleaseIdempotent(context.Background(), key); err != nil {
+				_ = c.Error(err)
+			}
+			return
+		}
+
+		rec.StatusCode = recorder.Status()
+		rec.ContentType = recorder.Header().Get("Content-Type")
+		rec.Body = recorder.body.Bytes()
+		if err := store.CompleteIdempotent(context.Background(), rec, ttl); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+// responseRecorder копирует тело ответа, чтобы его можно было сохранить.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
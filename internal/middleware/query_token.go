@@ -0,0 +1,31 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// QueryTokenParam — JWT в query для браузерных WebSocket и EventSource: они не умеют ставить заголовки.
+const QueryTokenParam = "access_token"
+
+// QueryToken убирает access_token из query на всех маршрутах, чтобы токен не попадал в логи,
+// и только на перечисленных путях переносит его в Authorization для AuthMiddleware.
+// Ставится первым, до логгера: тот запоминает query в начале запроса.
+func QueryToken(paths ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		allowed[p] = true
+	}
+	return func(c *gin.Context) {
+		q := c.Request.URL.Query()
+		if !q.Has(QueryTokenParam) {
+			c.Next()
+			return
+		}
+
+		token := q.Get(QueryTokenParam)
+		q.Del(QueryTokenParam)
+		c.Request.URL.RawQuery = q.Encode()
+		if token != "" && allowed[c.FullPath()] && c.GetHeader("Authorization") == "" {
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/Skapar/backend/internal/audit"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader — id запроса: берётся от клиента или прокси, иначе генерируется; попадает в журнал аудита.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestMeta кладёт в контекст IP и id запроса для журнала аудита и возвращает id в ответе.
+// Исполнителя позже дописывает AuthMiddleware.
+func RequestMeta() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := audit.RequestID(c.GetHeader(RequestIDHeader))
+		c.Header(RequestIDHeader, id)
+
+		meta := &audit.Meta{IP: c.ClientIP(), RequestID: id}
+		c.Set(audit.GinKey, meta)
+		c.Request = c.Request.WithContext(audit.WithMeta(c.Request.Context(), meta))
+		c.Next()
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/gin-gonic/gin"
+)
+
+// TOTPHeader — код второго фактора для операций, требующих повторного подтверждения.
+const TOTPHeader = "X-TOTP-Code"
+
+// StepUpVerifier проверяет код второго фактора (реализуется cqrs.Command).
+type StepUpVerifier interface {
+	VerifyStepUp(ctx context.Context, userID int64, code string) error
+}
+
+// RequireStepUp требует свежий TOTP-код или код восстановления в X-TOTP-Code, если у пользователя
+// включён второй фактор. Ставится после AuthMiddleware и перед Idempotency: иначе отказ
+// из-за кода сохранился бы как ответ на ключ идемпотентности.
+func RequireStepUp(v StepUpVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := v.VerifyStepUp(c, c.GetInt64("userID"), c.GetHeader(TOTPHeader))
+		switch {
+		case err == nil:
+			c.Next()
+		case errors.Is(err, entities.ErrTwoFactorRequired), errors.Is(err, entities.ErrInvalidTwoFactorCode):
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, entities.ErrTwoFactorLocked):
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "two-factor check unavailable"})
+		}
+	}
+}
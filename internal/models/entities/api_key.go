@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"net"
+	"time"
+)
+
+// APIKeyScope ограничивает, что можно делать ключом. Права ключа — пересечение прав роли
+// владельца и прав его скоупов, поэтому административные права через ключ недоступны.
+type APIKeyScope string
+
+const (
+	ScopeRead     APIKeyScope = "read"     // только чтение
+	ScopeTrade    APIKeyScope = "trade"    // выставление и отмена заявок
+	ScopeWithdraw APIKeyScope = "withdraw" // ввод и вывод средств; без него ключ деньги не двигает
+)
+
+var scopePermissions = map[APIKeyScope][]Permission{
+	ScopeRead: {
+		PermStocksRead,
+		PermOrdersRead, PermOrdersReadAny,
+		PermPortfolioRead, PermPortfolioReadAny,
+		PermHistoryRead, PermHistoryReadAny,
+		PermAccountRead,
+		PermUsersReadAny,
+	},
+	ScopeTrade:    {PermOrdersWrite},
+	ScopeWithdraw: {PermAccountWrite},
+}
+
+func (s APIKeyScope) Valid() bool {
+	_, ok := scopePermissions[s]
+	return ok
+}
+
+// APIKey — персональный ключ для ботов. Сам ключ показывается один раз при создании,
+// в БД хранится только его sha256.
+type APIKey struct {
+	ID         int64      `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"user_id"`
+	Name       string     `db:"name" json:"name"`
+	Prefix     string     `db:"prefix" json:"prefix"` // по нему ключ узнают в списке
+	KeyHash    string     `db:"key_hash" json:"-"`
+	Scopes     []string   `db:"scopes" json:"scopes"`
+	AllowedIPs []string   `db:"allowed_ips" json:"allowed_ips"` // IP или CIDR; пусто — без ограничений
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Active — ключ не отозван и не истёк.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AllowsIP проверяет адрес клиента по allowlist ключа.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, allowed := range k.AllowedIPs {
+		if _, cidr, err := net.ParseCIDR(allowed); err == nil {
+			if cidr.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if other := net.ParseIP(allowed); other != nil && other.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Restrict урезает права роли до прав скоупов ключа.
+func (k *APIKey) Restrict(rolePerms PermissionSet) PermissionSet {
+	out := PermissionSet{}
+	for _, s := range k.Scopes {
+		for _, p := range scopePermissions[APIKeyScope(s)] {
+			if rolePerms.Has(p) {
+				out[p] = struct{}{}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,67 @@
+package entities
+
+import "testing"
+
+func TestAPIKeyAllowsIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		ip      string
+		want    bool
+	}{
+		{"no allowlist", nil, "203.0.113.7", true},
+		{"exact ipv4", []string{"203.0.113.7"}, "203.0.113.7", true},
+		{"other ipv4", []string{"203.0.113.7"}, "203.0.113.8", false},
+		{"inside cidr", []string{"10.0.0.0/8"}, "10.20.30.40", true},
+		{"outside cidr", []string{"10.0.0.0/8"}, "11.0.0.1", false},
+		{"second entry matches", []string{"192.0.2.1", "198.51.100.0/24"}, "198.51.100.200", true},
+		{"ipv6 cidr", []string{"2001:db8::/32"}, "2001:db8::1", true},
+		{"ipv4-mapped ipv6 equals ipv4", []string{"203.0.113.7"}, "::ffff:203.0.113.7", true},
+		{"garbage client ip", []string{"10.0.0.0/8"}, "not-an-ip", false},
+		{"garbage allowlist entry ignored", []string{"bogus", "10.0.0.1"}, "10.0.0.1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &APIKey{AllowedIPs: tt.allowed}
+			if got := k.AllowsIP(tt.ip); got != tt.want {
+				t.Errorf("AllowsIP(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRestrict(t *testing.T) {
+	role := PermissionSet{
+		PermStocksRead:  {},
+		PermOrdersRead:  {},
+		PermOrdersWrite: {},
+	}
+
+	tests := []struct {
+		name   string
+		scopes []string
+		role   PermissionSet
+		want   []Permission
+	}{
+		{"no scopes", nil, role, nil},
+		{"read scope intersected with role", []string{string(ScopeRead)}, role, []Permission{PermStocksRead, PermOrdersRead}},
+		{"trade scope", []string{string(ScopeTrade)}, role, []Permission{PermOrdersWrite}},
+		{"withdraw scope without role permission", []string{string(ScopeWithdraw)}, role, nil},
+		{"unknown scope", []string{"admin"}, role, nil},
+		{"empty role", []string{string(ScopeRead), string(ScopeTrade)}, PermissionSet{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &APIKey{Scopes: tt.scopes}
+			got := k.Restrict(tt.role)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Restrict = %v, want %v", got, tt.want)
+			}
+			for _, p := range tt.want {
+				if !got.Has(p) {
+					t.Errorf("Restrict lacks %s", p)
+				}
+			}
+		})
+	}
+}
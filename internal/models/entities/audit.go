@@ -0,0 +1,98 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	// действия администраторов
+	AuditUserCreate     AuditAction = "USER_CREATE"
+	AuditUserUpdate     AuditAction = "USER_UPDATE"
+	AuditUserDelete     AuditAction = "USER_DELETE"
+	AuditUserUnlock     AuditAction = "USER_UNLOCK"
+	AuditAdminBootstrap AuditAction = "ADMIN_BOOTSTRAP"
+	AuditInviteCreate   AuditAction = "INVITE_CREATE"
+	AuditInviteRevoke   AuditAction = "INVITE_REVOKE"
+	AuditRoleUpdate     AuditAction = "ROLE_UPDATE"
+	AuditStockCreate    AuditAction = "STOCK_CREATE"
+	AuditStockUpdate    AuditAction = "STOCK_UPDATE"
+	AuditStockDelete    AuditAction = "STOCK_DELETE"
+	AuditPortfolioSet   AuditAction = "PORTFOLIO_SET"
+	AuditOrderStatusSet AuditAction = "ORDER_STATUS_SET" // смена статуса чужой заявки
+
+	// события аутентификации
+	AuditRegister           AuditAction = "REGISTER"
+	AuditLogin              AuditAction = "LOGIN"
+	AuditLoginFailed        AuditAction = "LOGIN_FAILED"
+	AuditLoginLockout       AuditAction = "LOGIN_LOCKOUT"
+	AuditLogout             AuditAction = "LOGOUT"
+	AuditRefreshReuse       AuditAction = "REFRESH_TOKEN_REUSE"
+	AuditTwoFactorEnable    AuditAction = "TWO_FACTOR_ENABLE"
+	AuditTwoFactorDisable   AuditAction = "TWO_FACTOR_DISABLE"
+	AuditTwoFactorFailed    AuditAction = "TWO_FACTOR_FAILED"
+	AuditRecoveryCodesReset AuditAction = "RECOVERY_CODES_RESET"
+	AuditAPIKeyCreate       AuditAction = "API_KEY_CREATE"
+	AuditAPIKeyRevoke       AuditAction = "API_KEY_REVOKE"
+	AuditEmailVerify        AuditAction = "EMAIL_VERIFY"
+	AuditPasswordReset      AuditAction = "PASSWORD_RESET"
+)
+
+// Типы объектов в target_type.
+const (
+	AuditTargetUser   = "user"
+	AuditTargetEmail  = "email" // вход по адресу, которого может и не быть в системе
+	AuditTargetIP     = "ip"
+	AuditTargetInvite = "invite"
+	AuditTargetRole   = "role"
+	AuditTargetStock  = "stock"
+	AuditTargetOrder  = "order"
+	AuditTargetAPIKey = "api_key"
+)
+
+// AuditEntry — строка журнала; меняться и удаляться не может (триггер в БД).
+// Hash считается от содержимого записи и PrevHash — хэша предыдущей.
+type AuditEntry struct {
+	ID         int64           `db:"id" json:"id"`
+	ActorID    *int64          `db:"actor_id" json:"actor_id,omitempty"` // nil — система или анонимный запрос
+	ActorRole  string          `db:"actor_role" json:"actor_role,omitempty"`
+	Action     AuditAction     `db:"action" json:"action"`
+	TargetType string          `db:"target_type" json:"target_type"`
+	TargetID   string          `db:"target_id" json:"target_id"`
+	Before     json.RawMessage `db:"before_data" json:"before,omitempty"` // только изменившиеся поля
+	After      json.RawMessage `db:"after_data" json:"after,omitempty"`
+	IP         string          `db:"ip" json:"ip,omitempty"`
+	RequestID  string          `db:"request_id" json:"request_id,omitempty"`
+	PrevHash   string          `db:"prev_hash" json:"prev_hash"`
+	Hash       string          `db:"hash" json:"hash"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter — отбор записей журнала; пустые поля не фильтруют.
+// Страницы идут от новых к старым: BeforeID — id последней записи предыдущей страницы.
+type AuditFilter struct {
+	ActorID    *int64
+	Action     AuditAction
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+// AuditVerification — результат проверки цепочки хэшей.
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int64  `json:"checked"`
+	BrokenAt *int64 `json:"broken_at,omitempty"` // первая запись, не сходящаяся с цепочкой
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Break отмечает цепочку разорванной на записи id.
+func (v *AuditVerification) Break(id int64, reason string) *AuditVerification {
+	v.Valid, v.BrokenAt, v.Reason = false, &id, reason
+	return v
+}
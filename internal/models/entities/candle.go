@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CandleInterval — ширина бара OHLCV.
+type CandleInterval string
+
+const (
+	Candle1m CandleInterval = "1m"
+	Candle5m CandleInterval = "5m"
+	Candle1h CandleInterval = "1h"
+	Candle1d CandleInterval = "1d"
+)
+
+// Duration возвращает ширину бара; 0 — неизвестный интервал.
+func (i CandleInterval) Duration() time.Duration {
+	switch i {
+	case Candle1m:
+		return time.Minute
+	case Candle5m:
+		return 5 * time.Minute
+	case Candle1h:
+		return time.Hour
+	case Candle1d:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// PriceTick — одно изменение цены акции.
+type PriceTick struct {
+	ID        int64           `db:"id" json:"id"`
+	StockID   int64           `db:"stock_id" json:"stock_id"`
+	Price     decimal.Decimal `db:"price" json:"price"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Candle — бар OHLCV. Цены берутся из тиков и сделок, объём — из сделок.
+type Candle struct {
+	OpenTime time.Time       `db:"open_time" json:"open_time"`
+	Open     decimal.Decimal `db:"open" json:"open"`
+	High     decimal.Decimal `db:"high" json:"high"`
+	Low      decimal.Decimal `db:"low" json:"low"`
+	Close    decimal.Decimal `db:"close" json:"close"`
+	Volume   decimal.Decimal `db:"volume" json:"volume"`
+}
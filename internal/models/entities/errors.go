@@ -0,0 +1,72 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrInsufficientShares = errors.New("not enough stocks to sell")
+	ErrInvalidAmount      = errors.New("amount must be positive")
+	ErrInvalidTickSize    = errors.New("price is not a multiple of the stock tick size")
+	ErrInvalidLotSize     = errors.New("quantity is not a multiple of the stock lot size")
+
+	ErrInvalidCostBasisMethod = errors.New("cost basis method must be FIFO, LIFO or AVERAGE")
+
+	ErrInvalidCandleInterval = errors.New("interval must be one of 1m, 5m, 1h, 1d")
+	ErrInvalidCandleRange    = errors.New("from must be before to")
+
+	ErrIdempotencyKeyTaken = errors.New("idempotency key was taken by another request before the response was stored")
+
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, session revoked")
+
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrInvalidEmailToken    = errors.New("token is invalid, expired or already used")
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrEmailNotVerified     = errors.New("email is not verified, confirm it before trading")
+
+	ErrInvalidInvite  = errors.New("invite code is invalid, expired or already used")
+	ErrInviteNotFound = errors.New("invite not found")
+	ErrAdminExists    = errors.New("an administrator already exists")
+
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrUnknownPermission = errors.New("unknown permission")
+
+	ErrInvalidAPIKey      = errors.New("api key is invalid, expired or not allowed from this address")
+	ErrInvalidAPIKeyScope = errors.New("scopes must be a non-empty list of read, trade, withdraw")
+	ErrInvalidAllowedIP   = errors.New("allowed_ips must contain IP addresses or CIDR ranges")
+	ErrTooManyAPIKeys     = errors.New("api key limit reached, revoke an unused key first")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+
+	ErrTwoFactorUnavailable    = errors.New("two-factor authentication is not configured on this server")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor enrollment has not been started")
+	ErrTwoFactorNotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorRequired       = errors.New("two-factor code required")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked         = errors.New("too many invalid two-factor codes, try again later")
+	ErrInvalidLoginChallenge   = errors.New("login challenge is invalid or expired")
+)
+
+// LoginLockedError — вход временно закрыт после серии неверных паролей (по email или по IP).
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return "too many failed login attempts, try again later"
+}
+
+// OrderTransitionError — недопустимый (или уже неактуальный) переход статуса заявки.
+type OrderTransitionError struct {
+	From OrderStatus
+	To   OrderStatus
+}
+
+func (e *OrderTransitionError) Error() string {
+	return fmt.Sprintf("order status transition %s -> %s is not allowed", e.From, e.To)
+}
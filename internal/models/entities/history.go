@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type HistoryAction string
 
@@ -10,15 +14,21 @@ const (
 	ActionBalanceUpdate HistoryAction = "BALANCE_UPDATE"
 	ActionDeposit       HistoryAction = "DEPOSIT"
 	ActionWithdraw      HistoryAction = "WITHDRAW"
+	ActionCancel        HistoryAction = "CANCEL"
+	ActionExpire        HistoryAction = "EXPIRE"
+	ActionStopTriggered HistoryAction = "STOP_TRIGGERED"
+	ActionLoginLockout  HistoryAction = "LOGIN_LOCKOUT"
+	ActionLoginUnlock   HistoryAction = "LOGIN_UNLOCK"
 )
 
 type History struct {
-	ID        int64         `db:"id" json:"id"`
-	UserID    int64         `db:"user_id" json:"user_id"`
-	OrderID   *int64        `db:"order_id" json:"order_id,omitempty"`
-	StockID   *int64        `db:"stock_id" json:"stock_id,omitempty"`
-	Action    HistoryAction `db:"action" json:"action"`
-	Details   string        `db:"details" json:"details"`
-	Amount    float64       `db:"amount" json:"amount"`
-	CreatedAt time.Time     `db:"created_at" json:"created_at"`
+	ID          int64            `db:"id" json:"id"`
+	UserID      int64            `db:"user_id" json:"user_id"`
+	OrderID     *int64           `db:"order_id" json:"order_id,omitempty"`
+	StockID     *int64           `db:"stock_id" json:"stock_id,omitempty"`
+	Action      HistoryAction    `db:"action" json:"action"`
+	Details     string           `db:"details" json:"details"`
+	Amount      decimal.Decimal  `db:"amount" json:"amount"`
+	RealizedPnL *decimal.Decimal `db:"realized_pnl" json:"realized_pnl,omitempty"` // только у SELL
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
 }
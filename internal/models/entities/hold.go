@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type HoldKind string
+
+const (
+	HoldCash   HoldKind = "CASH"
+	HoldShares HoldKind = "SHARES"
+)
+
+type HoldStatus string
+
+const (
+	HoldActive   HoldStatus = "ACTIVE"
+	HoldConsumed HoldStatus = "CONSUMED"
+	HoldReleased HoldStatus = "RELEASED"
+)
+
+// Hold — резерв денег (BUY) или бумаг (SELL) под открытую заявку.
+// Remaining уменьшается с каждым исполнением, остаток освобождается при закрытии заявки.
+type Hold struct {
+	ID        int64           `db:"id" json:"id"`
+	OrderID   int64           `db:"order_id" json:"order_id"`
+	UserID    int64           `db:"user_id" json:"user_id"`
+	StockID   int64           `db:"stock_id" json:"stock_id"`
+	Kind      HoldKind        `db:"kind" json:"kind"`
+	Amount    decimal.Decimal `db:"amount" json:"amount"`
+	Remaining decimal.Decimal `db:"remaining" json:"remaining"`
+	Status    HoldStatus      `db:"status" json:"status"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+}
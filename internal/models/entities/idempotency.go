@@ -0,0 +1,18 @@
+package entities
+
+import "time"
+
+// IdempotencyRecord — сохранённый ответ на запрос с заголовком Idempotency-Key.
+// StatusCode == 0 означает, что первый запрос ещё обрабатывается.
+type IdempotencyRecord struct {
+	Key         string    `db:"key" json:"key"`
+	RequestHash string    `db:"request_hash" json:"request_hash"`
+	StatusCode  int       `db:"status_code" json:"status_code"`
+	ContentType string    `db:"content_type" json:"content_type"`
+	Body        []byte    `db:"body" json:"body"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
+}
+
+func (r *IdempotencyRecord) InProgress() bool {
+	return r.StatusCode == 0
+}
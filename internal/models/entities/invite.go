@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// Invite — приглашение на регистрацию с заданной ролью. Сам код показывается один раз
+// при создании, в БД хранится только его sha256.
+type Invite struct {
+	ID        int64      `db:"id" json:"id"`
+	CodeHash  string     `db:"code_hash" json:"-"`
+	Role      Role       `db:"role" json:"role"`
+	Email     string     `db:"email" json:"email,omitempty"` // пусто — код подходит для любого адреса
+	CreatedBy *int64     `db:"created_by" json:"created_by,omitempty"`
+	UsedBy    *int64     `db:"used_by" json:"used_by,omitempty"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// Active — приглашение ещё можно использовать.
+func (i *Invite) Active(now time.Time) bool {
+	return i.UsedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type JournalKind string
+
+const (
+	JournalOpening    JournalKind = "OPENING"
+	JournalDeposit    JournalKind = "DEPOSIT"
+	JournalWithdraw   JournalKind = "WITHDRAW"
+	JournalTrade      JournalKind = "TRADE"
+	JournalAdjustment JournalKind = "ADJUSTMENT"
+)
+
+// Системные счета леджера.
+const (
+	AccountExternal   = "SYSTEM:EXTERNAL"   // деньги, пришедшие извне / ушедшие наружу
+	AccountHouse      = "SYSTEM:HOUSE"      // контрагент рыночных заявок
+	AccountAdjustment = "SYSTEM:ADJUSTMENT" // ручные корректировки админом
+)
+
+// LedgerAccount — счёт леджера. UserID задан только у денежных счетов пользователей.
+type LedgerAccount struct {
+	ID     int64  `db:"id" json:"id"`
+	Code   string `db:"code" json:"code"`
+	UserID *int64 `db:"user_id" json:"user_id,omitempty"`
+}
+
+func UserCashAccount(userID int64) LedgerAccount {
+	return LedgerAccount{Code: fmt.Sprintf("USER:%d:CASH", userID), UserID: &userID}
+}
+
+func SystemAccount(code string) LedgerAccount {
+	return LedgerAccount{Code: code}
+}
+
+type LedgerJournal struct {
+	ID        int64       `db:"id" json:"id"`
+	Kind      JournalKind `db:"kind" json:"kind"`
+	Reference string      `db:"reference" json:"reference"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+}
+
+// LedgerEntry — одна сторона проводки. Положительная сумма увеличивает остаток счёта;
+// сумма всех записей журнала равна нулю.
+type LedgerEntry struct {
+	ID          int64           `db:"id" json:"id"`
+	JournalID   int64           `db:"journal_id" json:"journal_id"`
+	JournalKind JournalKind     `db:"journal_kind" json:"journal_kind"`
+	Reference   string          `db:"reference" json:"reference"`
+	AccountCode string          `db:"account_code" json:"account_code"`
+	Amount      decimal.Decimal `db:"amount" json:"amount"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
+
+// BalanceMismatch — расхождение stock_user.balance с остатком по леджеру.
+type BalanceMismatch struct {
+	UserID        int64           `db:"user_id" json:"user_id"`
+	Balance       decimal.Decimal `db:"balance" json:"balance"`
+	LedgerBalance decimal.Decimal `db:"ledger_balance" json:"ledger_balance"`
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type OrderType string
 
@@ -9,22 +13,108 @@ const (
 	OrderSell OrderType = "SELL"
 )
 
+type OrderKind string
+
+const (
+	OrderMarket       OrderKind = "MARKET"
+	OrderLimit        OrderKind = "LIMIT"
+	OrderStop         OrderKind = "STOP"          // по срабатыванию становится MARKET
+	OrderStopLimit    OrderKind = "STOP_LIMIT"    // по срабатыванию становится LIMIT
+	OrderTrailingStop OrderKind = "TRAILING_STOP" // STOP, у которого trigger следует за ценой
+)
+
+// IsStop — условная заявка, которая ждёт пересечения trigger_price.
+func (k OrderKind) IsStop() bool {
+	return k == OrderStop || k == OrderStopLimit || k == OrderTrailingStop
+}
+
+// Activated — во что превращается условная заявка после срабатывания.
+func (k OrderKind) Activated() OrderKind {
+	if k == OrderStopLimit {
+		return OrderLimit
+	}
+	return OrderMarket
+}
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC" // до отмены
+	TimeInForceDAY TimeInForce = "DAY" // до закрытия торговой сессии
+	TimeInForceIOC TimeInForce = "IOC" // исполнить что можно, остаток отменить
+	TimeInForceFOK TimeInForce = "FOK" // исполнить целиком или отменить
+	TimeInForceGTD TimeInForce = "GTD" // до expires_at
+)
+
+func (t TimeInForce) Valid() bool {
+	switch t {
+	case TimeInForceGTC, TimeInForceDAY, TimeInForceIOC, TimeInForceFOK, TimeInForceGTD:
+		return true
+	}
+	return false
+}
+
 type OrderStatus string
 
 const (
-	OrderPending   OrderStatus = "PENDING"
-	OrderCompleted OrderStatus = "COMPLETED"
-	OrderFailed    OrderStatus = "FAILED"
+	OrderNew             OrderStatus = "NEW"
+	OrderPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderFilled          OrderStatus = "FILLED"
+	OrderCancelled       OrderStatus = "CANCELLED"
+	OrderRejected        OrderStatus = "REJECTED"
+	OrderExpired         OrderStatus = "EXPIRED"
 )
 
+// IsOpen — заявка ещё может исполняться.
+func (s OrderStatus) IsOpen() bool {
+	return s == OrderNew || s == OrderPartiallyFilled
+}
+
+func (s OrderStatus) Valid() bool {
+	switch s {
+	case OrderNew, OrderPartiallyFilled, OrderFilled, OrderCancelled, OrderRejected, OrderExpired:
+		return true
+	}
+	return false
+}
+
 type Order struct {
-	ID        int64       `db:"id" json:"id"`
-	UserID    int64       `db:"user_id" json:"user_id"`
-	StockID   int64       `db:"stock_id" json:"stock_id"`
-	OrderType OrderType   `db:"order_type" json:"order_type"`
-	Quantity  float64     `db:"quantity" json:"quantity"`
-	Price     float64     `db:"price" json:"price"`
-	Status    OrderStatus `db:"status" json:"status"`
-	CreatedAt time.Time   `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time   `db:"updated_at" json:"updated_at"`
+	ID             int64            `db:"id" json:"id"`
+	UserID         int64            `db:"user_id" json:"user_id"`
+	StockID        int64            `db:"stock_id" json:"stock_id"`
+	OrderType      OrderType        `db:"order_type" json:"order_type"`
+	Kind           OrderKind        `db:"kind" json:"kind"`
+	Quantity       decimal.Decimal  `db:"quantity" json:"quantity"`
+	Price          decimal.Decimal  `db:"price" json:"price"`
+	LimitPrice     *decimal.Decimal `db:"limit_price" json:"limit_price,omitempty"`
+	TriggerPrice   *decimal.Decimal `db:"trigger_price" json:"trigger_price,omitempty"`
+	TrailingOffset *decimal.Decimal `db:"trailing_offset" json:"trailing_offset,omitempty"`
+	ActivatedAt    *time.Time       `db:"activated_at" json:"activated_at,omitempty"`
+	TimeInForce    TimeInForce      `db:"time_in_force" json:"time_in_force"`
+	ExpiresAt      *time.Time       `db:"expires_at" json:"expires_at,omitempty"`
+	FilledQuantity decimal.Decimal  `db:"filled_quantity" json:"filled_quantity"`
+	AvgFillPrice   decimal.Decimal  `db:"avg_fill_price" json:"avg_fill_price"`
+	Status         OrderStatus      `db:"status" json:"status"`
+	CreatedAt      time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time        `db:"updated_at" json:"updated_at"`
+}
+
+// RemainingQuantity — сколько ещё осталось исполнить.
+func (o *Order) RemainingQuantity() decimal.Decimal {
+	return o.Quantity.Sub(o.FilledQuantity)
+}
+
+// ApplyFill учитывает исполнение qty по цене price в filled_quantity и средней цене
+// и возвращает статус, в который должна перейти заявка.
+func (o *Order) ApplyFill(price, qty decimal.Decimal) OrderStatus {
+	total := o.AvgFillPrice.Mul(o.FilledQuantity).Add(price.Mul(qty))
+	o.FilledQuantity = o.FilledQuantity.Add(qty)
+	if o.FilledQuantity.IsPositive() {
+		o.AvgFillPrice = total.DivRound(o.FilledQuantity, Scale)
+	}
+
+	if !o.RemainingQuantity().IsPositive() {
+		return OrderFilled
+	}
+	return OrderPartiallyFilled
 }
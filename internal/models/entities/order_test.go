@@ -0,0 +1,43 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOrderApplyFill(t *testing.T) {
+	type fill struct{ price, qty string }
+	tests := []struct {
+		name       string
+		quantity   string
+		fills      []fill
+		wantStatus OrderStatus
+		wantFilled string
+		wantAvg    string
+	}{
+		{"single partial fill", "10", []fill{{"100", "4"}}, OrderPartiallyFilled, "4", "100"},
+		{"single full fill", "10", []fill{{"100", "10"}}, OrderFilled, "10", "100"},
+		{"weighted average price", "10", []fill{{"100", "4"}, {"110", "6"}}, OrderFilled, "10", "106"},
+		{"average rounded to scale", "3", []fill{{"1", "1"}, {"2", "1"}, {"2", "1"}}, OrderFilled, "3", "1.66666667"},
+		{"fractional quantities", "1", []fill{{"50", "0.25"}, {"70", "0.25"}}, OrderPartiallyFilled, "0.5", "60"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &Order{Quantity: decimal.RequireFromString(tt.quantity)}
+			var status OrderStatus
+			for _, f := range tt.fills {
+				status = o.ApplyFill(decimal.RequireFromString(f.price), decimal.RequireFromString(f.qty))
+			}
+			if status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", status, tt.wantStatus)
+			}
+			if !o.FilledQuantity.Equal(decimal.RequireFromString(tt.wantFilled)) {
+				t.Errorf("filled = %s, want %s", o.FilledQuantity, tt.wantFilled)
+			}
+			if !o.AvgFillPrice.Equal(decimal.RequireFromString(tt.wantAvg)) {
+				t.Errorf("avg price = %s, want %s", o.AvgFillPrice, tt.wantAvg)
+			}
+		})
+	}
+}
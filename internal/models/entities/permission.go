@@ -0,0 +1,75 @@
+package entities
+
+// Permission — именованное право. Суффикс :any расширяет право на чужие ресурсы;
+// без него пользователь работает только со своими.
+type Permission string
+
+const (
+	PermUsersReadAny Permission = "users:read:any"
+	PermUsersAdmin   Permission = "users:admin"
+
+	PermStocksRead  Permission = "stocks:read"
+	PermStocksWrite Permission = "stocks:write"
+
+	PermOrdersRead     Permission = "orders:read"
+	PermOrdersReadAny  Permission = "orders:read:any"
+	PermOrdersWrite    Permission = "orders:write"
+	PermOrdersWriteAny Permission = "orders:write:any" // в т.ч. любой переход статуса, а не только отмена
+
+	PermPortfolioRead     Permission = "portfolio:read"
+	PermPortfolioReadAny  Permission = "portfolio:read:any"
+	PermPortfolioWrite    Permission = "portfolio:write"
+	PermPortfolioWriteAny Permission = "portfolio:write:any" // ручная правка позиций
+
+	PermHistoryRead     Permission = "history:read"
+	PermHistoryReadAny  Permission = "history:read:any"
+	PermHistoryWrite    Permission = "history:write"
+	PermHistoryWriteAny Permission = "history:write:any"
+
+	PermAccountRead     Permission = "account:read"
+	PermAccountWrite    Permission = "account:write"
+	PermLedgerReconcile Permission = "ledger:reconcile"
+
+	PermRolesAdmin Permission = "roles:admin"
+
+	PermAuditRead Permission = "audit:read"
+)
+
+// PermissionSet — права роли, загруженные из stock_role_permission.
+type PermissionSet map[Permission]struct{}
+
+func NewPermissionSet(perms []Permission) PermissionSet {
+	ps := make(PermissionSet, len(perms))
+	for _, p := range perms {
+		ps[p] = struct{}{}
+	}
+	return ps
+}
+
+func (ps PermissionSet) Has(p Permission) bool {
+	_, ok := ps[p]
+	return ok
+}
+
+// CanAccess — проверка владения: свой ресурс доступен всегда, чужой — только с правом anyPerm.
+func (ps PermissionSet) CanAccess(actorID, ownerID int64, anyPerm Permission) bool {
+	return actorID == ownerID || ps.Has(anyPerm)
+}
+
+// RoleInfo — роль и её права; роли заводятся в БД без изменений кода.
+type RoleInfo struct {
+	Name        Role         `db:"name" json:"name"`
+	Description string       `db:"description" json:"description"`
+	Permissions []Permission `db:"-" json:"permissions"`
+}
+
+type PermissionInfo struct {
+	Name        Permission `db:"name" json:"name"`
+	Description string     `db:"description" json:"description"`
+}
+
+// RolePermission — строка stock_role_permission.
+type RolePermission struct {
+	Role       Role       `db:"role"`
+	Permission Permission `db:"permission"`
+}
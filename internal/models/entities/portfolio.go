@@ -1,12 +1,36 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type Portfolio struct {
-	ID        int64     `db:"id" json:"id"`
-	UserID    int64     `db:"user_id" json:"user_id"`
-	StockID   int64     `db:"stock_id" json:"stock_id"`
-	Quantity  float64   `db:"quantity" json:"quantity"`
-	Version   int       `db:"version" json:"version"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ID                int64           `db:"id" json:"id"`
+	UserID            int64           `db:"user_id" json:"user_id"`
+	StockID           int64           `db:"stock_id" json:"stock_id"`
+	Quantity          decimal.Decimal `db:"quantity" json:"quantity"`
+	LockedQuantity    decimal.Decimal `db:"locked_quantity" json:"locked_quantity"`       // под открытыми SELL-заявками
+	AvailableQuantity decimal.Decimal `db:"available_quantity" json:"available_quantity"` // quantity - locked_quantity
+	Version           int             `db:"version" json:"version"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// Position — позиция с оценкой по текущей цене акции.
+type Position struct {
+	Portfolio
+	Symbol        string          `json:"symbol"`
+	AvgCost       decimal.Decimal `json:"avg_cost"`
+	CostBasis     decimal.Decimal `json:"cost_basis"`
+	MarketPrice   decimal.Decimal `json:"market_price"`
+	MarketValue   decimal.Decimal `json:"market_value"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl"`
+}
+
+type PortfolioValuation struct {
+	Positions     []*Position     `json:"positions"`
+	CostBasis     decimal.Decimal `json:"cost_basis"`
+	MarketValue   decimal.Decimal `json:"market_value"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl"`
 }
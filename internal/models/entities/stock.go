@@ -1,11 +1,37 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Scale — число знаков после запятой у денежных и количественных колонок (NUMERIC(20, 8)).
+const Scale int32 = 8
 
 type Stock struct {
-	ID        int64     `db:"id" json:"id"`
-	Symbol    string    `db:"symbol" json:"symbol"`
-	Name      string    `db:"name" json:"name"`
-	Price     float64   `db:"price" json:"price"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	ID        int64           `db:"id" json:"id"`
+	Symbol    string          `db:"symbol" json:"symbol"`
+	Name      string          `db:"name" json:"name"`
+	Price     decimal.Decimal `db:"price" json:"price"`
+	TickSize  decimal.Decimal `db:"tick_size" json:"tick_size"` // шаг цены
+	LotSize   decimal.Decimal `db:"lot_size" json:"lot_size"`   // шаг количества
+	UpdatedAt time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// ValidPrice — цена кратна шагу цены. Нулевой шаг ограничений не накладывает.
+func (s *Stock) ValidPrice(price decimal.Decimal) bool {
+	return multipleOf(price, s.TickSize)
+}
+
+// ValidQuantity — количество кратно лоту.
+func (s *Stock) ValidQuantity(qty decimal.Decimal) bool {
+	return multipleOf(qty, s.LotSize)
+}
+
+func multipleOf(v, step decimal.Decimal) bool {
+	if !step.IsPositive() {
+		return true
+	}
+	return v.Mod(step).IsZero()
 }
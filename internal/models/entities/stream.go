@@ -0,0 +1,63 @@
+package entities
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type StreamEventType string
+
+const (
+	EventPrice StreamEventType = "price" // изменение цены акции
+	EventOrder StreamEventType = "order" // смена статуса или исполнения заявки
+	EventFill  StreamEventType = "fill"  // исполнение заявки пользователя
+)
+
+// StreamEvent — событие, рассылаемое подписчикам канала через Redis pub/sub.
+type StreamEvent struct {
+	ID      string          `json:"id,omitempty"`
+	Channel string          `json:"channel"`
+	Type    StreamEventType `json:"type"`
+	Data    json.RawMessage `json:"data"`
+	Time    time.Time       `json:"time"`
+}
+
+// PriceChannel — публичный канал цен одной акции.
+func PriceChannel(symbol string) string {
+	return "prices:" + strings.ToUpper(symbol)
+}
+
+// UserChannel — приватный канал заявок и исполнений пользователя.
+func UserChannel(userID int64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+type PriceEvent struct {
+	StockID int64           `json:"stock_id"`
+	Symbol  string          `json:"symbol"`
+	Price   decimal.Decimal `json:"price"`
+}
+
+type OrderEvent struct {
+	OrderID        int64           `json:"order_id"`
+	StockID        int64           `json:"stock_id"`
+	Type           OrderType       `json:"type"`
+	Kind           OrderKind       `json:"kind"`
+	Status         OrderStatus     `json:"status"`
+	Quantity       decimal.Decimal `json:"quantity"`
+	FilledQuantity decimal.Decimal `json:"filled_quantity"`
+	AvgFillPrice   decimal.Decimal `json:"avg_fill_price"`
+}
+
+type FillEvent struct {
+	TradeID  int64           `json:"trade_id,omitempty"` // 0 у рыночных заявок, исполненных по цене акции
+	OrderID  int64           `json:"order_id"`
+	StockID  int64           `json:"stock_id"`
+	Side     OrderType       `json:"side"`
+	Price    decimal.Decimal `json:"price"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
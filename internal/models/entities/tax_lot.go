@@ -0,0 +1,36 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// CostBasisMethod — из каких лотов списывается позиция при продаже.
+type CostBasisMethod string
+
+const (
+	CostBasisFIFO    CostBasisMethod = "FIFO"    // сначала самые старые лоты
+	CostBasisLIFO    CostBasisMethod = "LIFO"    // сначала самые новые лоты
+	CostBasisAverage CostBasisMethod = "AVERAGE" // по средней цене всех открытых лотов
+)
+
+func (m CostBasisMethod) Valid() bool {
+	switch m {
+	case CostBasisFIFO, CostBasisLIFO, CostBasisAverage:
+		return true
+	}
+	return false
+}
+
+// TaxLot — партия бумаг, купленная одним исполнением по одной цене.
+type TaxLot struct {
+	ID        int64           `db:"id" json:"id"`
+	UserID    int64           `db:"user_id" json:"user_id"`
+	StockID   int64           `db:"stock_id" json:"stock_id"`
+	OrderID   *int64          `db:"order_id" json:"order_id,omitempty"`
+	Quantity  decimal.Decimal `db:"quantity" json:"quantity"`
+	Remaining decimal.Decimal `db:"remaining" json:"remaining"`
+	Price     decimal.Decimal `db:"price" json:"price"` // цена за бумагу
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package entities
+
+import "time"
+
+// RefreshToken — серверная запись refresh-токена. Токены одного логина образуют семейство:
+// при каждом обновлении выдаётся новый, а старый помечается использованным.
+type RefreshToken struct {
+	ID        int64      `db:"id" json:"id"`
+	UserID    int64      `db:"user_id" json:"user_id"`
+	FamilyID  string     `db:"family_id" json:"family_id"`
+	TokenHash string     `db:"token_hash" json:"-"`
+	ExpiresAt time.Time  `db:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time `db:"used_at" json:"used_at,omitempty"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+}
+
+// TokenPair — то, что получает клиент при логине и обновлении.
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// UserToken — одноразовый токен из письма: подтверждение email или сброс пароля.
+type UserToken struct {
+	JTI       string     `db:"jti"`
+	UserID    int64      `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	Email     string     `db:"email"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Trade — сделка. У исполнений рыночных и стоп-заявок против дома контрагента нет:
+// заявка и пользователь на стороне дома пустые.
+type Trade struct {
+	ID          int64           `db:"id" json:"id"`
+	StockID     int64           `db:"stock_id" json:"stock_id"`
+	BuyOrderID  *int64          `db:"buy_order_id" json:"buy_order_id"`
+	SellOrderID *int64          `db:"sell_order_id" json:"sell_order_id"`
+	BuyerID     *int64          `db:"buyer_id" json:"buyer_id"`
+	SellerID    *int64          `db:"seller_id" json:"seller_id"`
+	Price       decimal.Decimal `db:"price" json:"price"`
+	Quantity    decimal.Decimal `db:"quantity" json:"quantity"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package entities
+
+import "time"
+
+// UserTOTP — второй фактор пользователя. Секрет хранится зашифрованным ключом из конфига.
+// Пока EnabledAt пуст, подключение не подтверждено и при логине код не спрашивается.
+type UserTOTP struct {
+	UserID         int64      `db:"user_id"`
+	SecretEnc      []byte     `db:"secret_enc"`
+	EnabledAt      *time.Time `db:"enabled_at"`
+	LastUsedStep   int64      `db:"last_used_step"` // шаг последнего принятого кода: тот же код второй раз не пройдёт
+	FailedAttempts int        `db:"failed_attempts"`
+	LockedUntil    *time.Time `db:"locked_until"`
+	CreatedAt      time.Time  `db:"created_at"`
+}
+
+func (t *UserTOTP) Enabled() bool {
+	return t != nil && t.EnabledAt != nil
+}
+
+// Locked — после серии неверных кодов проверка временно закрыта.
+func (t *UserTOTP) Locked(now time.Time) bool {
+	return t.LockedUntil != nil && now.Before(*t.LockedUntil)
+}
+
+// TOTPEnrollment — то, что показывается пользователю при подключении: секрет и ссылка для QR-кода.
+type TOTPEnrollment struct {
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXP"`
+	URI    string `json:"uri" example:"otpauth://totp/Stock:test@mail.com?secret=JBSWY3DPEHPK3PXP&issuer=Stock"`
+}
+
+// TwoFactorStatus — состояние второго фактора для профиля пользователя.
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// LoginChallenge — выдаётся вместо токенов, если у пользователя включён второй фактор.
+type LoginChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
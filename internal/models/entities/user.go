@@ -1,6 +1,11 @@
 package entities
 
-import "time"
+import (
+	"net/mail"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 type Role string
 
@@ -10,10 +15,28 @@ const (
 )
 
 type User struct {
-	ID        int64     `db:"id"`
-	Email     string    `db:"email"`
-	Password  string    `db:"password"`
-	Role      Role      `db:"role"`
-	Balance   float64   `db:"balance"`
-	CreatedAt time.Time `db:"created_at"`
+	ID              int64           `db:"id"`
+	Email           string          `db:"email"`
+	Password        string          `db:"password"`
+	Role            Role            `db:"role"`
+	Balance         decimal.Decimal `db:"balance"`
+	ReservedBalance decimal.Decimal `db:"reserved_balance"` // зарезервировано под открытые BUY-заявки
+	CostBasisMethod CostBasisMethod `db:"cost_basis_method"`
+	EmailVerifiedAt *time.Time      `db:"email_verified_at"` // nil — email не подтверждён, торговать нельзя
+	CreatedAt       time.Time       `db:"created_at"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// AvailableBalance — сколько можно потратить или вывести прямо сейчас.
+func (u *User) AvailableBalance() decimal.Decimal {
+	return u.Balance.Sub(u.ReservedBalance)
+}
+
+// ValidEmail — адрес без имени и угловых скобок, как его вводят при регистрации.
+func ValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
 }
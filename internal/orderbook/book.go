@@ -0,0 +1,168 @@
+package orderbook
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+// Entry — лимитная заявка, стоящая в стакане.
+type Entry struct {
+	OrderID   int64
+	UserID    int64
+	Side      entities.OrderType
+	Price     decimal.Decimal
+	Remaining decimal.Decimal
+	CreatedAt time.Time
+}
+
+// Fill — результат встречи входящей (taker) заявки со стоящей в стакане (maker).
+type Fill struct {
+	MakerOrderID int64
+	MakerUserID  int64
+	TakerOrderID int64
+	TakerUserID  int64
+	TakerSide    entities.OrderType
+	Price        decimal.Decimal
+	Quantity     decimal.Decimal
+}
+
+// Book — стакан одной акции с приоритетом цена-время.
+// Вызывающий код обязан держать Lock на время Match/Add/Remove.
+type Book struct {
+	sync.Mutex
+
+	StockID int64
+	bids    []*Entry // по убыванию цены, затем по времени
+	asks    []*Entry // по возрастанию цены, затем по времени
+}
+
+func newBook(stockID int64) *Book {
+	return &Book{StockID: stockID}
+}
+
+// Match исполняет taker против противоположной стороны стакана, пока цены пересекаются.
+// Остаток taker не добавляется в стакан — для этого есть Add.
+//
+// Защита от сделки с самим собой — cancel newest: дойдя до своей же встречной заявки,
+// Match останавливается и возвращает selfMatch=true. Остаток taker вызывающий должен отменить,
+// а не ставить в стакан: иначе он встал бы по цене, пересекающей его же заявку, и стакан
+// оказался бы перекрещенным. Стоящая заявка не трогается.
+func (b *Book) Match(taker *Entry) (fills []Fill, selfMatch bool) {
+	book := &b.asks
+	if taker.Side == entities.OrderSell {
+		book = &b.bids
+	}
+
+	i := 0
+	for i < len(*book) && taker.Remaining.IsPositive() {
+		maker := (*book)[i]
+		if !crosses(taker, maker) {
+			break
+		}
+		if maker.UserID == taker.UserID {
+			return fills, true
+		}
+
+		qty := decimal.Min(taker.Remaining, maker.Remaining)
+		fills = append(fills, Fill{
+			MakerOrderID: maker.OrderID,
+			MakerUserID:  maker.UserID,
+			TakerOrderID: taker.OrderID,
+			TakerUserID:  taker.UserID,
+			TakerSide:    taker.Side,
+			Price:        maker.Price,
+			Quantity:     qty,
+		})
+
+		taker.Remaining = taker.Remaining.Sub(qty)
+		maker.Remaining = maker.Remaining.Sub(qty)
+		if !maker.Remaining.IsPositive() {
+			*book = append((*book)[:i], (*book)[i+1:]...)
+			continue
+		}
+		i++
+	}
+
+	return fills, false
+}
+
+// Available — объём встречных заявок, с которыми taker может сойтись по цене до первой
+// своей заявки (на ней Match остановится). Нужен для FOK: заявка исполняется, только если
+// объёма хватает целиком.
+func (b *Book) Available(taker *Entry) decimal.Decimal {
+	book := b.asks
+	if taker.Side == entities.OrderSell {
+		book = b.bids
+	}
+
+	total := decimal.Zero
+	for _, maker := range book {
+		if !crosses(taker, maker) {
+			break
+		}
+		if maker.UserID == taker.UserID {
+			break
+		}
+		total = total.Add(maker.Remaining)
+	}
+	return total
+}
+
+// Add ставит заявку в стакан с соблюдением приоритета цена-время.
+func (b *Book) Add(e *Entry) {
+	if !e.Remaining.IsPositive() {
+		return
+	}
+
+	if e.Side == entities.OrderBuy {
+		idx := sort.Search(len(b.bids), func(i int) bool {
+			return b.bids[i].Price.LessThan(e.Price) ||
+				(b.bids[i].Price.Equal(e.Price) && b.bids[i].CreatedAt.After(e.CreatedAt))
+		})
+		b.bids = insertAt(b.bids, idx, e)
+		return
+	}
+
+	idx := sort.Search(len(b.asks), func(i int) bool {
+		return b.asks[i].Price.GreaterThan(e.Price) ||
+			(b.asks[i].Price.Equal(e.Price) && b.asks[i].CreatedAt.After(e.CreatedAt))
+	})
+	b.asks = insertAt(b.asks, idx, e)
+}
+
+// Remove снимает заявку из стакана. Возвращает false, если её там нет.
+func (b *Book) Remove(orderID int64) bool {
+	for _, side := range []*[]*Entry{&b.bids, &b.asks} {
+		for i, e := range *side {
+			if e.OrderID == orderID {
+				*side = append((*side)[:i], (*side)[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Clear очищает стакан (используется при пересборке из БД).
+func (b *Book) Clear() {
+	b.bids = nil
+	b.asks = nil
+}
+
+func crosses(taker, maker *Entry) bool {
+	if taker.Side == entities.OrderBuy {
+		return taker.Price.GreaterThanOrEqual(maker.Price)
+	}
+	return taker.Price.LessThanOrEqual(maker.Price)
+}
+
+func insertAt(entries []*Entry, idx int, e *Entry) []*Entry {
+	entries = append(entries, nil)
+	copy(entries[idx+1:], entries[idx:])
+	entries[idx] = e
+	return entries
+}
@@ -0,0 +1,309 @@
+package orderbook
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+var t0 = time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+func entry(id, user int64, side entities.OrderType, price, qty string, at int) *Entry {
+	return &Entry{
+		OrderID:   id,
+		UserID:    user,
+		Side:      side,
+		Price:     decimal.RequireFromString(price),
+		Remaining: decimal.RequireFromString(qty),
+		CreatedAt: t0.Add(time.Duration(at) * time.Second),
+	}
+}
+
+func bookOf(entries ...*Entry) *Book {
+	b := newBook(1)
+	for _, e := range entries {
+		b.Add(e)
+	}
+	return b
+}
+
+func ids(entries []*Entry) []int64 {
+	out := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.OrderID)
+	}
+	return out
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAddPriceTimePriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		entries  []*Entry
+		wantBids []int64
+		wantAsks []int64
+	}{
+		{
+			name: "bids by price desc then time",
+			entries: []*Entry{
+				entry(1, 1, entities.OrderBuy, "100", "1", 0),
+				entry(2, 2, entities.OrderBuy, "101", "1", 1),
+				entry(3, 3, entities.OrderBuy, "100", "1", 2),
+				entry(4, 4, entities.OrderBuy, "99", "1", 3),
+			},
+			wantBids: []int64{2, 1, 3, 4},
+		},
+		{
+			name: "asks by price asc then time",
+			entries: []*Entry{
+				entry(1, 1, entities.OrderSell, "101", "1", 0),
+				entry(2, 2, entities.OrderSell, "100", "1", 1),
+				entry(3, 3, entities.OrderSell, "101", "1", 2),
+				entry(4, 4, entities.OrderSell, "102", "1", 3),
+			},
+			wantAsks: []int64{2, 1, 3, 4},
+		},
+		{
+			name: "earlier order added later keeps its time priority",
+			entries: []*Entry{
+				entry(1, 1, entities.OrderBuy, "100", "1", 5),
+				entry(2, 2, entities.OrderBuy, "100", "1", 1),
+			},
+			wantBids: []int64{2, 1},
+		},
+		{
+			name: "empty remainder is not added",
+			entries: []*Entry{
+				entry(1, 1, entities.OrderBuy, "100", "0", 0),
+				entry(2, 2, entities.OrderSell, "101", "0", 0),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bookOf(tt.entries...)
+			if got := ids(b.bids); !equalIDs(got, tt.wantBids) {
+				t.Errorf("bids = %v, want %v", got, tt.wantBids)
+			}
+			if got := ids(b.asks); !equalIDs(got, tt.wantAsks) {
+				t.Errorf("asks = %v, want %v", got, tt.wantAsks)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	type fill struct {
+		maker int64
+		price string
+		qty   string
+	}
+	tests := []struct {
+		name          string
+		resting       []*Entry
+		taker         *Entry
+		wantFills     []fill
+		wantSelf      bool
+		wantRemaining string
+		wantAsks      []int64
+		wantBids      []int64
+	}{
+		{
+			name: "no cross leaves book intact",
+			resting: []*Entry{
+				entry(1, 1, entities.OrderSell, "101", "5", 0),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "5", 10),
+			wantRemaining: "5",
+			wantAsks:      []int64{1},
+		},
+		{
+			name: "full fill at maker price",
+			resting: []*Entry{
+				entry(1, 1, entities.OrderSell, "99", "5", 0),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "5", 10),
+			wantFills:     []fill{{1, "99", "5"}},
+			wantRemaining: "0",
+		},
+		{
+			name: "partial fill of maker keeps its remainder",
+			resting: []*Entry{
+				entry(1, 1, entities.OrderSell, "100", "5", 0),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "2", 10),
+			wantFills:     []fill{{1, "100", "2"}},
+			wantRemaining: "0",
+			wantAsks:      []int64{1},
+		},
+		{
+			name: "taker sweeps levels in price-time order",
+			resting: []*Entry{
+				entry(1, 1, entities.OrderSell, "101", "2", 0),
+				entry(2, 2, entities.OrderSell, "100", "1", 1),
+				entry(3, 3, entities.OrderSell, "100", "1", 2),
+				entry(4, 4, entities.OrderSell, "103", "1", 3),
+			},
+			taker: entry(10, 9, entities.OrderBuy, "101", "5", 10),
+			wantFills: []fill{
+				{2, "100", "1"},
+				{3, "100", "1"},
+				{1, "101", "2"},
+			},
+			wantRemaining: "1",
+			wantAsks:      []int64{4},
+		},
+		{
+			name: "sell taker matches best bid first",
+			resting: []*Entry{
+				entry(1, 1, entities.OrderBuy, "99", "1", 0),
+				entry(2, 2, entities.OrderBuy, "100", "1", 1),
+			},
+			taker:         entry(10, 9, entities.OrderSell, "99", "1", 10),
+			wantFills:     []fill{{2, "100", "1"}},
+			wantRemaining: "0",
+			wantBids:      []int64{1},
+		},
+		{
+			name: "self match stops before own order",
+			resting: []*Entry{
+				entry(1, 9, entities.OrderSell, "100", "1", 0),
+				entry(2, 2, entities.OrderSell, "100", "1", 1),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "2", 10),
+			wantSelf:      true,
+			wantRemaining: "2",
+			wantAsks:      []int64{1, 2},
+		},
+		{
+			name: "self match after partial fill keeps earlier fills",
+			resting: []*Entry{
+				entry(1, 2, entities.OrderSell, "99", "1", 0),
+				entry(2, 9, entities.OrderSell, "100", "1", 1),
+				entry(3, 3, entities.OrderSell, "100", "1", 2),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "3", 10),
+			wantFills:     []fill{{1, "99", "1"}},
+			wantSelf:      true,
+			wantRemaining: "2",
+			wantAsks:      []int64{2, 3},
+		},
+		{
+			name: "own order behind the limit does not count",
+			resting: []*Entry{
+				entry(1, 2, entities.OrderSell, "100", "1", 0),
+				entry(2, 9, entities.OrderSell, "105", "1", 1),
+			},
+			taker:         entry(10, 9, entities.OrderBuy, "100", "2", 10),
+			wantFills:     []fill{{1, "100", "1"}},
+			wantRemaining: "1",
+			wantAsks:      []int64{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bookOf(tt.resting...)
+			fills, self := b.Match(tt.taker)
+
+			if self != tt.wantSelf {
+				t.Errorf("selfMatch = %v, want %v", self, tt.wantSelf)
+			}
+			if len(fills) != len(tt.wantFills) {
+				t.Fatalf("got %d fills, want %d: %+v", len(fills), len(tt.wantFills), fills)
+			}
+			for i, want := range tt.wantFills {
+				got := fills[i]
+				if got.MakerOrderID != want.maker ||
+					!got.Price.Equal(decimal.RequireFromString(want.price)) ||
+					!got.Quantity.Equal(decimal.RequireFromString(want.qty)) {
+					t.Errorf("fill %d = maker %d %s x %s, want maker %d %s x %s",
+						i, got.MakerOrderID, got.Price, got.Quantity, want.maker, want.price, want.qty)
+				}
+				if got.TakerOrderID != tt.taker.OrderID || got.TakerSide != tt.taker.Side {
+					t.Errorf("fill %d has wrong taker: %+v", i, got)
+				}
+			}
+			if !tt.taker.Remaining.Equal(decimal.RequireFromString(tt.wantRemaining)) {
+				t.Errorf("taker remaining = %s, want %s", tt.taker.Remaining, tt.wantRemaining)
+			}
+			if got := ids(b.asks); !equalIDs(got, tt.wantAsks) {
+				t.Errorf("asks = %v, want %v", got, tt.wantAsks)
+			}
+			if got := ids(b.bids); !equalIDs(got, tt.wantBids) {
+				t.Errorf("bids = %v, want %v", got, tt.wantBids)
+			}
+		})
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	b := bookOf(
+		entry(1, 1, entities.OrderSell, "100", "2", 0),
+		entry(2, 9, entities.OrderSell, "101", "3", 1),
+		entry(3, 3, entities.OrderSell, "101", "4", 2),
+	)
+
+	tests := []struct {
+		name  string
+		taker *Entry
+		want  string
+	}{
+		{"up to own order", entry(10, 9, entities.OrderBuy, "101", "10", 10), "2"},
+		{"other user sees all crossing liquidity", entry(11, 5, entities.OrderBuy, "101", "10", 10), "9"},
+		{"limit below best ask", entry(12, 5, entities.OrderBuy, "99", "10", 10), "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.Available(tt.taker); !got.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("Available = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name     string
+		remove   int64
+		wantOK   bool
+		wantBids []int64
+		wantAsks []int64
+	}{
+		{"bid", 2, true, []int64{1}, []int64{3}},
+		{"ask", 3, true, []int64{1, 2}, nil},
+		{"missing", 42, false, []int64{1, 2}, []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bookOf(
+				entry(1, 1, entities.OrderBuy, "100", "1", 0),
+				entry(2, 2, entities.OrderBuy, "99", "1", 1),
+				entry(3, 3, entities.OrderSell, "101", "1", 2),
+			)
+			if ok := b.Remove(tt.remove); ok != tt.wantOK {
+				t.Errorf("Remove = %v, want %v", ok, tt.wantOK)
+			}
+			if got := ids(b.bids); !equalIDs(got, tt.wantBids) {
+				t.Errorf("bids = %v, want %v", got, tt.wantBids)
+			}
+			if got := ids(b.asks); !equalIDs(got, tt.wantAsks) {
+				t.Errorf("asks = %v, want %v", got, tt.wantAsks)
+			}
+		})
+	}
+}
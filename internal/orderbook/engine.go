@@ -0,0 +1,31 @@
+package orderbook
+
+import "sync"
+
+type Engine interface {
+	// Book возвращает стакан акции, создавая его при первом обращении.
+	Book(stockID int64) *Book
+}
+
+type engine struct {
+	mu    sync.Mutex
+	books map[int64]*Book
+}
+
+func NewEngine() Engine {
+	return &engine{
+		books: make(map[int64]*Book),
+	}
+}
+
+func (e *engine) Book(stockID int64) *Book {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	b, ok := e.books[stockID]
+	if !ok {
+		b = newBook(stockID)
+		e.books[stockID] = b
+	}
+	return b
+}
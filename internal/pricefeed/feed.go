@@ -0,0 +1,57 @@
+package pricefeed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Skapar/backend/config"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	SourceSimulator = "simulator"
+	SourceReplay    = "replay"
+)
+
+// Feed — источник рыночных цен. Next вызывается раз в интервал фида и возвращает
+// новые цены по ID акции; акции, цена которых не изменилась, можно не включать.
+type Feed interface {
+	Next(stocks []*entities.Stock) map[int64]decimal.Decimal
+}
+
+// New собирает фид по конфигу.
+func New(cfg *config.Config) (Feed, error) {
+	switch cfg.PriceFeedSource {
+	case SourceSimulator, "":
+		params, err := ParseParams(cfg.PriceFeedParams)
+		if err != nil {
+			return nil, err
+		}
+		return NewSimulator(&SimulatorConfig{
+			Seed:       cfg.PriceFeedSeed,
+			Interval:   time.Duration(cfg.PriceFeedIntervalSeconds) * time.Second,
+			Drift:      cfg.PriceFeedDrift,
+			Volatility: cfg.PriceFeedVolatility,
+			Params:     params,
+		}), nil
+	case SourceReplay:
+		return NewReplayFromFile(cfg.PriceFeedReplayFile, cfg.PriceFeedReplayLoop)
+	}
+	return nil, fmt.Errorf("unknown price feed source %q", cfg.PriceFeedSource)
+}
+
+// roundToTick приводит цену к шагу акции и не даёт ей стать меньше одного шага.
+func roundToTick(price float64, stock *entities.Stock) decimal.Decimal {
+	p := decimal.NewFromFloat(price)
+	tick := stock.TickSize
+	if !tick.IsPositive() {
+		return p.Round(entities.Scale)
+	}
+
+	p = p.Div(tick).Round(0).Mul(tick)
+	if p.LessThan(tick) {
+		return tick
+	}
+	return p
+}
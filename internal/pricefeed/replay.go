@@ -0,0 +1,105 @@
+package pricefeed
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+// Replay проигрывает записанные тики: за один вызов Next — все строки с одной
+// отметкой времени. Формат CSV: timestamp,symbol,price; строка-заголовок допускается.
+type Replay struct {
+	frames [][]replayTick
+	pos    int
+	loop   bool
+}
+
+type replayTick struct {
+	symbol string
+	price  decimal.Decimal
+}
+
+func NewReplayFromFile(path string, loop bool) (*Replay, error) {
+	if path == "" {
+		return nil, fmt.Errorf("price feed replay: PRICE_FEED_REPLAY_FILE is not set")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("price feed replay: %w", err)
+	}
+	defer f.Close()
+
+	return NewReplay(f, loop)
+}
+
+func NewReplay(r io.Reader, loop bool) (*Replay, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 3
+	cr.TrimLeadingSpace = true
+
+	var (
+		frames [][]replayTick
+		lastTS string
+	)
+	for line := 1; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("price feed replay: %w", err)
+		}
+
+		price, err := decimal.NewFromString(rec[2])
+		if err != nil {
+			if line == 1 {
+				continue // заголовок
+			}
+			return nil, fmt.Errorf("price feed replay: line %d: bad price %q", line, rec[2])
+		}
+		if !price.IsPositive() {
+			return nil, fmt.Errorf("price feed replay: line %d: price must be positive", line)
+		}
+
+		tick := replayTick{symbol: strings.ToUpper(rec[1]), price: price}
+		if len(frames) == 0 || rec[0] != lastTS {
+			frames = append(frames, nil)
+			lastTS = rec[0]
+		}
+		frames[len(frames)-1] = append(frames[len(frames)-1], tick)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("price feed replay: no ticks")
+	}
+
+	return &Replay{frames: frames, loop: loop}, nil
+}
+
+func (r *Replay) Next(stocks []*entities.Stock) map[int64]decimal.Decimal {
+	if r.pos >= len(r.frames) {
+		if !r.loop {
+			return nil
+		}
+		r.pos = 0
+	}
+	frame := r.frames[r.pos]
+	r.pos++
+
+	bySymbol := make(map[string]*entities.Stock, len(stocks))
+	for _, st := range stocks {
+		bySymbol[strings.ToUpper(st.Symbol)] = st
+	}
+
+	out := make(map[int64]decimal.Decimal, len(frame))
+	for _, t := range frame {
+		if st, ok := bySymbol[t.symbol]; ok {
+			out[st.ID] = t.price
+		}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package pricefeed
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+// year — в каких единицах заданы drift и volatility.
+const year = 365 * 24 * time.Hour
+
+// Params — годовые drift (μ) и volatility (σ) одной акции.
+type Params struct {
+	Drift      float64
+	Volatility float64
+}
+
+type SimulatorConfig struct {
+	Seed       int64
+	Interval   time.Duration
+	Drift      float64 // по умолчанию для акций без своих параметров
+	Volatility float64
+	Params     map[string]Params // по тикеру
+}
+
+// Simulator двигает цены геометрическим броуновским движением:
+// S' = S·exp((μ − σ²/2)·dt + σ·√dt·Z), Z ~ N(0, 1).
+// С одинаковым seed и набором акций последовательность цен повторяется.
+type Simulator struct {
+	rnd    *rand.Rand
+	dt     float64
+	cfg    *SimulatorConfig
+	state  map[int64]float64         // неокруглённая цена, чтобы мелкие шаги не съедались тиком
+	posted map[int64]decimal.Decimal // последняя выданная цена
+}
+
+func NewSimulator(cfg *SimulatorConfig) *Simulator {
+	return &Simulator{
+		rnd:    rand.New(rand.NewSource(cfg.Seed)),
+		dt:     float64(cfg.Interval) / float64(year),
+		cfg:    cfg,
+		state:  make(map[int64]float64),
+		posted: make(map[int64]decimal.Decimal),
+	}
+}
+
+func (s *Simulator) Next(stocks []*entities.Stock) map[int64]decimal.Decimal {
+	out := make(map[int64]decimal.Decimal, len(stocks))
+
+	// порядок вызовов генератора должен быть стабильным
+	ordered := append([]*entities.Stock(nil), stocks...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].ID < ordered[j].ID })
+
+	for _, st := range ordered {
+		// цену поменяли в обход фида (админ) — продолжаем от неё
+		if posted, ok := s.posted[st.ID]; !ok || !posted.Equal(st.Price) {
+			s.state[st.ID] = st.Price.InexactFloat64()
+		}
+
+		p := s.params(st.Symbol)
+		z := s.rnd.NormFloat64()
+		next := s.state[st.ID] * math.Exp((p.Drift-p.Volatility*p.Volatility/2)*s.dt+p.Volatility*math.Sqrt(s.dt)*z)
+		s.state[st.ID] = next
+
+		price := roundToTick(next, st)
+		s.posted[st.ID] = price
+		if !price.Equal(st.Price) {
+			out[st.ID] = price
+		}
+	}
+	return out
+}
+
+func (s *Simulator) params(symbol string) Params {
+	if p, ok := s.cfg.Params[strings.ToUpper(symbol)]; ok {
+		return p
+	}
+	return Params{Drift: s.cfg.Drift, Volatility: s.cfg.Volatility}
+}
+
+// ParseParams разбирает параметры по тикерам вида "AAPL:0.08:0.25,TSLA:0.1:0.6".
+func ParseParams(raw string) (map[string]Params, error) {
+	out := make(map[string]Params)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("price feed params %q: want SYMBOL:drift:volatility", item)
+		}
+		drift, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("price feed params %q: drift: %w", item, err)
+		}
+		vol, err := strconv.ParseFloat(parts[2], 64)
+		if err != nil || vol < 0 {
+			return nil, fmt.Errorf("price feed params %q: volatility must be a non-negative number", item)
+		}
+		out[strings.ToUpper(parts[0])] = Params{Drift: drift, Volatility: vol}
+	}
+	return out, nil
+}
@@ -2,38 +2,148 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
 )
 
 type PGRepository interface {
 	// User
-	CreateUser(ctx context.Context, user *entities.User) (int64, error)
 	GetUserByID(ctx context.Context, id int64) (*entities.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*entities.User, error)
-	UpdateUser(ctx context.Context, user *entities.User) error
-	DeleteUser(ctx context.Context, id int64) error
+	UpdateCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error
 	GetAllUsers(ctx context.Context) ([]*entities.User, error)
 
 	// --- Stock ---
-	CreateStock(ctx context.Context, stock *entities.Stock) (int64, error)
 	GetStockByID(ctx context.Context, id int64) (*entities.Stock, error)
 	GetAllStocks(ctx context.Context) ([]*entities.Stock, error)
-	UpdateStock(ctx context.Context, stock *entities.Stock) error
-	DeleteStock(ctx context.Context, id int64) error
+
+	// --- Price history ---
+	GetCandles(ctx context.Context, stockID int64, width time.Duration, from, to time.Time) ([]*entities.Candle, error)
 
 	// --- Orders ---
 	CreateOrder(ctx context.Context, order *entities.Order) (int64, error)
-	UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error
 	GetOrdersByUserID(ctx context.Context, userID int64) ([]*entities.Order, error)
 	GetOrderByID(ctx context.Context, orderID int64) (*entities.Order, error)
+	GetOpenLimitOrders(ctx context.Context) ([]*entities.Order, error)
+	GetDormantStopOrders(ctx context.Context) ([]*entities.Order, error)
+	GetExpiredOrders(ctx context.Context, now time.Time) ([]*entities.Order, error)
+	ActivateStopOrder(ctx context.Context, orderID int64, kind entities.OrderKind) (bool, error)
+	UpdateTriggerPrice(ctx context.Context, orderID int64, triggerPrice decimal.Decimal) error
+
+	// --- Trades ---
+	CreateTrade(ctx context.Context, t *entities.Trade) (int64, error)
 
 	// --- Portfolio ---
 	GetPortfolio(ctx context.Context, userID, stockID int64) (*entities.Portfolio, error)
-	CreateOrUpdatePortfolio(ctx context.Context, portfolio *entities.Portfolio) error
 	GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*entities.Portfolio, error)
 
 	// --- History ---
 	AddHistoryRecord(ctx context.Context, h *entities.History) (int64, error)
 	GetHistoryByUserID(ctx context.Context, userID int64) ([]*entities.History, error)
+
+	// --- Tax lots ---
+	GetOpenTaxLotsByUserID(ctx context.Context, userID int64) ([]*entities.TaxLot, error)
+
+	// --- Ledger ---
+	GetLedgerEntriesByAccount(ctx context.Context, code string) ([]*entities.LedgerEntry, error)
+	GetBalanceMismatches(ctx context.Context) ([]*entities.BalanceMismatch, error)
+
+	// --- Idempotency ---
+	InsertIdempotencyKey(ctx context.Context, rec *entities.IdempotencyRecord) (bool, error)
+	GetIdempotencyKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error)
+	CompleteIdempotencyKey(ctx context.Context, rec *entities.IdempotencyRecord) (bool, error)
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+
+	// --- Tokens ---
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeRefreshTokenFamilyByHash(ctx context.Context, userID int64, tokenHash string) error
+	AddRevokedToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
+
+	// --- RBAC ---
+	GetRoles(ctx context.Context) ([]*entities.RoleInfo, error)
+	GetPermissions(ctx context.Context) ([]*entities.PermissionInfo, error)
+	GetRolePermissions(ctx context.Context) ([]*entities.RolePermission, error)
+
+	// --- API keys ---
+	GetAPIKeysByUserID(ctx context.Context, userID int64) ([]*entities.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	CountActiveAPIKeys(ctx context.Context, userID int64) (int64, error)
+	TouchAPIKey(ctx context.Context, id int64) error
+
+	// --- Two-factor ---
+	GetUserTOTP(ctx context.Context, userID int64) (*entities.UserTOTP, error)
+	UpsertPendingUserTOTP(ctx context.Context, userID int64, secretEnc []byte) (bool, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+	ResetTOTPLockout(ctx context.Context, userID int64) error
+
+	// --- Email tokens ---
+	CreateUserToken(ctx context.Context, t *entities.UserToken) error
+
+	// --- Invites ---
+	GetInvites(ctx context.Context) ([]*entities.Invite, error)
+
+	// --- Audit log ---
+	GetAuditEntries(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error)
+	GetAuditEntriesAfter(ctx context.Context, afterID int64, limit int) ([]*entities.AuditEntry, error)
+
+	// --- Transactional variants ---
+	BeginTx(ctx context.Context) (database.Transaction, error)
+	GetUserForUpdateTx(ctx context.Context, tx database.Transaction, id int64) (*entities.User, error)
+	UpdateUserTx(ctx context.Context, tx database.Transaction, user *entities.User) error
+	DeleteUserTx(ctx context.Context, tx database.Transaction, id int64) error
+	CreateStockTx(ctx context.Context, tx database.Transaction, stock *entities.Stock) (int64, error)
+	UpdateStockTx(ctx context.Context, tx database.Transaction, stock *entities.Stock) error
+	DeleteStockTx(ctx context.Context, tx database.Transaction, id int64) error
+	AddPriceTickTx(ctx context.Context, tx database.Transaction, stockID int64, price decimal.Decimal) error
+	CompactPriceTicksTx(ctx context.Context, tx database.Transaction, until time.Time) (int64, error)
+	DebitBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error
+	CreditBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error
+	AddPortfolioQuantityTx(ctx context.Context, tx database.Transaction, userID, stockID int64, delta decimal.Decimal) error
+	GetOrderForUpdateTx(ctx context.Context, tx database.Transaction, orderID int64) (*entities.Order, error)
+	UpdateOrderFillTx(ctx context.Context, tx database.Transaction, orderID int64, filledQty, avgPrice decimal.Decimal, from, to entities.OrderStatus) error
+	TransitionOrderStatusTx(ctx context.Context, tx database.Transaction, orderID int64, from, to entities.OrderStatus) error
+	CreateTradeTx(ctx context.Context, tx database.Transaction, t *entities.Trade) (int64, error)
+	AddHistoryRecordTx(ctx context.Context, tx database.Transaction, h *entities.History) (int64, error)
+	PostJournalTx(ctx context.Context, tx database.Transaction, j *entities.LedgerJournal, legs map[*entities.LedgerAccount]decimal.Decimal) (int64, error)
+
+	ReserveBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error
+	ReleaseBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error
+	LockSharesTx(ctx context.Context, tx database.Transaction, userID, stockID int64, qty decimal.Decimal) error
+	UnlockSharesTx(ctx context.Context, tx database.Transaction, userID, stockID int64, qty decimal.Decimal) error
+	CreateTaxLotTx(ctx context.Context, tx database.Transaction, lot *entities.TaxLot) (int64, error)
+	GetOpenTaxLotsForUpdateTx(ctx context.Context, tx database.Transaction, userID, stockID int64, newestFirst bool) ([]*entities.TaxLot, error)
+	UpdateTaxLotTx(ctx context.Context, tx database.Transaction, lotID int64, remaining, price decimal.Decimal) error
+	CreateHoldTx(ctx context.Context, tx database.Transaction, h *entities.Hold) (int64, error)
+	GetHoldForUpdateTx(ctx context.Context, tx database.Transaction, orderID int64) (*entities.Hold, error)
+	UpdateHoldTx(ctx context.Context, tx database.Transaction, holdID int64, remaining decimal.Decimal, status entities.HoldStatus) error
+	CreateRefreshTokenTx(ctx context.Context, tx database.Transaction, t *entities.RefreshToken) (int64, error)
+	GetRefreshTokenForUpdateTx(ctx context.Context, tx database.Transaction, tokenHash string) (*entities.RefreshToken, error)
+	MarkRefreshTokenUsedTx(ctx context.Context, tx database.Transaction, id int64) error
+	UpsertRoleTx(ctx context.Context, tx database.Transaction, role *entities.RoleInfo) error
+	ReplaceRolePermissionsTx(ctx context.Context, tx database.Transaction, role entities.Role, perms []entities.Permission) error
+	GetUserTOTPForUpdateTx(ctx context.Context, tx database.Transaction, userID int64) (*entities.UserTOTP, error)
+	RecordTOTPSuccessTx(ctx context.Context, tx database.Transaction, userID, step int64, enable bool) error
+	RecordTOTPFailureTx(ctx context.Context, tx database.Transaction, userID int64, maxAttempts int, lockout time.Duration) error
+	DeleteUserTOTPTx(ctx context.Context, tx database.Transaction, userID int64) error
+	ReplaceRecoveryCodesTx(ctx context.Context, tx database.Transaction, userID int64, hashes []string) error
+	UseRecoveryCodeTx(ctx context.Context, tx database.Transaction, userID int64, codeHash string) (bool, error)
+	ConsumeUserTokenTx(ctx context.Context, tx database.Transaction, jti, purpose string) (*entities.UserToken, error)
+	MarkEmailVerifiedTx(ctx context.Context, tx database.Transaction, userID int64) error
+	UpdatePasswordTx(ctx context.Context, tx database.Transaction, userID int64, passwordHash string) error
+	RevokeUserRefreshTokensTx(ctx context.Context, tx database.Transaction, userID int64) error
+	ConsumeInviteTx(ctx context.Context, tx database.Transaction, codeHash string) (*entities.Invite, error)
+	SetInviteUserTx(ctx context.Context, tx database.Transaction, inviteID, userID int64) error
+	CreateUserTx(ctx context.Context, tx database.Transaction, user *entities.User) (int64, error)
+	CreateInviteTx(ctx context.Context, tx database.Transaction, inv *entities.Invite) (int64, error)
+	RevokeInviteTx(ctx context.Context, tx database.Transaction, id int64) (bool, error)
+	CreateFirstAdminTx(ctx context.Context, tx database.Transaction, user *entities.User) (int64, bool, error)
+	CreateAPIKeyTx(ctx context.Context, tx database.Transaction, k *entities.APIKey) (int64, error)
+	RevokeAPIKeyTx(ctx context.Context, tx database.Transaction, userID, id int64) (bool, error)
+	LockAuditChainTx(ctx context.Context, tx database.Transaction) (string, error)
+	InsertAuditEntryTx(ctx context.Context, tx database.Transaction, e *entities.AuditEntry) (int64, error)
 }
@@ -7,10 +7,21 @@ import (
 	"github.com/Skapar/backend/internal/models/entities"
 	"github.com/Skapar/backend/pkg/database"
 	"github.com/Skapar/backend/pkg/logger"
-	"github.com/jackc/pgx/v4"
+	"github.com/georgysavva/scany/pgxscan"
 	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 )
 
+// executor — общее подмножество database.IDatabase и database.Transaction,
+// чтобы один и тот же запрос можно было выполнить как в транзакции, так и без неё.
+type executor interface {
+	Get(ctx context.Context, returnValue interface{}, sql string, args ...interface{}) error
+	GetOne(ctx context.Context, returnValue interface{}, sql string, args ...interface{}) error
+	Insert(ctx context.Context, returnValue interface{}, sql string, args ...interface{}) error
+	Update(ctx context.Context, returnValue interface{}, sql string, args ...interface{}) error
+	Delete(ctx context.Context, returnValue interface{}, sql string, args ...interface{}) error
+}
+
 type pgRepository struct {
 	DB  database.IDatabase
 	log logger.Logger
@@ -23,23 +34,9 @@ func NewPGRepository(db database.IDatabase, log logger.Logger) PGRepository {
 	}
 }
 
-func (r *pgRepository) CreateUser(ctx context.Context, user *entities.User) (int64, error) {
-	q := `
-		INSERT INTO stock_user (email, password, role, balance)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id;
-	`
-
-	var id int64
-	if err := r.DB.Insert(ctx, &id, q, user.Email, user.Password, user.Role, user.Balance); err != nil {
-		return 0, errors.Wrap(err, "CreateUser: failed to create user")
-	}
-	return id, nil
-}
-
 func (r *pgRepository) GetUserByID(ctx context.Context, id int64) (*entities.User, error) {
 	q := `
-		SELECT id, email, password, role, balance::float8, created_at
+		SELECT id, email, password, role, balance, reserved_balance, cost_basis_method, email_verified_at, created_at
 		FROM stock_user
 		WHERE id = $1;
 	`
@@ -54,7 +51,7 @@ func (r *pgRepository) GetUserByID(ctx context.Context, id int64) (*entities.Use
 
 func (r *pgRepository) GetUserByEmail(ctx context.Context, email string) (*entities.User, error) {
 	q := `
-        SELECT id, email, password, role, balance, created_at
+        SELECT id, email, password, role, balance, reserved_balance, cost_basis_method, email_verified_at, created_at
         FROM stock_user
         WHERE email = $1;
     `
@@ -66,35 +63,68 @@ func (r *pgRepository) GetUserByEmail(ctx context.Context, email string) (*entit
 	return &user, nil
 }
 
-func (r *pgRepository) UpdateUser(ctx context.Context, user *entities.User) error {
+// GetUserForUpdateTx блокирует строку пользователя до конца транзакции.
+func (r *pgRepository) GetUserForUpdateTx(ctx context.Context, tx database.Transaction, id int64) (*entities.User, error) {
+	q := `
+		SELECT id, email, password, role, balance, reserved_balance, cost_basis_method, email_verified_at, created_at
+		FROM stock_user
+		WHERE id = $1
+		FOR UPDATE;
+	`
+
+	var user entities.User
+	if err := tx.GetOne(ctx, &user, q, id); err != nil {
+		return nil, errors.Wrap(err, "GetUserForUpdateTx: failed to get user")
+	}
+	return &user, nil
+}
+
+func (r *pgRepository) UpdateUserTx(ctx context.Context, tx database.Transaction, user *entities.User) error {
 	q := `
 		UPDATE stock_user
 		SET email = $1,
 			password = $2,
 			role = $3,
-			balance = $4
-		WHERE id = $5
+			-- новый адрес нужно подтвердить заново
+			email_verified_at = CASE WHEN email = $1 THEN email_verified_at END
+		WHERE id = $4
+		RETURNING id;
+	`
+
+	// balance меняется только через леджер (см. service.transferTx)
+	var updatedID int64
+	if err := tx.Update(ctx, &updatedID, q, user.Email, user.Password, user.Role, user.ID); err != nil {
+		return errors.Wrap(err, "UpdateUserTx: failed to update user")
+	}
+	return nil
+}
+
+func (r *pgRepository) UpdateCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error {
+	q := `
+		UPDATE stock_user
+		SET cost_basis_method = $1
+		WHERE id = $2
 		RETURNING id;
 	`
 
 	var updatedID int64
-	if err := r.DB.Update(ctx, &updatedID, q, user.Email, user.Password, user.Role, user.Balance, user.ID); err != nil {
-		return errors.Wrap(err, "UpdateUser: failed to update user")
+	if err := r.DB.Update(ctx, &updatedID, q, method, userID); err != nil {
+		return errors.Wrap(err, "UpdateCostBasisMethod failed")
 	}
 	return nil
 }
 
-func (r *pgRepository) DeleteUser(ctx context.Context, id int64) error {
+func (r *pgRepository) DeleteUserTx(ctx context.Context, tx database.Transaction, id int64) error {
 	q := `DELETE FROM stock_user WHERE id = $1;`
-	if err := r.DB.Delete(ctx, nil, q, id); err != nil {
-		return errors.Wrapf(err, "DeleteUser: failed to delete user")
+	if err := tx.Delete(ctx, nil, q, id); err != nil {
+		return errors.Wrapf(err, "DeleteUserTx: failed to delete user")
 	}
 	return nil
 }
 
 func (r *pgRepository) GetAllUsers(ctx context.Context) ([]*entities.User, error) {
 	q := `
-		SELECT id, email, password, role, balance, created_at
+		SELECT id, email, password, role, balance, reserved_balance, cost_basis_method, email_verified_at, created_at
 		FROM stock_user
 		ORDER BY id DESC;
 	`
@@ -106,23 +136,23 @@ func (r *pgRepository) GetAllUsers(ctx context.Context) ([]*entities.User, error
 	return users, nil
 }
 
-func (r *pgRepository) CreateStock(ctx context.Context, stock *entities.Stock) (int64, error) {
+func (r *pgRepository) CreateStockTx(ctx context.Context, tx database.Transaction, stock *entities.Stock) (int64, error) {
 	q := `
-		INSERT INTO stock_stock (symbol, name, price, updated_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO stock_stock (symbol, name, price, tick_size, lot_size, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id;
 	`
 
 	var id int64
-	if err := r.DB.Insert(ctx, &id, q, stock.Symbol, stock.Name, stock.Price, time.Now()); err != nil {
-		return 0, errors.Wrap(err, "CreateStock: failed to create stock")
+	if err := tx.Insert(ctx, &id, q, stock.Symbol, stock.Name, stock.Price, stock.TickSize, stock.LotSize, time.Now()); err != nil {
+		return 0, errors.Wrap(err, "CreateStockTx: failed to create stock")
 	}
 	return id, nil
 }
 
 func (r *pgRepository) GetStockByID(ctx context.Context, id int64) (*entities.Stock, error) {
 	q := `
-		SELECT id, symbol, name, price, updated_at
+		SELECT id, symbol, name, price, tick_size, lot_size, updated_at
 		FROM stock_stock
 		WHERE id = $1;
 	`
@@ -136,7 +166,7 @@ func (r *pgRepository) GetStockByID(ctx context.Context, id int64) (*entities.St
 
 func (r *pgRepository) GetAllStocks(ctx context.Context) ([]*entities.Stock, error) {
 	q := `
-		SELECT id, symbol, name, price, updated_at
+		SELECT id, symbol, name, price, tick_size, lot_size, updated_at
 		FROM stock_stock
 		ORDER BY id DESC;
 	`
@@ -148,62 +178,56 @@ func (r *pgRepository) GetAllStocks(ctx context.Context) ([]*entities.Stock, err
 	return stocks, nil
 }
 
-func (r *pgRepository) UpdateStock(ctx context.Context, stock *entities.Stock) error {
+func (r *pgRepository) UpdateStockTx(ctx context.Context, tx database.Transaction, stock *entities.Stock) error {
 	q := `
 		UPDATE stock_stock
 		SET symbol = $1,
 			name = $2,
 			price = $3,
-			updated_at = $4
-		WHERE id = $5
+			tick_size = $4,
+			lot_size = $5,
+			updated_at = $6
+		WHERE id = $7
 		RETURNING id;
 	`
 
 	var updatedID int64
-	if err := r.DB.Update(ctx, &updatedID, q, stock.Symbol, stock.Name, stock.Price, time.Now(), stock.ID); err != nil {
-		return errors.Wrap(err, "UpdateStock: failed to update stock")
+	if err := tx.Update(ctx, &updatedID, q, stock.Symbol, stock.Name, stock.Price, stock.TickSize, stock.LotSize, time.Now(), stock.ID); err != nil {
+		return errors.Wrap(err, "UpdateStockTx: failed to update stock")
 	}
 	return nil
 }
 
-func (r *pgRepository) DeleteStock(ctx context.Context, id int64) error {
+func (r *pgRepository) DeleteStockTx(ctx context.Context, tx database.Transaction, id int64) error {
 	q := `DELETE FROM stock_stock WHERE id = $1;`
-	if err := r.DB.Delete(ctx, nil, q, id); err != nil {
-		return errors.Wrapf(err, "DeleteStock: failed to delete stock")
+	if err := tx.Delete(ctx, nil, q, id); err != nil {
+		return errors.Wrapf(err, "DeleteStockTx: failed to delete stock")
 	}
 	return nil
 }
 
+const orderColumns = `id, user_id, stock_id, order_type, kind, quantity, price, limit_price,
+		trigger_price, trailing_offset, activated_at, time_in_force, expires_at,
+		filled_quantity, avg_fill_price, status, created_at, updated_at`
+
 func (r *pgRepository) CreateOrder(ctx context.Context, order *entities.Order) (int64, error) {
 	q := `
-		INSERT INTO stock_order (user_id, stock_id, order_type, quantity, price, status, created_at, updated_at)
-		VALUES ($1,$2,$3,$4,$5,$6,NOW(),NOW())
+		INSERT INTO stock_order (user_id, stock_id, order_type, kind, quantity, price, limit_price, trigger_price, trailing_offset,
+		                         time_in_force, expires_at, status, created_at, updated_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,NOW(),NOW())
 		RETURNING id
 	`
 	var id int64
-	if err := r.DB.Insert(ctx, &id, q, order.UserID, order.StockID, order.OrderType, order.Quantity, order.Price, order.Status); err != nil {
+	if err := r.DB.Insert(ctx, &id, q, order.UserID, order.StockID, order.OrderType, order.Kind, order.Quantity, order.Price,
+		order.LimitPrice, order.TriggerPrice, order.TrailingOffset, order.TimeInForce, order.ExpiresAt, order.Status); err != nil {
 		return 0, errors.Wrap(err, "CreateOrder failed")
 	}
 	return id, nil
 }
 
-func (r *pgRepository) UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error {
-	q := `
-		UPDATE stock_order
-		SET status = $1, updated_at = NOW()
-		WHERE id = $2
-		RETURNING id
-	`
-	var id int64
-	if err := r.DB.Update(ctx, &id, q, status, orderID); err != nil {
-		return errors.Wrap(err, "UpdateOrderStatus failed")
-	}
-	return nil
-}
-
 func (r *pgRepository) GetOrdersByUserID(ctx context.Context, userID int64) ([]*entities.Order, error) {
 	q := `
-		SELECT id, user_id, stock_id, order_type, quantity, price, status, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM stock_order
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -217,7 +241,7 @@ func (r *pgRepository) GetOrdersByUserID(ctx context.Context, userID int64) ([]*
 
 func (r *pgRepository) GetOrderByID(ctx context.Context, orderID int64) (*entities.Order, error) {
 	q := `
-		SELECT id, user_id, stock_id, order_type, quantity, price, status, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM stock_order
 		WHERE id = $1
 	`
@@ -228,9 +252,144 @@ func (r *pgRepository) GetOrderByID(ctx context.Context, orderID int64) (*entiti
 	return &order, nil
 }
 
+// UpdateOrderFillTx обновляет исполнение заявки, если её статус всё ещё from.
+func (r *pgRepository) UpdateOrderFillTx(ctx context.Context, tx database.Transaction, orderID int64, filledQty, avgPrice decimal.Decimal, from, to entities.OrderStatus) error {
+	q := `
+		UPDATE stock_order
+		SET filled_quantity = $1, avg_fill_price = $2, status = $3, updated_at = NOW()
+		WHERE id = $4 AND status = $5
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, filledQty, avgPrice, to, orderID, from); err != nil {
+		if pgxscan.NotFound(err) {
+			return &entities.OrderTransitionError{From: from, To: to}
+		}
+		return errors.Wrap(err, "UpdateOrderFillTx failed")
+	}
+	return nil
+}
+
+// TransitionOrderStatusTx меняет статус заявки, если он всё ещё from.
+func (r *pgRepository) TransitionOrderStatusTx(ctx context.Context, tx database.Transaction, orderID int64, from, to entities.OrderStatus) error {
+	q := `
+		UPDATE stock_order
+		SET status = $1, updated_at = NOW()
+		WHERE id = $2 AND status = $3
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, to, orderID, from); err != nil {
+		if pgxscan.NotFound(err) {
+			return &entities.OrderTransitionError{From: from, To: to}
+		}
+		return errors.Wrap(err, "TransitionOrderStatusTx failed")
+	}
+	return nil
+}
+
+// GetOpenLimitOrders возвращает лимитные заявки, которые должны стоять в стакане.
+func (r *pgRepository) GetOpenLimitOrders(ctx context.Context) ([]*entities.Order, error) {
+	q := `
+		SELECT ` + orderColumns + `
+		FROM stock_order
+		WHERE kind = $1 AND status IN ($2, $3)
+		ORDER BY created_at, id
+	`
+	var orders []*entities.Order
+	if err := r.DB.Get(ctx, &orders, q, entities.OrderLimit, entities.OrderNew, entities.OrderPartiallyFilled); err != nil {
+		return nil, errors.Wrap(err, "GetOpenLimitOrders failed")
+	}
+	return orders, nil
+}
+
+func (r *pgRepository) CreateTrade(ctx context.Context, t *entities.Trade) (int64, error) {
+	return r.createTrade(ctx, r.DB, t)
+}
+
+func (r *pgRepository) CreateTradeTx(ctx context.Context, tx database.Transaction, t *entities.Trade) (int64, error) {
+	return r.createTrade(ctx, tx, t)
+}
+
+func (r *pgRepository) createTrade(ctx context.Context, db executor, t *entities.Trade) (int64, error) {
+	q := `
+		INSERT INTO stock_trade (stock_id, buy_order_id, sell_order_id, buyer_id, seller_id, price, quantity, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NOW())
+		RETURNING id
+	`
+	var id int64
+	if err := db.Insert(ctx, &id, q, t.StockID, t.BuyOrderID, t.SellOrderID, t.BuyerID, t.SellerID, t.Price, t.Quantity); err != nil {
+		return 0, errors.Wrap(err, "CreateTrade failed")
+	}
+	return id, nil
+}
+
+// GetDormantStopOrders возвращает условные заявки, которые ещё не сработали.
+func (r *pgRepository) GetDormantStopOrders(ctx context.Context) ([]*entities.Order, error) {
+	q := `
+		SELECT ` + orderColumns + `
+		FROM stock_order
+		WHERE kind IN ($1, $2, $3) AND activated_at IS NULL AND status = $4
+		ORDER BY created_at, id
+	`
+	var orders []*entities.Order
+	if err := r.DB.Get(ctx, &orders, q, entities.OrderStop, entities.OrderStopLimit, entities.OrderTrailingStop, entities.OrderNew); err != nil {
+		return nil, errors.Wrap(err, "GetDormantStopOrders failed")
+	}
+	return orders, nil
+}
+
+// GetExpiredOrders возвращает открытые заявки с истёкшим expires_at.
+func (r *pgRepository) GetExpiredOrders(ctx context.Context, now time.Time) ([]*entities.Order, error) {
+	q := `
+		SELECT ` + orderColumns + `
+		FROM stock_order
+		WHERE expires_at <= $1 AND status IN ($2, $3)
+		ORDER BY expires_at, id
+	`
+	var orders []*entities.Order
+	if err := r.DB.Get(ctx, &orders, q, now, entities.OrderNew, entities.OrderPartiallyFilled); err != nil {
+		return nil, errors.Wrap(err, "GetExpiredOrders failed")
+	}
+	return orders, nil
+}
+
+// ActivateStopOrder переводит сработавшую условную заявку в kind. Возвращает false,
+// если заявку уже активировали или закрыли параллельно.
+func (r *pgRepository) ActivateStopOrder(ctx context.Context, orderID int64, kind entities.OrderKind) (bool, error) {
+	q := `
+		UPDATE stock_order
+		SET kind = $1, activated_at = NOW(), updated_at = NOW()
+		WHERE id = $2 AND activated_at IS NULL AND status = $3
+		RETURNING id
+	`
+	var id int64
+	if err := r.DB.Update(ctx, &id, q, kind, orderID, entities.OrderNew); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "ActivateStopOrder failed")
+	}
+	return true, nil
+}
+
+func (r *pgRepository) UpdateTriggerPrice(ctx context.Context, orderID int64, triggerPrice decimal.Decimal) error {
+	q := `
+		UPDATE stock_order
+		SET trigger_price = $1, updated_at = NOW()
+		WHERE id = $2 AND activated_at IS NULL
+		RETURNING id
+	`
+	var id int64
+	if err := r.DB.Update(ctx, &id, q, triggerPrice, orderID); err != nil && !pgxscan.NotFound(err) {
+		return errors.Wrap(err, "UpdateTriggerPrice failed")
+	}
+	return nil
+}
+
 func (r *pgRepository) GetPortfolio(ctx context.Context, userID, stockID int64) (*entities.Portfolio, error) {
 	q := `
-		SELECT id, user_id, stock_id, quantity, version, updated_at
+		SELECT id, user_id, stock_id, quantity, locked_quantity, quantity - locked_quantity AS available_quantity, version, updated_at
 		FROM stock_portfolio
 		WHERE user_id = $1 AND stock_id = $2
 	`
@@ -246,45 +405,9 @@ func (r *pgRepository) GetPortfolio(ctx context.Context, userID, stockID int64)
 	return &p, nil
 }
 
-// CreateOrUpdatePortfolio без транзакции, с оптимистической блокировкой
-func (r *pgRepository) CreateOrUpdatePortfolio(ctx context.Context, portfolio *entities.Portfolio) error {
-	existing := &entities.Portfolio{}
-	qSelect := `SELECT id, quantity, version FROM stock_portfolio WHERE user_id=$1 AND stock_id=$2`
-
-	err := r.DB.GetOne(ctx, existing, qSelect, portfolio.UserID, portfolio.StockID)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			// вставляем новую запись
-			qInsert := `INSERT INTO stock_portfolio (user_id, stock_id, quantity, version, updated_at)
-			            VALUES ($1,$2,$3,1,NOW()) RETURNING id`
-			var id int64
-			if err := r.DB.Insert(ctx, &id, qInsert, portfolio.UserID, portfolio.StockID, portfolio.Quantity); err != nil {
-				return errors.Wrap(err, "CreateOrUpdatePortfolio: insert failed")
-			}
-			return nil
-		}
-		return errors.Wrap(err, "CreateOrUpdatePortfolio: select failed")
-	}
-
-	// update с оптимистической блокировкой
-	qUpdate := `UPDATE stock_portfolio
-	            SET quantity = $1, version = version+1, updated_at=NOW()
-	            WHERE id=$2 AND version=$3 RETURNING id`
-	var updatedID int64
-	if err := r.DB.Update(ctx, &updatedID, qUpdate, existing.Quantity+portfolio.Quantity, existing.ID, existing.Version); err != nil {
-		return errors.Wrap(err, "CreateOrUpdatePortfolio: update failed")
-	}
-
-	if updatedID == 0 {
-		return errors.New("CreateOrUpdatePortfolio: optimistic lock failed")
-	}
-
-	return nil
-}
-
 func (r *pgRepository) GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*entities.Portfolio, error) {
 	q := `
-		SELECT id, user_id, stock_id, quantity, version, updated_at
+		SELECT id, user_id, stock_id, quantity, locked_quantity, quantity - locked_quantity AS available_quantity, version, updated_at
 		FROM stock_portfolio
 		WHERE user_id = $1
 	`
@@ -301,13 +424,21 @@ func (r *pgRepository) GetPortfoliosByUserID(ctx context.Context, userID int64)
 }
 
 func (r *pgRepository) AddHistoryRecord(ctx context.Context, h *entities.History) (int64, error) {
+	return r.addHistoryRecord(ctx, r.DB, h)
+}
+
+func (r *pgRepository) AddHistoryRecordTx(ctx context.Context, tx database.Transaction, h *entities.History) (int64, error) {
+	return r.addHistoryRecord(ctx, tx, h)
+}
+
+func (r *pgRepository) addHistoryRecord(ctx context.Context, db executor, h *entities.History) (int64, error) {
 	q := `
-		INSERT INTO stock_history (user_id, order_id, stock_id, action, details, amount, created_at)
-		VALUES ($1,$2,$3,$4,$5,$6,NOW())
+		INSERT INTO stock_history (user_id, order_id, stock_id, action, details, amount, realized_pnl, created_at)
+		VALUES ($1,$2,$3,$4,$5,$6,$7,NOW())
 		RETURNING id
 	`
 	var id int64
-	if err := r.DB.Insert(ctx, &id, q, h.UserID, h.OrderID, h.StockID, h.Action, h.Details, h.Amount); err != nil {
+	if err := db.Insert(ctx, &id, q, h.UserID, h.OrderID, h.StockID, h.Action, h.Details, h.Amount, h.RealizedPnL); err != nil {
 		return 0, errors.Wrap(err, "AddHistoryRecord failed")
 	}
 	return id, nil
@@ -315,7 +446,7 @@ func (r *pgRepository) AddHistoryRecord(ctx context.Context, h *entities.History
 
 func (r *pgRepository) GetHistoryByUserID(ctx context.Context, userID int64) ([]*entities.History, error) {
 	q := `
-		SELECT id, user_id, order_id, stock_id, action, details, amount, created_at
+		SELECT id, user_id, order_id, stock_id, action, details, amount, realized_pnl, created_at
 		FROM stock_history
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -326,3 +457,1193 @@ func (r *pgRepository) GetHistoryByUserID(ctx context.Context, userID int64) ([]
 	}
 	return history, nil
 }
+
+// --- Ledger ---
+
+// PostJournalTx записывает сбалансированную проводку. Счета создаются при первом обращении.
+func (r *pgRepository) PostJournalTx(ctx context.Context, tx database.Transaction, j *entities.LedgerJournal, legs map[*entities.LedgerAccount]decimal.Decimal) (int64, error) {
+	sum := decimal.Zero
+	for _, amount := range legs {
+		sum = sum.Add(amount)
+	}
+	if !sum.IsZero() {
+		return 0, errors.Errorf("PostJournalTx: unbalanced journal (sum=%s)", sum)
+	}
+
+	qJournal := `
+		INSERT INTO stock_ledger_journal (kind, reference, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id
+	`
+	var journalID int64
+	if err := tx.Insert(ctx, &journalID, qJournal, j.Kind, j.Reference); err != nil {
+		return 0, errors.Wrap(err, "PostJournalTx: insert journal failed")
+	}
+
+	qAccount := `
+		INSERT INTO stock_ledger_account (code, user_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (code) DO UPDATE SET code = EXCLUDED.code
+		RETURNING id
+	`
+	qEntry := `
+		INSERT INTO stock_ledger_entry (journal_id, account_id, amount, created_at)
+		VALUES ($1, $2, $3, NOW())
+		RETURNING id
+	`
+	for account, amount := range legs {
+		if err := tx.Insert(ctx, &account.ID, qAccount, account.Code, account.UserID); err != nil {
+			return 0, errors.Wrap(err, "PostJournalTx: ensure account failed")
+		}
+		var entryID int64
+		if err := tx.Insert(ctx, &entryID, qEntry, journalID, account.ID, amount); err != nil {
+			return 0, errors.Wrap(err, "PostJournalTx: insert entry failed")
+		}
+	}
+
+	return journalID, nil
+}
+
+func (r *pgRepository) GetLedgerEntriesByAccount(ctx context.Context, code string) ([]*entities.LedgerEntry, error) {
+	q := `
+		SELECT e.id, e.journal_id, j.kind AS journal_kind, j.reference, a.code AS account_code, e.amount, e.created_at
+		FROM stock_ledger_entry e
+		JOIN stock_ledger_account a ON a.id = e.account_id
+		JOIN stock_ledger_journal j ON j.id = e.journal_id
+		WHERE a.code = $1
+		ORDER BY e.created_at DESC, e.id DESC
+	`
+	var entries []*entities.LedgerEntry
+	if err := r.DB.Get(ctx, &entries, q, code); err != nil {
+		return nil, errors.Wrap(err, "GetLedgerEntriesByAccount failed")
+	}
+	return entries, nil
+}
+
+// GetBalanceMismatches сверяет stock_user.balance с суммой проводок по денежному счёту пользователя.
+func (r *pgRepository) GetBalanceMismatches(ctx context.Context) ([]*entities.BalanceMismatch, error) {
+	q := `
+		SELECT u.id AS user_id, u.balance, COALESCE(SUM(e.amount), 0) AS ledger_balance
+		FROM stock_user u
+		LEFT JOIN stock_ledger_account a ON a.user_id = u.id
+		LEFT JOIN stock_ledger_entry e ON e.account_id = a.id
+		GROUP BY u.id, u.balance
+		HAVING u.balance <> COALESCE(SUM(e.amount), 0)
+		ORDER BY u.id
+	`
+	var mismatches []*entities.BalanceMismatch
+	if err := r.DB.Get(ctx, &mismatches, q); err != nil {
+		return nil, errors.Wrap(err, "GetBalanceMismatches failed")
+	}
+	return mismatches, nil
+}
+
+// --- Idempotency ---
+
+// InsertIdempotencyKey занимает ключ. Просроченная запись перезаписывается.
+// Возвращает false, если ключ уже занят.
+func (r *pgRepository) InsertIdempotencyKey(ctx context.Context, rec *entities.IdempotencyRecord) (bool, error) {
+	q := `
+		INSERT INTO stock_idempotency_key (key, request_hash, status_code, content_type, body, expires_at, created_at)
+		VALUES ($1, $2, 0, '', NULL, $3, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+			status_code = 0,
+			content_type = '',
+			body = NULL,
+			expires_at = EXCLUDED.expires_at,
+			created_at = NOW()
+		WHERE stock_idempotency_key.expires_at < NOW()
+		RETURNING key
+	`
+	var key string
+	if err := r.DB.Insert(ctx, &key, q, rec.Key, rec.RequestHash, rec.ExpiresAt); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "InsertIdempotencyKey failed")
+	}
+	return true, nil
+}
+
+func (r *pgRepository) GetIdempotencyKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	q := `
+		SELECT key, request_hash, status_code, content_type, COALESCE(body, ''::bytea) AS body, expires_at
+		FROM stock_idempotency_key
+		WHERE key = $1
+	`
+	var rec entities.IdempotencyRecord
+	if err := r.DB.GetOne(ctx, &rec, q, key); err != nil {
+		return nil, errors.Wrap(err, "GetIdempotencyKey failed")
+	}
+	return &rec, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ. Строки может не быть (ключ занимали в Redis) — тогда
+// она создаётся. false — ключ уже занят другим запросом: его истёкшую блокировку перехватили.
+func (r *pgRepository) CompleteIdempotencyKey(ctx context.Context, rec *entities.IdempotencyRecord) (bool, error) {
+	q := `
+		INSERT INTO stock_idempotency_key (key, request_hash, status_code, content_type, body, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (key) DO UPDATE
+		SET status_code = EXCLUDED.status_code,
+			content_type = EXCLUDED.content_type,
+			body = EXCLUDED.body,
+			expires_at = EXCLUDED.expires_at
+		WHERE stock_idempotency_key.request_hash = EXCLUDED.request_hash
+		   OR stock_idempotency_key.expires_at < NOW()
+		RETURNING key
+	`
+	var key string
+	if err := r.DB.Insert(ctx, &key, q, rec.Key, rec.RequestHash, rec.StatusCode, rec.ContentType, rec.Body, rec.ExpiresAt); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "CompleteIdempotencyKey failed")
+	}
+	return true, nil
+}
+
+func (r *pgRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	q := `DELETE FROM stock_idempotency_key WHERE key = $1;`
+	if err := r.DB.Delete(ctx, nil, q, key); err != nil {
+		return errors.Wrap(err, "DeleteIdempotencyKey failed")
+	}
+	return nil
+}
+
+// --- Tokens ---
+
+func (r *pgRepository) CreateRefreshTokenTx(ctx context.Context, tx database.Transaction, t *entities.RefreshToken) (int64, error) {
+	q := `
+		INSERT INTO stock_refresh_token (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt); err != nil {
+		return 0, errors.Wrap(err, "CreateRefreshTokenTx failed")
+	}
+	return id, nil
+}
+
+// GetRefreshTokenForUpdateTx возвращает refresh-токен по хэшу под блокировкой; nil — если такого нет.
+func (r *pgRepository) GetRefreshTokenForUpdateTx(ctx context.Context, tx database.Transaction, tokenHash string) (*entities.RefreshToken, error) {
+	q := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM stock_refresh_token
+		WHERE token_hash = $1
+		FOR UPDATE
+	`
+	var t entities.RefreshToken
+	if err := tx.GetOne(ctx, &t, q, tokenHash); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "GetRefreshTokenForUpdateTx failed")
+	}
+	return &t, nil
+}
+
+func (r *pgRepository) MarkRefreshTokenUsedTx(ctx context.Context, tx database.Transaction, id int64) error {
+	q := `UPDATE stock_refresh_token SET used_at = NOW() WHERE id = $1 AND used_at IS NULL RETURNING id`
+	var updated int64
+	if err := tx.Update(ctx, &updated, q, id); err != nil {
+		return errors.Wrap(err, "MarkRefreshTokenUsedTx failed")
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamily отзывает все токены семейства: и текущий, и уже обменянные.
+func (r *pgRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	q := `UPDATE stock_refresh_token SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	if err := r.DB.Update(ctx, nil, q, familyID); err != nil {
+		return errors.Wrap(err, "RevokeRefreshTokenFamily failed")
+	}
+	return nil
+}
+
+// RevokeRefreshTokenFamilyByHash отзывает семейство токена, если он принадлежит userID.
+func (r *pgRepository) RevokeRefreshTokenFamilyByHash(ctx context.Context, userID int64, tokenHash string) error {
+	q := `
+		UPDATE stock_refresh_token
+		SET revoked_at = NOW()
+		WHERE revoked_at IS NULL
+		  AND family_id = (SELECT family_id FROM stock_refresh_token WHERE token_hash = $1 AND user_id = $2)
+	`
+	if err := r.DB.Update(ctx, nil, q, tokenHash, userID); err != nil {
+		return errors.Wrap(err, "RevokeRefreshTokenFamilyByHash failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) AddRevokedToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	q := `
+		INSERT INTO stock_revoked_token (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING
+	`
+	if err := r.DB.Insert(ctx, nil, q, jti, expiresAt); err != nil {
+		return errors.Wrap(err, "AddRevokedToken failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	q := `SELECT EXISTS (SELECT 1 FROM stock_revoked_token WHERE jti = $1 AND expires_at > NOW())`
+	var revoked bool
+	if err := r.DB.GetOne(ctx, &revoked, q, jti); err != nil {
+		return false, errors.Wrap(err, "IsTokenRevoked failed")
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredTokens удаляет истёкшие refresh-токены и записи отзыва; возвращает число удалённых строк.
+func (r *pgRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	q := `
+		WITH refresh AS (
+			DELETE FROM stock_refresh_token WHERE expires_at < $1 RETURNING 1
+		), revoked AS (
+			DELETE FROM stock_revoked_token WHERE expires_at < $1 RETURNING 1
+		), user_tokens AS (
+			DELETE FROM stock_user_token WHERE expires_at < $1 RETURNING 1
+		)
+		SELECT (SELECT COUNT(*) FROM refresh) + (SELECT COUNT(*) FROM revoked) + (SELECT COUNT(*) FROM user_tokens)
+	`
+	var n int64
+	if err := r.DB.GetOne(ctx, &n, q, before); err != nil {
+		return 0, errors.Wrap(err, "DeleteExpiredTokens failed")
+	}
+	return n, nil
+}
+
+// --- Transactions ---
+
+func (r *pgRepository) BeginTx(ctx context.Context) (database.Transaction, error) {
+	tx, err := r.DB.GetTransaction()
+	if err != nil {
+		return nil, errors.Wrap(err, "BeginTx failed")
+	}
+	return tx, nil
+}
+
+// DebitBalanceTx списывает amount со свободного (не зарезервированного) баланса.
+func (r *pgRepository) DebitBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error {
+	q := `
+		UPDATE stock_user
+		SET balance = balance - $1
+		WHERE id = $2 AND balance - reserved_balance >= $1
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, amount, userID); err != nil {
+		if pgxscan.NotFound(err) {
+			return entities.ErrInsufficientFunds
+		}
+		return errors.Wrap(err, "DebitBalanceTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) CreditBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error {
+	q := `
+		UPDATE stock_user
+		SET balance = balance + $1
+		WHERE id = $2
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, amount, userID); err != nil {
+		return errors.Wrap(err, "CreditBalanceTx failed")
+	}
+	return nil
+}
+
+// AddPortfolioQuantityTx меняет позицию на delta под блокировкой строки.
+// Уход ниже заблокированного количества — ErrInsufficientShares.
+func (r *pgRepository) AddPortfolioQuantityTx(ctx context.Context, tx database.Transaction, userID, stockID int64, delta decimal.Decimal) error {
+	var p entities.Portfolio
+	qSelect := `SELECT id, quantity, locked_quantity, version FROM stock_portfolio WHERE user_id=$1 AND stock_id=$2 FOR UPDATE`
+
+	err := tx.GetOne(ctx, &p, qSelect, userID, stockID)
+	if err != nil {
+		if !pgxscan.NotFound(err) {
+			return errors.Wrap(err, "AddPortfolioQuantityTx: select failed")
+		}
+		if delta.IsNegative() {
+			return entities.ErrInsufficientShares
+		}
+
+		qInsert := `INSERT INTO stock_portfolio (user_id, stock_id, quantity, version, updated_at)
+		            VALUES ($1,$2,$3,1,NOW()) RETURNING id`
+		var id int64
+		if err := tx.Insert(ctx, &id, qInsert, userID, stockID, delta); err != nil {
+			return errors.Wrap(err, "AddPortfolioQuantityTx: insert failed")
+		}
+		return nil
+	}
+
+	quantity := p.Quantity.Add(delta)
+	if quantity.LessThan(p.LockedQuantity) {
+		return entities.ErrInsufficientShares
+	}
+
+	qUpdate := `UPDATE stock_portfolio
+	            SET quantity = $1, version = version+1, updated_at=NOW()
+	            WHERE id=$2 RETURNING id`
+	var id int64
+	if err := tx.Update(ctx, &id, qUpdate, quantity, p.ID); err != nil {
+		return errors.Wrap(err, "AddPortfolioQuantityTx: update failed")
+	}
+	return nil
+}
+
+// --- Price history ---
+
+func (r *pgRepository) AddPriceTickTx(ctx context.Context, tx database.Transaction, stockID int64, price decimal.Decimal) error {
+	q := `
+		INSERT INTO stock_price_tick (stock_id, price, created_at)
+		VALUES ($1, $2, NOW())
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, stockID, price); err != nil {
+		return errors.Wrap(err, "AddPriceTickTx failed")
+	}
+	return nil
+}
+
+// GetCandles собирает бары шириной width из минутных баров stock_candle (до отметки
+// компактизации) и сырых тиков и сделок (после неё). from должен быть выровнен по width.
+func (r *pgRepository) GetCandles(ctx context.Context, stockID int64, width time.Duration, from, to time.Time) ([]*entities.Candle, error) {
+	q := `
+		WITH wm AS (
+			SELECT compacted_until AS t FROM stock_candle_watermark
+		),
+		raw AS (
+			SELECT created_at AS at, id, price, 0::NUMERIC AS quantity
+			FROM stock_price_tick
+			WHERE stock_id = $1 AND created_at >= GREATEST($3, (SELECT t FROM wm)) AND created_at < $4
+			UNION ALL
+			SELECT created_at, id, price, quantity
+			FROM stock_trade
+			WHERE stock_id = $1 AND created_at >= GREATEST($3, (SELECT t FROM wm)) AND created_at < $4
+		),
+		minutes AS (
+			SELECT open_time, open, high, low, close, volume
+			FROM stock_candle
+			WHERE stock_id = $1 AND open_time >= $3 AND open_time < LEAST($4, (SELECT t FROM wm))
+			UNION ALL
+			SELECT date_trunc('minute', at),
+				(array_agg(price ORDER BY at, id))[1],
+				MAX(price),
+				MIN(price),
+				(array_agg(price ORDER BY at DESC, id DESC))[1],
+				SUM(quantity)
+			FROM raw
+			GROUP BY 1
+		)
+		SELECT to_timestamp(floor(extract(epoch FROM open_time) / $2::BIGINT) * $2::BIGINT) AS open_time,
+			(array_agg(open ORDER BY open_time))[1] AS open,
+			MAX(high) AS high,
+			MIN(low) AS low,
+			(array_agg(close ORDER BY open_time DESC))[1] AS close,
+			SUM(volume) AS volume
+		FROM minutes
+		GROUP BY 1
+		ORDER BY 1
+	`
+	var candles []*entities.Candle
+	if err := r.DB.Get(ctx, &candles, q, stockID, int64(width/time.Second), from, to); err != nil {
+		return nil, errors.Wrap(err, "GetCandles failed")
+	}
+	return candles, nil
+}
+
+// CompactPriceTicksTx сворачивает тики и сделки до until в минутные бары, удаляет
+// свёрнутые тики и сдвигает отметку. until должен быть выровнен по минуте.
+// Возвращает число записанных баров.
+func (r *pgRepository) CompactPriceTicksTx(ctx context.Context, tx database.Transaction, until time.Time) (int64, error) {
+	var since time.Time
+	if err := tx.GetOne(ctx, &since, `SELECT compacted_until FROM stock_candle_watermark FOR UPDATE`); err != nil {
+		return 0, errors.Wrap(err, "CompactPriceTicksTx: lock watermark")
+	}
+	if !until.After(since) {
+		return 0, nil
+	}
+
+	q := `
+		INSERT INTO stock_candle (stock_id, open_time, open, high, low, close, volume)
+		SELECT stock_id,
+			date_trunc('minute', at),
+			(array_agg(price ORDER BY at, id))[1],
+			MAX(price),
+			MIN(price),
+			(array_agg(price ORDER BY at DESC, id DESC))[1],
+			SUM(quantity)
+		FROM (
+			SELECT stock_id, created_at AS at, id, price, 0::NUMERIC AS quantity
+			FROM stock_price_tick
+			WHERE created_at >= $1 AND created_at < $2
+			UNION ALL
+			SELECT stock_id, created_at, id, price, quantity
+			FROM stock_trade
+			WHERE created_at >= $1 AND created_at < $2
+		) raw
+		GROUP BY stock_id, 2
+		ON CONFLICT (stock_id, open_time) DO NOTHING
+	`
+	tag, err := tx.Exec(ctx, q, since, until)
+	if err != nil {
+		return 0, errors.Wrap(err, "CompactPriceTicksTx: insert candles")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM stock_price_tick WHERE created_at < $1`, until); err != nil {
+		return 0, errors.Wrap(err, "CompactPriceTicksTx: delete ticks")
+	}
+	if _, err := tx.Exec(ctx, `UPDATE stock_candle_watermark SET compacted_until = $1`, until); err != nil {
+		return 0, errors.Wrap(err, "CompactPriceTicksTx: move watermark")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// --- Tax lots ---
+
+const taxLotColumns = `id, user_id, stock_id, order_id, quantity, remaining, price, created_at`
+
+func (r *pgRepository) CreateTaxLotTx(ctx context.Context, tx database.Transaction, lot *entities.TaxLot) (int64, error) {
+	q := `
+		INSERT INTO stock_tax_lot (user_id, stock_id, order_id, quantity, remaining, price, created_at)
+		VALUES ($1, $2, $3, $4, $4, $5, NOW())
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, lot.UserID, lot.StockID, lot.OrderID, lot.Quantity, lot.Price); err != nil {
+		return 0, errors.Wrap(err, "CreateTaxLotTx failed")
+	}
+	return id, nil
+}
+
+// GetOpenTaxLotsForUpdateTx блокирует открытые лоты позиции в порядке списания:
+// от старых к новым, либо наоборот при newestFirst.
+func (r *pgRepository) GetOpenTaxLotsForUpdateTx(ctx context.Context, tx database.Transaction, userID, stockID int64, newestFirst bool) ([]*entities.TaxLot, error) {
+	order := "ASC"
+	if newestFirst {
+		order = "DESC"
+	}
+	q := `
+		SELECT ` + taxLotColumns + `
+		FROM stock_tax_lot
+		WHERE user_id = $1 AND stock_id = $2 AND remaining > 0
+		ORDER BY created_at ` + order + `, id ` + order + `
+		FOR UPDATE
+	`
+	var lots []*entities.TaxLot
+	if err := tx.Get(ctx, &lots, q, userID, stockID); err != nil {
+		return nil, errors.Wrap(err, "GetOpenTaxLotsForUpdateTx failed")
+	}
+	return lots, nil
+}
+
+func (r *pgRepository) UpdateTaxLotTx(ctx context.Context, tx database.Transaction, lotID int64, remaining, price decimal.Decimal) error {
+	q := `
+		UPDATE stock_tax_lot
+		SET remaining = $1, price = $2
+		WHERE id = $3
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, remaining, price, lotID); err != nil {
+		return errors.Wrap(err, "UpdateTaxLotTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) GetOpenTaxLotsByUserID(ctx context.Context, userID int64) ([]*entities.TaxLot, error) {
+	q := `
+		SELECT ` + taxLotColumns + `
+		FROM stock_tax_lot
+		WHERE user_id = $1 AND remaining > 0
+		ORDER BY stock_id, created_at, id
+	`
+	var lots []*entities.TaxLot
+	if err := r.DB.Get(ctx, &lots, q, userID); err != nil {
+		return nil, errors.Wrap(err, "GetOpenTaxLotsByUserID failed")
+	}
+	return lots, nil
+}
+
+// --- Holds ---
+
+// ReserveBalanceTx резервирует amount из свободного баланса.
+func (r *pgRepository) ReserveBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error {
+	q := `
+		UPDATE stock_user
+		SET reserved_balance = reserved_balance + $1
+		WHERE id = $2 AND balance - reserved_balance >= $1
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, amount, userID); err != nil {
+		if pgxscan.NotFound(err) {
+			return entities.ErrInsufficientFunds
+		}
+		return errors.Wrap(err, "ReserveBalanceTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) ReleaseBalanceTx(ctx context.Context, tx database.Transaction, userID int64, amount decimal.Decimal) error {
+	q := `
+		UPDATE stock_user
+		SET reserved_balance = GREATEST(reserved_balance - $1, 0)
+		WHERE id = $2
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, amount, userID); err != nil {
+		return errors.Wrap(err, "ReleaseBalanceTx failed")
+	}
+	return nil
+}
+
+// LockSharesTx блокирует qty бумаг из свободной части позиции.
+func (r *pgRepository) LockSharesTx(ctx context.Context, tx database.Transaction, userID, stockID int64, qty decimal.Decimal) error {
+	q := `
+		UPDATE stock_portfolio
+		SET locked_quantity = locked_quantity + $1, version = version+1, updated_at = NOW()
+		WHERE user_id = $2 AND stock_id = $3 AND quantity - locked_quantity >= $1
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, qty, userID, stockID); err != nil {
+		if pgxscan.NotFound(err) {
+			return entities.ErrInsufficientShares
+		}
+		return errors.Wrap(err, "LockSharesTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) UnlockSharesTx(ctx context.Context, tx database.Transaction, userID, stockID int64, qty decimal.Decimal) error {
+	q := `
+		UPDATE stock_portfolio
+		SET locked_quantity = GREATEST(locked_quantity - $1, 0), version = version+1, updated_at = NOW()
+		WHERE user_id = $2 AND stock_id = $3
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, qty, userID, stockID); err != nil {
+		return errors.Wrap(err, "UnlockSharesTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) CreateHoldTx(ctx context.Context, tx database.Transaction, h *entities.Hold) (int64, error) {
+	q := `
+		INSERT INTO stock_hold (order_id, user_id, stock_id, kind, amount, remaining, status, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5, $6, NOW(), NOW())
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, h.OrderID, h.UserID, h.StockID, h.Kind, h.Amount, entities.HoldActive); err != nil {
+		return 0, errors.Wrap(err, "CreateHoldTx failed")
+	}
+	return id, nil
+}
+
+// GetHoldForUpdateTx возвращает резерв заявки под блокировкой; nil — если резерва нет.
+func (r *pgRepository) GetHoldForUpdateTx(ctx context.Context, tx database.Transaction, orderID int64) (*entities.Hold, error) {
+	q := `
+		SELECT id, order_id, user_id, stock_id, kind, amount, remaining, status, created_at, updated_at
+		FROM stock_hold
+		WHERE order_id = $1
+		FOR UPDATE
+	`
+	var h entities.Hold
+	if err := tx.GetOne(ctx, &h, q, orderID); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "GetHoldForUpdateTx failed")
+	}
+	return &h, nil
+}
+
+func (r *pgRepository) UpdateHoldTx(ctx context.Context, tx database.Transaction, holdID int64, remaining decimal.Decimal, status entities.HoldStatus) error {
+	q := `
+		UPDATE stock_hold
+		SET remaining = $1, status = $2, updated_at = NOW()
+		WHERE id = $3
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, remaining, status, holdID); err != nil {
+		return errors.Wrap(err, "UpdateHoldTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) GetOrderForUpdateTx(ctx context.Context, tx database.Transaction, orderID int64) (*entities.Order, error) {
+	q := `
+		SELECT ` + orderColumns + `
+		FROM stock_order
+		WHERE id = $1
+		FOR UPDATE
+	`
+	var order entities.Order
+	if err := tx.GetOne(ctx, &order, q, orderID); err != nil {
+		return nil, errors.Wrap(err, "GetOrderForUpdateTx failed")
+	}
+	return &order, nil
+}
+
+func (r *pgRepository) GetRoles(ctx context.Context) ([]*entities.RoleInfo, error) {
+	q := `SELECT name, description FROM stock_role ORDER BY name`
+	var roles []*entities.RoleInfo
+	if err := r.DB.Get(ctx, &roles, q); err != nil {
+		return nil, errors.Wrap(err, "GetRoles failed")
+	}
+	return roles, nil
+}
+
+func (r *pgRepository) GetPermissions(ctx context.Context) ([]*entities.PermissionInfo, error) {
+	q := `SELECT name, description FROM stock_permission ORDER BY name`
+	var perms []*entities.PermissionInfo
+	if err := r.DB.Get(ctx, &perms, q); err != nil {
+		return nil, errors.Wrap(err, "GetPermissions failed")
+	}
+	return perms, nil
+}
+
+func (r *pgRepository) GetRolePermissions(ctx context.Context) ([]*entities.RolePermission, error) {
+	q := `SELECT role, permission FROM stock_role_permission ORDER BY role, permission`
+	var rows []*entities.RolePermission
+	if err := r.DB.Get(ctx, &rows, q); err != nil {
+		return nil, errors.Wrap(err, "GetRolePermissions failed")
+	}
+	return rows, nil
+}
+
+func (r *pgRepository) UpsertRoleTx(ctx context.Context, tx database.Transaction, role *entities.RoleInfo) error {
+	q := `
+		INSERT INTO stock_role (name, description)
+		VALUES ($1, $2)
+		ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description
+	`
+	if err := tx.Insert(ctx, nil, q, role.Name, role.Description); err != nil {
+		return errors.Wrap(err, "UpsertRoleTx failed")
+	}
+	return nil
+}
+
+// ReplaceRolePermissionsTx заменяет набор прав роли целиком.
+func (r *pgRepository) ReplaceRolePermissionsTx(ctx context.Context, tx database.Transaction, role entities.Role, perms []entities.Permission) error {
+	if err := tx.Delete(ctx, nil, `DELETE FROM stock_role_permission WHERE role = $1`, role); err != nil {
+		return errors.Wrap(err, "ReplaceRolePermissionsTx: failed to clear permissions")
+	}
+	if len(perms) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(perms))
+	for i, p := range perms {
+		names[i] = string(p)
+	}
+	q := `
+		INSERT INTO stock_role_permission (role, permission)
+		SELECT $1, unnest($2::text[])
+	`
+	if err := tx.Insert(ctx, nil, q, role, names); err != nil {
+		return errors.Wrap(err, "ReplaceRolePermissionsTx: failed to insert permissions")
+	}
+	return nil
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at, last_used_at, revoked_at, created_at`
+
+func (r *pgRepository) CreateAPIKeyTx(ctx context.Context, tx database.Transaction, k *entities.APIKey) (int64, error) {
+	q := `
+		INSERT INTO stock_api_key (user_id, name, prefix, key_hash, scopes, allowed_ips, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, k.UserID, k.Name, k.Prefix, k.KeyHash, k.Scopes, k.AllowedIPs, k.ExpiresAt, k.CreatedAt); err != nil {
+		return 0, errors.Wrap(err, "CreateAPIKeyTx failed")
+	}
+	return id, nil
+}
+
+// GetAPIKeysByUserID возвращает неотозванные ключи пользователя.
+func (r *pgRepository) GetAPIKeysByUserID(ctx context.Context, userID int64) ([]*entities.APIKey, error) {
+	q := `
+		SELECT ` + apiKeyColumns + `
+		FROM stock_api_key
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY id DESC
+	`
+	var keys []*entities.APIKey
+	if err := r.DB.Get(ctx, &keys, q, userID); err != nil {
+		return nil, errors.Wrap(err, "GetAPIKeysByUserID failed")
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash возвращает ключ по хэшу; nil — если такого нет.
+func (r *pgRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	q := `SELECT ` + apiKeyColumns + ` FROM stock_api_key WHERE key_hash = $1`
+	var k entities.APIKey
+	if err := r.DB.GetOne(ctx, &k, q, keyHash); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "GetAPIKeyByHash failed")
+	}
+	return &k, nil
+}
+
+func (r *pgRepository) CountActiveAPIKeys(ctx context.Context, userID int64) (int64, error) {
+	q := `
+		SELECT COUNT(*)
+		FROM stock_api_key
+		WHERE user_id = $1 AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())
+	`
+	var n int64
+	if err := r.DB.GetOne(ctx, &n, q, userID); err != nil {
+		return 0, errors.Wrap(err, "CountActiveAPIKeys failed")
+	}
+	return n, nil
+}
+
+// RevokeAPIKey отзывает ключ, если он принадлежит userID; false — ключ не найден или уже отозван.
+func (r *pgRepository) RevokeAPIKeyTx(ctx context.Context, tx database.Transaction, userID, id int64) (bool, error) {
+	q := `
+		UPDATE stock_api_key
+		SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+		RETURNING id
+	`
+	var revokedID int64
+	if err := tx.Update(ctx, &revokedID, q, id, userID); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "RevokeAPIKeyTx failed")
+	}
+	return true, nil
+}
+
+// TouchAPIKey обновляет last_used_at не чаще раза в минуту, чтобы не писать в БД на каждый запрос бота.
+func (r *pgRepository) TouchAPIKey(ctx context.Context, id int64) error {
+	q := `
+		UPDATE stock_api_key
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+	if err := r.DB.Update(ctx, nil, q, id); err != nil {
+		return errors.Wrap(err, "TouchAPIKey failed")
+	}
+	return nil
+}
+
+const userTOTPColumns = `user_id, secret_enc, enabled_at, last_used_step, failed_attempts, locked_until, created_at`
+
+// GetUserTOTP возвращает второй фактор пользователя; nil — если он не подключался.
+func (r *pgRepository) GetUserTOTP(ctx context.Context, userID int64) (*entities.UserTOTP, error) {
+	q := `SELECT ` + userTOTPColumns + ` FROM stock_user_totp WHERE user_id = $1`
+	var t entities.UserTOTP
+	if err := r.DB.GetOne(ctx, &t, q, userID); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "GetUserTOTP failed")
+	}
+	return &t, nil
+}
+
+// UpsertPendingUserTOTP сохраняет новый неподтверждённый секрет. Уже включённый фактор
+// не перезаписывается: false — сначала его нужно отключить.
+func (r *pgRepository) UpsertPendingUserTOTP(ctx context.Context, userID int64, secretEnc []byte) (bool, error) {
+	q := `
+		INSERT INTO stock_user_totp (user_id, secret_enc)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret_enc = EXCLUDED.secret_enc, last_used_step = 0, failed_attempts = 0, locked_until = NULL, created_at = NOW()
+		WHERE stock_user_totp.enabled_at IS NULL
+		RETURNING user_id
+	`
+	var id int64
+	if err := r.DB.Insert(ctx, &id, q, userID, secretEnc); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "UpsertPendingUserTOTP failed")
+	}
+	return true, nil
+}
+
+func (r *pgRepository) CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	q := `SELECT COUNT(*) FROM stock_recovery_code WHERE user_id = $1 AND used_at IS NULL`
+	var n int64
+	if err := r.DB.GetOne(ctx, &n, q, userID); err != nil {
+		return 0, errors.Wrap(err, "CountUnusedRecoveryCodes failed")
+	}
+	return n, nil
+}
+
+func (r *pgRepository) GetUserTOTPForUpdateTx(ctx context.Context, tx database.Transaction, userID int64) (*entities.UserTOTP, error) {
+	q := `SELECT ` + userTOTPColumns + ` FROM stock_user_totp WHERE user_id = $1 FOR UPDATE`
+	var t entities.UserTOTP
+	if err := tx.GetOne(ctx, &t, q, userID); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "GetUserTOTPForUpdateTx failed")
+	}
+	return &t, nil
+}
+
+// RecordTOTPSuccessTx запоминает шаг принятого кода и сбрасывает счётчик ошибок.
+// enable — это подтверждение подключения.
+func (r *pgRepository) RecordTOTPSuccessTx(ctx context.Context, tx database.Transaction, userID, step int64, enable bool) error {
+	q := `
+		UPDATE stock_user_totp
+		SET last_used_step = GREATEST(last_used_step, $2),
+		    failed_attempts = 0,
+		    locked_until = NULL,
+		    enabled_at = CASE WHEN $3 THEN COALESCE(enabled_at, NOW()) ELSE enabled_at END
+		WHERE user_id = $1
+	`
+	if err := tx.Update(ctx, nil, q, userID, step, enable); err != nil {
+		return errors.Wrap(err, "RecordTOTPSuccessTx failed")
+	}
+	return nil
+}
+
+// RecordTOTPFailureTx считает неверный код; на maxAttempts-й ошибке проверка закрывается на lockout.
+func (r *pgRepository) RecordTOTPFailureTx(ctx context.Context, tx database.Transaction, userID int64, maxAttempts int, lockout time.Duration) error {
+	q := `
+		UPDATE stock_user_totp
+		SET failed_attempts = CASE WHEN failed_attempts + 1 >= $2 THEN 0 ELSE failed_attempts + 1 END,
+		    locked_until = CASE WHEN failed_attempts + 1 >= $2 THEN NOW() + $3 * INTERVAL '1 second' ELSE locked_until END
+		WHERE user_id = $1
+	`
+	if err := tx.Update(ctx, nil, q, userID, maxAttempts, lockout.Seconds()); err != nil {
+		return errors.Wrap(err, "RecordTOTPFailureTx failed")
+	}
+	return nil
+}
+
+// DeleteUserTOTPTx отключает второй фактор вместе с кодами восстановления.
+func (r *pgRepository) DeleteUserTOTPTx(ctx context.Context, tx database.Transaction, userID int64) error {
+	if err := tx.Delete(ctx, nil, `DELETE FROM stock_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return errors.Wrap(err, "DeleteUserTOTPTx: failed to delete recovery codes")
+	}
+	if err := tx.Delete(ctx, nil, `DELETE FROM stock_user_totp WHERE user_id = $1`, userID); err != nil {
+		return errors.Wrap(err, "DeleteUserTOTPTx failed")
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodesTx заменяет все коды восстановления пользователя новыми.
+func (r *pgRepository) ReplaceRecoveryCodesTx(ctx context.Context, tx database.Transaction, userID int64, hashes []string) error {
+	if err := tx.Delete(ctx, nil, `DELETE FROM stock_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return errors.Wrap(err, "ReplaceRecoveryCodesTx: failed to clear codes")
+	}
+	q := `
+		INSERT INTO stock_recovery_code (user_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`
+	if err := tx.Insert(ctx, nil, q, userID, hashes); err != nil {
+		return errors.Wrap(err, "ReplaceRecoveryCodesTx: failed to insert codes")
+	}
+	return nil
+}
+
+// UseRecoveryCodeTx гасит код восстановления; false — кода нет или он уже использован.
+func (r *pgRepository) UseRecoveryCodeTx(ctx context.Context, tx database.Transaction, userID int64, codeHash string) (bool, error) {
+	q := `
+		UPDATE stock_recovery_code
+		SET used_at = NOW()
+		WHERE id = (
+			SELECT id FROM stock_recovery_code
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Update(ctx, &id, q, userID, codeHash); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "UseRecoveryCodeTx failed")
+	}
+	return true, nil
+}
+
+// ResetTOTPLockout снимает блокировку второго фактора после серии неверных кодов.
+func (r *pgRepository) ResetTOTPLockout(ctx context.Context, userID int64) error {
+	q := `UPDATE stock_user_totp SET failed_attempts = 0, locked_until = NULL WHERE user_id = $1`
+	if err := r.DB.Update(ctx, nil, q, userID); err != nil {
+		return errors.Wrap(err, "ResetTOTPLockout failed")
+	}
+	return nil
+}
+
+// CreateUserToken сохраняет jti нового токена из письма. Прежние неиспользованные токены
+// того же назначения гасятся: действует только последнее письмо.
+func (r *pgRepository) CreateUserToken(ctx context.Context, t *entities.UserToken) error {
+	q := `
+		WITH superseded AS (
+			UPDATE stock_user_token SET used_at = NOW()
+			WHERE user_id = $2 AND purpose = $3 AND used_at IS NULL
+		)
+		INSERT INTO stock_user_token (jti, user_id, purpose, email, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	if err := r.DB.Insert(ctx, nil, q, t.JTI, t.UserID, t.Purpose, t.Email, t.ExpiresAt); err != nil {
+		return errors.Wrap(err, "CreateUserToken failed")
+	}
+	return nil
+}
+
+// ConsumeUserTokenTx гасит токен; nil — токен уже использован, истёк или адрес пользователя сменился.
+func (r *pgRepository) ConsumeUserTokenTx(ctx context.Context, tx database.Transaction, jti, purpose string) (*entities.UserToken, error) {
+	q := `
+		UPDATE stock_user_token t
+		SET used_at = NOW()
+		FROM stock_user u
+		WHERE t.jti = $1 AND t.purpose = $2 AND t.used_at IS NULL AND t.expires_at > NOW()
+		  AND u.id = t.user_id AND u.email = t.email
+		RETURNING t.jti, t.user_id, t.purpose, t.email, t.expires_at, t.used_at, t.created_at
+	`
+	var t entities.UserToken
+	if err := tx.Update(ctx, &t, q, jti, purpose); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "ConsumeUserTokenTx failed")
+	}
+	return &t, nil
+}
+
+func (r *pgRepository) MarkEmailVerifiedTx(ctx context.Context, tx database.Transaction, userID int64) error {
+	q := `UPDATE stock_user SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`
+	if err := tx.Update(ctx, nil, q, userID); err != nil {
+		return errors.Wrap(err, "MarkEmailVerifiedTx failed")
+	}
+	return nil
+}
+
+func (r *pgRepository) UpdatePasswordTx(ctx context.Context, tx database.Transaction, userID int64, passwordHash string) error {
+	q := `UPDATE stock_user SET password = $1 WHERE id = $2`
+	if err := tx.Update(ctx, nil, q, passwordHash, userID); err != nil {
+		return errors.Wrap(err, "UpdatePasswordTx failed")
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokensTx отзывает все сессии пользователя, например после смены пароля.
+func (r *pgRepository) RevokeUserRefreshTokensTx(ctx context.Context, tx database.Transaction, userID int64) error {
+	q := `UPDATE stock_refresh_token SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	if err := tx.Update(ctx, nil, q, userID); err != nil {
+		return errors.Wrap(err, "RevokeUserRefreshTokensTx failed")
+	}
+	return nil
+}
+
+// --- Invites ---
+
+const inviteColumns = `id, code_hash, role, email, created_by, used_by, expires_at, used_at, revoked_at, created_at`
+
+func (r *pgRepository) CreateInviteTx(ctx context.Context, tx database.Transaction, inv *entities.Invite) (int64, error) {
+	q := `
+		INSERT INTO stock_invite (code_hash, role, email, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, inv.CodeHash, inv.Role, inv.Email, inv.CreatedBy, inv.ExpiresAt, inv.CreatedAt); err != nil {
+		return 0, errors.Wrap(err, "CreateInviteTx failed")
+	}
+	return id, nil
+}
+
+// GetInvites возвращает все приглашения, новые первыми: использованные и отозванные тоже.
+func (r *pgRepository) GetInvites(ctx context.Context) ([]*entities.Invite, error) {
+	q := `SELECT ` + inviteColumns + ` FROM stock_invite ORDER BY id DESC`
+	var invites []*entities.Invite
+	if err := r.DB.Get(ctx, &invites, q); err != nil {
+		return nil, errors.Wrap(err, "GetInvites failed")
+	}
+	return invites, nil
+}
+
+// RevokeInviteTx отзывает неиспользованное приглашение; false — такого нет или оно уже недействительно.
+func (r *pgRepository) RevokeInviteTx(ctx context.Context, tx database.Transaction, id int64) (bool, error) {
+	q := `
+		UPDATE stock_invite SET revoked_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+		RETURNING id
+	`
+	var got int64
+	if err := tx.Update(ctx, &got, q, id); err != nil {
+		if pgxscan.NotFound(err) {
+			return false, nil
+		}
+		return false, errors.Wrap(err, "RevokeInviteTx failed")
+	}
+	return true, nil
+}
+
+// bootstrapAdminLock — ключ advisory-блокировки первичного создания администратора.
+const bootstrapAdminLock = 0x61646d6e
+
+// CreateFirstAdminTx создаёт администратора, только если в системе ещё нет ни одного;
+// false — администратор уже есть. Без блокировки два параллельных запуска в READ COMMITTED
+// оба не увидели бы чужую незакоммиченную вставку и создали бы двух администраторов.
+func (r *pgRepository) CreateFirstAdminTx(ctx context.Context, tx database.Transaction, user *entities.User) (int64, bool, error) {
+	var locked int
+	if err := tx.GetOne(ctx, &locked, `SELECT 1 FROM pg_advisory_xact_lock($1)`, bootstrapAdminLock); err != nil {
+		return 0, false, errors.Wrap(err, "CreateFirstAdminTx: lock failed")
+	}
+
+	q := `
+		INSERT INTO stock_user (email, password, role, balance, email_verified_at)
+		SELECT $1, $2, $3, 0, NOW()
+		WHERE NOT EXISTS (SELECT 1 FROM stock_user WHERE role = $3)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, user.Email, user.Password, entities.RoleAdmin); err != nil {
+		if pgxscan.NotFound(err) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, "CreateFirstAdminTx failed")
+	}
+	return id, true, nil
+}
+
+// ConsumeInviteTx гасит действующее приглашение по хэшу кода; nil — код не подходит.
+func (r *pgRepository) ConsumeInviteTx(ctx context.Context, tx database.Transaction, codeHash string) (*entities.Invite, error) {
+	q := `
+		UPDATE stock_invite SET used_at = NOW()
+		WHERE code_hash = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING ` + inviteColumns
+	var inv entities.Invite
+	if err := tx.Update(ctx, &inv, q, codeHash); err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "ConsumeInviteTx failed")
+	}
+	return &inv, nil
+}
+
+func (r *pgRepository) SetInviteUserTx(ctx context.Context, tx database.Transaction, inviteID, userID int64) error {
+	q := `UPDATE stock_invite SET used_by = $1 WHERE id = $2`
+	if err := tx.Update(ctx, nil, q, userID, inviteID); err != nil {
+		return errors.Wrap(err, "SetInviteUserTx failed")
+	}
+	return nil
+}
+
+// CreateUserTx создаёт пользователя с нулевым балансом: стартовый баланс заводится проводкой
+// в той же транзакции. Подтверждение email берётся из user.
+func (r *pgRepository) CreateUserTx(ctx context.Context, tx database.Transaction, user *entities.User) (int64, error) {
+	q := `
+		INSERT INTO stock_user (email, password, role, balance, email_verified_at)
+		VALUES ($1, $2, $3, 0, $4)
+		RETURNING id
+	`
+	var id int64
+	if err := tx.Insert(ctx, &id, q, user.Email, user.Password, user.Role, user.EmailVerifiedAt); err != nil {
+		return 0, errors.Wrap(err, "CreateUserTx failed")
+	}
+	return id, nil
+}
+
+// --- Audit log ---
+
+const auditColumns = `id, actor_id, actor_role, action, target_type, target_id, before_data, after_data, ip, request_id, prev_hash, hash, created_at`
+
+// auditChainLock — ключ advisory-блокировки: записи в журнал идут строго по одной,
+// иначе две транзакции сослались бы на один и тот же предыдущий хэш.
+const auditChainLock = 0x61756469
+
+// LockAuditChainTx берёт блокировку цепочки до конца транзакции и возвращает хэш последней записи
+// ("" — журнал пуст). Хэш читается отдельным запросом: в READ COMMITTED снимок берётся на запрос,
+// и только так он видит записи, закоммиченные, пока мы ждали блокировку.
+func (r *pgRepository) LockAuditChainTx(ctx context.Context, tx database.Transaction) (string, error) {
+	var locked int
+	if err := tx.GetOne(ctx, &locked, `SELECT 1 FROM pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+		return "", errors.Wrap(err, "LockAuditChainTx: lock failed")
+	}
+
+	var hash string
+	if err := tx.GetOne(ctx, &hash, `SELECT hash FROM stock_audit_log ORDER BY id DESC LIMIT 1`); err != nil {
+		if pgxscan.NotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrap(err, "LockAuditChainTx failed")
+	}
+	return hash, nil
+}
+
+func (r *pgRepository) InsertAuditEntryTx(ctx context.Context, tx database.Transaction, e *entities.AuditEntry) (int64, error) {
+	q := `
+		INSERT INTO stock_audit_log (actor_id, actor_role, action, target_type, target_id, before_data, after_data, ip, request_id, prev_hash, hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`
+	var id int64
+	err := tx.Insert(ctx, &id, q, e.ActorID, e.ActorRole, e.Action, e.TargetType, e.TargetID,
+		jsonOrNull(e.Before), jsonOrNull(e.After), e.IP, e.RequestID, e.PrevHash, e.Hash, e.CreatedAt)
+	if err != nil {
+		return 0, errors.Wrap(err, "InsertAuditEntryTx failed")
+	}
+	return id, nil
+}
+
+// GetAuditEntries — страница журнала по фильтру, от новых записей к старым.
+func (r *pgRepository) GetAuditEntries(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error) {
+	q := `
+		SELECT ` + auditColumns + `
+		FROM stock_audit_log
+		WHERE ($1::bigint IS NULL OR actor_id = $1)
+		  AND ($2 = '' OR action = $2)
+		  AND ($3 = '' OR target_type = $3)
+		  AND ($4 = '' OR target_id = $4)
+		  AND ($5::timestamptz IS NULL OR created_at >= $5)
+		  AND ($6::timestamptz IS NULL OR created_at < $6)
+		  AND ($7 = 0 OR id < $7)
+		ORDER BY id DESC
+		LIMIT $8
+	`
+	var entries []*entities.AuditEntry
+	err := r.DB.Get(ctx, &entries, q, f.ActorID, string(f.Action), f.TargetType, f.TargetID, f.From, f.To, f.BeforeID, f.Limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "GetAuditEntries failed")
+	}
+	return entries, nil
+}
+
+// GetAuditEntriesAfter — записи по возрастанию id, начиная после afterID; для проверки цепочки.
+func (r *pgRepository) GetAuditEntriesAfter(ctx context.Context, afterID int64, limit int) ([]*entities.AuditEntry, error) {
+	q := `SELECT ` + auditColumns + ` FROM stock_audit_log WHERE id > $1 ORDER BY id LIMIT $2`
+	var entries []*entities.AuditEntry
+	if err := r.DB.Get(ctx, &entries, q, afterID, limit); err != nil {
+		return nil, errors.Wrap(err, "GetAuditEntriesAfter failed")
+	}
+	return entries, nil
+}
+
+// jsonOrNull — пустой снимок пишется как SQL NULL.
+func jsonOrNull(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// CreateAPIKey проверяет параметры, сохраняет хэш ключа и возвращает сам ключ — больше его не узнать.
+func (s *service) CreateAPIKey(ctx context.Context, k *entities.APIKey) (string, error) {
+	if len(k.Scopes) == 0 {
+		return "", entities.ErrInvalidAPIKeyScope
+	}
+	for _, scope := range k.Scopes {
+		if !entities.APIKeyScope(scope).Valid() {
+			return "", entities.ErrInvalidAPIKeyScope
+		}
+	}
+	for _, ip := range k.AllowedIPs {
+		if net.ParseIP(ip) == nil {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return "", entities.ErrInvalidAllowedIP
+			}
+		}
+	}
+	if k.AllowedIPs == nil {
+		k.AllowedIPs = []string{}
+	}
+
+	n, err := s.pgRepository.CountActiveAPIKeys(ctx, k.UserID)
+	if err != nil {
+		return "", err
+	}
+	if n >= int64(s.config.MaxAPIKeysPerUser) {
+		return "", entities.ErrTooManyAPIKeys
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return "", err
+	}
+	k.Prefix, k.KeyHash, k.CreatedAt = prefix, hash, time.Now()
+
+	err = s.inTx(ctx, func(tx database.Transaction) (err error) {
+		if k.ID, err = s.pgRepository.CreateAPIKeyTx(ctx, tx, k); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditAPIKeyCreate,
+			TargetType: entities.AuditTargetAPIKey,
+			TargetID:   auditID(k.ID),
+		}, nil, k)
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (s *service) ListAPIKeys(ctx context.Context, userID int64) ([]*entities.APIKey, error) {
+	return s.pgRepository.GetAPIKeysByUserID(ctx, userID)
+}
+
+// RevokeAPIKey отзывает ключ пользователя; чужой или уже отозванный ключ — ErrAPIKeyNotFound.
+func (s *service) RevokeAPIKey(ctx context.Context, userID, id int64) error {
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		ok, err := s.pgRepository.RevokeAPIKeyTx(ctx, tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return entities.ErrAPIKeyNotFound
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditAPIKeyRevoke,
+			TargetType: entities.AuditTargetAPIKey,
+			TargetID:   auditID(id),
+		}, nil, nil)
+	})
+}
+
+// AuthenticateAPIKey находит ключ и его владельца. Роль берётся из пользователя, а не из ключа,
+// поэтому понижение роли сразу урезает и ключи.
+func (s *service) AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*entities.APIKey, *entities.User, error) {
+	if !strings.HasPrefix(key, auth.APIKeyPrefix) {
+		return nil, nil, entities.ErrInvalidAPIKey
+	}
+
+	k, err := s.pgRepository.GetAPIKeyByHash(ctx, auth.HashToken(key))
+	if err != nil {
+		return nil, nil, err
+	}
+	if k == nil || !k.Active(time.Now()) || !k.AllowsIP(clientIP) {
+		return nil, nil, entities.ErrInvalidAPIKey
+	}
+
+	user, err := s.pgRepository.GetUserByID(ctx, k.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.pgRepository.TouchAPIKey(ctx, k.ID); err != nil {
+		s.log.Warnf("AuthenticateAPIKey: failed to update last_used_at: %v", err)
+	}
+	return k, user, nil
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Skapar/backend/internal/audit"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+const (
+	auditDefaultLimit = 50
+	auditMaxLimit     = 500
+	auditVerifyBatch  = 1000
+	auditFlushBatch   = 100
+)
+
+// queuedAudit — запись журнала, ждущая фоновой вставки.
+type queuedAudit struct {
+	ctx           context.Context
+	entry         *entities.AuditEntry
+	before, after interface{}
+}
+
+// GetAuditLog — страница журнала по фильтру, от новых записей к старым.
+func (s *service) GetAuditLog(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error) {
+	if f.Limit <= 0 {
+		f.Limit = auditDefaultLimit
+	}
+	if f.Limit > auditMaxLimit {
+		f.Limit = auditMaxLimit
+	}
+	return s.pgRepository.GetAuditEntries(ctx, f)
+}
+
+// VerifyAuditLog проходит цепочку с начала и пересчитывает хэши. Правка записи ломает её хэш,
+// удаление или вставка в середину — ссылку prev_hash следующей записи.
+func (s *service) VerifyAuditLog(ctx context.Context) (*entities.AuditVerification, error) {
+	res := &entities.AuditVerification{Valid: true}
+	var (
+		prev   string
+		lastID int64
+	)
+	for {
+		entries, err := s.pgRepository.GetAuditEntriesAfter(ctx, lastID, auditVerifyBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			res.Checked++
+			if err := audit.Verify(e, prev); err != nil {
+				return res.Break(e.ID, err.Error()), nil
+			}
+			prev, lastID = e.Hash, e.ID
+		}
+		if len(entries) < auditVerifyBatch {
+			return res, nil
+		}
+	}
+}
+
+// auditTx пишет действие в журнал в транзакции самого действия: без записи в журнале
+// изменение не коммитится. Исполнитель, IP и request id берутся из контекста, если исполнитель
+// не задан в e явно. До и после — снимки объекта, в журнал попадает их разница.
+// Вызывается последним шагом транзакции: блокировка цепочки держится от вставки до коммита,
+// а не всё время действия.
+func (s *service) auditTx(ctx context.Context, tx database.Transaction, e *entities.AuditEntry, before, after interface{}) error {
+	meta := audit.MetaFrom(ctx)
+	if e.ActorID == nil {
+		e.ActorID, e.ActorRole = meta.ActorID, meta.ActorRole
+	}
+	e.IP, e.RequestID = meta.IP, meta.RequestID
+
+	var err error
+	if e.Before, e.After, err = audit.Diff(before, after); err != nil {
+		return fmt.Errorf("audit %s: %w", e.Action, err)
+	}
+
+	prev, err := s.pgRepository.LockAuditChainTx(ctx, tx)
+	if err != nil {
+		return err
+	}
+	e.PrevHash = prev
+	// Postgres хранит микросекунды: хэш должен сойтись при повторном чтении
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	if e.Hash, err = audit.Hash(e); err != nil {
+		return fmt.Errorf("audit %s: %w", e.Action, err)
+	}
+	e.ID, err = s.pgRepository.InsertAuditEntryTx(ctx, tx, e)
+	return err
+}
+
+// recordAudit — запись события, у которого нет своей транзакции в Postgres (выход, сброс
+// блокировки в Redis). Ошибку получает вызывающий: запрос не должен пройти молча без записи.
+func (s *service) recordAudit(ctx context.Context, e *entities.AuditEntry, before, after interface{}) error {
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		return s.auditTx(ctx, tx, e, before, after)
+	})
+}
+
+// recordAuditAsync — запись в фоне для неудачных попыток входа: задержка ответа выдавала бы,
+// существует ли пользователь. Записи копятся в ограниченной очереди и пишутся пачками
+// (FlushAuditQueue), чтобы подбор пароля не занимал блокировку цепочки на каждую попытку.
+// Отказывать тут некому: при переполнении запись отбрасывается с предупреждением в лог.
+func (s *service) recordAuditAsync(ctx context.Context, e *entities.AuditEntry, before, after interface{}) {
+	select {
+	case s.auditQueue <- queuedAudit{ctx: audit.Detach(ctx), entry: e, before: before, after: after}:
+	default:
+		s.log.Warnf("audit queue full, dropping %s %s/%s", e.Action, e.TargetType, e.TargetID)
+	}
+}
+
+// FlushAuditQueue пишет накопленные фоновые записи журнала, по auditFlushBatch за транзакцию.
+func (s *service) FlushAuditQueue(ctx context.Context) error {
+	for {
+		batch := s.takeAuditBatch()
+		if len(batch) == 0 {
+			return nil
+		}
+		err := s.inTx(ctx, func(tx database.Transaction) error {
+			for _, q := range batch {
+				if err := s.auditTx(q.ctx, tx, q.entry, q.before, q.after); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("flush audit queue: %d entries lost: %w", len(batch), err)
+		}
+		if len(batch) < auditFlushBatch {
+			return nil
+		}
+	}
+}
+
+// takeAuditBatch забирает из очереди до auditFlushBatch записей, не дожидаясь новых.
+func (s *service) takeAuditBatch() []queuedAudit {
+	var batch []queuedAudit
+	for len(batch) < auditFlushBatch {
+		select {
+		case q := <-s.auditQueue:
+			batch = append(batch, q)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// userAuditEntry — запись о пользователе, который сам выступает исполнителем (например, вход).
+func userAuditEntry(user *entities.User, action entities.AuditAction) *entities.AuditEntry {
+	id := user.ID
+	return &entities.AuditEntry{
+		ActorID:    &id,
+		ActorRole:  string(user.Role),
+		Action:     action,
+		TargetType: entities.AuditTargetUser,
+		TargetID:   auditID(user.ID),
+	}
+}
+
+// auditID — id объекта в target_id.
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// userAuditView — поля пользователя для журнала; хэш пароля туда не попадает.
+func userAuditView(u *entities.User) map[string]interface{} {
+	if u == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"email":          u.Email,
+		"role":           u.Role,
+		"balance":        u.Balance,
+		"email_verified": u.EmailVerified(),
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"go.uber.org/zap"
+)
+
+func TestAuditQueueBounded(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		pushed    int
+		wantBatch []int
+	}{
+		{"empty", 10, 0, nil},
+		{"single batch", 10, 3, []int{3}},
+		{"overflow dropped", 5, 8, []int{5}},
+		{"split into batches", 3 * auditFlushBatch, 2*auditFlushBatch + 1, []int{auditFlushBatch, auditFlushBatch, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{log: zap.NewNop().Sugar(), auditQueue: make(chan queuedAudit, tt.size)}
+			for i := 0; i < tt.pushed; i++ {
+				s.recordAuditAsync(context.Background(), &entities.AuditEntry{Action: entities.AuditLoginFailed}, nil, nil)
+			}
+
+			var got []int
+			for batch := s.takeAuditBatch(); len(batch) > 0; batch = s.takeAuditBatch() {
+				got = append(got, len(batch))
+			}
+			if len(got) != len(tt.wantBatch) {
+				t.Fatalf("batches = %v, want %v", got, tt.wantBatch)
+			}
+			for i := range got {
+				if got[i] != tt.wantBatch[i] {
+					t.Errorf("batches = %v, want %v", got, tt.wantBatch)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// GetCandles возвращает бары OHLCV за [from, to). Начало диапазона выравнивается
+// по ширине бара, чтобы первый бар не был обрезан.
+func (s *service) GetCandles(ctx context.Context, stockID int64, interval entities.CandleInterval, from, to time.Time) ([]*entities.Candle, error) {
+	width := interval.Duration()
+	if width == 0 {
+		return nil, entities.ErrInvalidCandleInterval
+	}
+	from = from.UTC().Truncate(width)
+	if !from.Before(to) {
+		return nil, entities.ErrInvalidCandleRange
+	}
+
+	candles, err := s.pgRepository.GetCandles(ctx, stockID, width, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if candles == nil {
+		candles = []*entities.Candle{}
+	}
+	return candles, nil
+}
+
+// CompactPriceTicks сворачивает тики старше окна хранения в минутные бары.
+func (s *service) CompactPriceTicks(ctx context.Context) error {
+	retention := time.Duration(s.config.PriceTickRetentionHours) * time.Hour
+	until := time.Now().Add(-retention).UTC().Truncate(time.Minute)
+
+	var bars int64
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		bars, err = s.pgRepository.CompactPriceTicksTx(ctx, tx, until)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if bars > 0 {
+		s.log.Infof("CompactPriceTicks: %d minute bars written up to %s", bars, until.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// addLotTx открывает лот на купленные бумаги.
+func (s *service) addLotTx(ctx context.Context, tx database.Transaction, userID, stockID int64, orderID *int64, qty, price decimal.Decimal) error {
+	_, err := s.pgRepository.CreateTaxLotTx(ctx, tx, &entities.TaxLot{
+		UserID:   userID,
+		StockID:  stockID,
+		OrderID:  orderID,
+		Quantity: qty,
+		Price:    price,
+	})
+	return err
+}
+
+// relieveLotsTx списывает qty из лотов позиции по методу пользователя и возвращает
+// реализованный P&L продажи по price. Бумаги, не покрытые лотами (начисленные до учёта
+// себестоимости), считаются купленными по цене продажи.
+func (s *service) relieveLotsTx(ctx context.Context, tx database.Transaction, userID, stockID int64, qty, price decimal.Decimal) (decimal.Decimal, error) {
+	user, err := s.pgRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	method := user.CostBasisMethod
+
+	lots, err := s.pgRepository.GetOpenTaxLotsForUpdateTx(ctx, tx, userID, stockID, method == entities.CostBasisLIFO)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	changed, pnl := relieveLots(lots, method, qty, price)
+	for _, lot := range changed {
+		if err := s.pgRepository.UpdateTaxLotTx(ctx, tx, lot.ID, lot.Remaining, lot.Price); err != nil {
+			return decimal.Zero, err
+		}
+	}
+	return pnl, nil
+}
+
+// relieveLots списывает qty из lots, уже упорядоченных под метод, и возвращает изменённые
+// лоты и реализованный P&L. Остаток, не покрытый лотами, считается купленным по price.
+func relieveLots(lots []*entities.TaxLot, method entities.CostBasisMethod, qty, price decimal.Decimal) ([]*entities.TaxLot, decimal.Decimal) {
+	// при AVERAGE все лоты переоцениваются по средней, поэтому порядок списания не важен
+	if method == entities.CostBasisAverage {
+		avg := averageCost(lots)
+		for _, lot := range lots {
+			lot.Price = avg
+		}
+	}
+
+	var changed []*entities.TaxLot
+	left := qty
+	cost := decimal.Zero
+	for _, lot := range lots {
+		used := decimal.Min(lot.Remaining, left)
+		if method != entities.CostBasisAverage && !used.IsPositive() {
+			break
+		}
+		cost = cost.Add(used.Mul(lot.Price))
+		left = left.Sub(used)
+		lot.Remaining = lot.Remaining.Sub(used)
+		changed = append(changed, lot)
+	}
+	cost = cost.Add(left.Mul(price))
+
+	return changed, qty.Mul(price).Sub(cost)
+}
+
+func averageCost(lots []*entities.TaxLot) decimal.Decimal {
+	qty, cost := decimal.Zero, decimal.Zero
+	for _, lot := range lots {
+		qty = qty.Add(lot.Remaining)
+		cost = cost.Add(lot.Remaining.Mul(lot.Price))
+	}
+	if !qty.IsPositive() {
+		return decimal.Zero
+	}
+	return cost.DivRound(qty, entities.Scale)
+}
+
+func (s *service) SetCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error {
+	if !method.Valid() {
+		return entities.ErrInvalidCostBasisMethod
+	}
+	return s.pgRepository.UpdateCostBasisMethod(ctx, userID, method)
+}
+
+// GetPortfolioValuation оценивает позиции пользователя по текущим ценам из stock_stock.
+func (s *service) GetPortfolioValuation(ctx context.Context, userID int64) (*entities.PortfolioValuation, error) {
+	portfolios, err := s.pgRepository.GetPortfoliosByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	lots, err := s.pgRepository.GetOpenTaxLotsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// идём мимо кэша stocks:all — нужны актуальные цены
+	stocks, err := s.pgRepository.GetAllStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stockByID := make(map[int64]*entities.Stock, len(stocks))
+	for _, st := range stocks {
+		stockByID[st.ID] = st
+	}
+	lotsByStock := make(map[int64][]*entities.TaxLot)
+	for _, lot := range lots {
+		lotsByStock[lot.StockID] = append(lotsByStock[lot.StockID], lot)
+	}
+
+	v := &entities.PortfolioValuation{Positions: []*entities.Position{}}
+	for _, p := range portfolios {
+		if !p.Quantity.IsPositive() {
+			continue
+		}
+
+		pos := &entities.Position{Portfolio: *p}
+		if st, ok := stockByID[p.StockID]; ok {
+			pos.Symbol = st.Symbol
+			pos.MarketPrice = st.Price
+		}
+
+		covered := decimal.Zero
+		for _, lot := range lotsByStock[p.StockID] {
+			covered = covered.Add(lot.Remaining)
+			pos.CostBasis = pos.CostBasis.Add(lot.Remaining.Mul(lot.Price))
+		}
+		// бумаги без лотов оцениваем по рынку, как и при списании
+		if uncovered := p.Quantity.Sub(covered); uncovered.IsPositive() {
+			pos.CostBasis = pos.CostBasis.Add(uncovered.Mul(pos.MarketPrice))
+		}
+
+		pos.AvgCost = pos.CostBasis.DivRound(p.Quantity, entities.Scale)
+		pos.MarketValue = p.Quantity.Mul(pos.MarketPrice)
+		pos.UnrealizedPnL = pos.MarketValue.Sub(pos.CostBasis)
+
+		v.Positions = append(v.Positions, pos)
+		v.CostBasis = v.CostBasis.Add(pos.CostBasis)
+		v.MarketValue = v.MarketValue.Add(pos.MarketValue)
+		v.UnrealizedPnL = v.UnrealizedPnL.Add(pos.UnrealizedPnL)
+	}
+	return v, nil
+}
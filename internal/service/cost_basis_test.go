@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+func TestRelieveLots(t *testing.T) {
+	// лоты в порядке FIFO: 10 по 100, затем 10 по 120
+	fifo := func() []*entities.TaxLot {
+		return []*entities.TaxLot{
+			{ID: 1, Remaining: dec("10"), Price: dec("100")},
+			{ID: 2, Remaining: dec("10"), Price: dec("120")},
+		}
+	}
+	lifo := func() []*entities.TaxLot {
+		lots := fifo()
+		return []*entities.TaxLot{lots[1], lots[0]}
+	}
+
+	tests := []struct {
+		name          string
+		lots          []*entities.TaxLot
+		method        entities.CostBasisMethod
+		qty, price    string
+		wantPnL       string
+		wantChanged   []int64
+		wantRemaining map[int64]string
+		wantPrice     map[int64]string
+	}{
+		{
+			name: "fifo within first lot", lots: fifo(), method: entities.CostBasisFIFO,
+			qty: "4", price: "130", wantPnL: "120",
+			wantChanged:   []int64{1},
+			wantRemaining: map[int64]string{1: "6", 2: "10"},
+		},
+		{
+			name: "fifo across lots", lots: fifo(), method: entities.CostBasisFIFO,
+			qty: "15", price: "130", wantPnL: "350",
+			wantChanged:   []int64{1, 2},
+			wantRemaining: map[int64]string{1: "0", 2: "5"},
+		},
+		{
+			name: "lifo takes newest first", lots: lifo(), method: entities.CostBasisLIFO,
+			qty: "15", price: "130", wantPnL: "250",
+			wantChanged:   []int64{2, 1},
+			wantRemaining: map[int64]string{1: "5", 2: "0"},
+		},
+		{
+			name: "average reprices all lots", lots: fifo(), method: entities.CostBasisAverage,
+			qty: "5", price: "130", wantPnL: "100",
+			wantChanged:   []int64{1, 2},
+			wantRemaining: map[int64]string{1: "5", 2: "10"},
+			wantPrice:     map[int64]string{1: "110", 2: "110"},
+		},
+		{
+			name: "uncovered quantity is priced at sale price", lots: fifo(), method: entities.CostBasisFIFO,
+			qty: "25", price: "130", wantPnL: "400",
+			wantChanged:   []int64{1, 2},
+			wantRemaining: map[int64]string{1: "0", 2: "0"},
+		},
+		{
+			name: "no lots", lots: nil, method: entities.CostBasisFIFO,
+			qty: "3", price: "50", wantPnL: "0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, pnl := relieveLots(tt.lots, tt.method, dec(tt.qty), dec(tt.price))
+			if !pnl.Equal(dec(tt.wantPnL)) {
+				t.Errorf("pnl = %s, want %s", pnl, tt.wantPnL)
+			}
+			if len(changed) != len(tt.wantChanged) {
+				t.Fatalf("changed %d lots, want %d", len(changed), len(tt.wantChanged))
+			}
+			for i, lot := range changed {
+				if lot.ID != tt.wantChanged[i] {
+					t.Errorf("changed[%d] = lot %d, want %d", i, lot.ID, tt.wantChanged[i])
+				}
+			}
+			for _, lot := range tt.lots {
+				if want, ok := tt.wantRemaining[lot.ID]; ok && !lot.Remaining.Equal(dec(want)) {
+					t.Errorf("lot %d remaining = %s, want %s", lot.ID, lot.Remaining, want)
+				}
+				if want, ok := tt.wantPrice[lot.ID]; ok && !lot.Price.Equal(dec(want)) {
+					t.Errorf("lot %d price = %s, want %s", lot.ID, lot.Price, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAverageCost(t *testing.T) {
+	tests := []struct {
+		name string
+		lots []*entities.TaxLot
+		want string
+	}{
+		{"empty", nil, "0"},
+		{"single lot", []*entities.TaxLot{{Remaining: dec("3"), Price: dec("10")}}, "10"},
+		{"weighted by remaining", []*entities.TaxLot{
+			{Remaining: dec("1"), Price: dec("10")},
+			{Remaining: dec("3"), Price: dec("20")},
+		}, "17.5"},
+		{"closed lots ignored", []*entities.TaxLot{
+			{Remaining: dec("0"), Price: dec("1000")},
+			{Remaining: dec("2"), Price: dec("20")},
+		}, "20"},
+		{"rounded to scale", []*entities.TaxLot{
+			{Remaining: dec("3"), Price: dec("1")},
+			{Remaining: dec("0"), Price: dec("0")},
+			{Remaining: dec("3"), Price: dec("1")},
+			{Remaining: dec("1"), Price: dec("0")},
+		}, "0.85714286"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := averageCost(tt.lots); !got.Equal(dec(tt.want)) {
+				t.Errorf("averageCost = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/mailer"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+const (
+	passwordResetLimitPrefix = "password-reset:"
+	mailTimeout              = time.Minute
+)
+
+// SendVerificationEmail отправляет (повторно) ссылку подтверждения email; старые ссылки перестают действовать.
+func (s *service) SendVerificationEmail(ctx context.Context, userID int64) error {
+	user, err := s.pgRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified() {
+		return entities.ErrEmailAlreadyVerified
+	}
+
+	ttl := time.Duration(s.config.EmailVerificationTTLHours) * time.Hour
+	token, err := s.issueEmailToken(ctx, user, auth.PurposeVerifyEmail, ttl)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, &mailer.Message{
+		To:      user.Email,
+		Subject: "Confirm your email",
+		Body: fmt.Sprintf("Confirm your email address by opening this link:\n\n%s\n\n"+
+			"The link expires in %d hours. If you did not create an account, ignore this email.\n",
+			s.emailLink("/verify-email", token), s.config.EmailVerificationTTLHours),
+	})
+}
+
+// VerifyEmail гасит токен из письма и отмечает email подтверждённым.
+func (s *service) VerifyEmail(ctx context.Context, token string) error {
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		t, err := s.consumeEmailToken(ctx, tx, token, auth.PurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+		if err := s.pgRepository.MarkEmailVerifiedTx(ctx, tx, t.UserID); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditEmailVerify,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(t.UserID),
+		}, nil, nil)
+	})
+}
+
+// RequestPasswordReset отправляет ссылку сброса пароля в фоне и ничего не сообщает о результате:
+// по ответу нельзя узнать, зарегистрирован ли адрес.
+func (s *service) RequestPasswordReset(_ context.Context, email string) {
+	if s.cache != nil && !s.cache.RateLimit(passwordResetLimitPrefix+loginEmailKey(email), int64(s.config.PasswordResetsPerHour)+1, time.Hour) {
+		s.log.Warnf("RequestPasswordReset: rate limit reached for %s", email)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+
+		user, err := s.pgRepository.GetUserByEmail(ctx, email)
+		if err != nil {
+			// неизвестный адрес — обычная ситуация, письмо просто не уходит
+			return
+		}
+
+		ttl := time.Duration(s.config.PasswordResetTTLMinutes) * time.Minute
+		token, err := s.issueEmailToken(ctx, user, auth.PurposeResetPassword, ttl)
+		if err != nil {
+			s.log.Errorf("RequestPasswordReset: failed to issue token (user=%d): %v", user.ID, err)
+			return
+		}
+
+		err = s.mailer.Send(ctx, &mailer.Message{
+			To:      user.Email,
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Set a new password by opening this link:\n\n%s\n\n"+
+				"The link expires in %d minutes. If you did not request a reset, ignore this email: your password stays the same.\n",
+				s.emailLink("/reset-password", token), s.config.PasswordResetTTLMinutes),
+		})
+		if err != nil {
+			s.log.Errorf("RequestPasswordReset: failed to send email (user=%d): %v", user.ID, err)
+		}
+	}()
+}
+
+// ResetPassword меняет пароль по токену из письма и завершает все сессии пользователя.
+// Переход по ссылке доказывает владение адресом, поэтому email заодно считается подтверждённым.
+func (s *service) ResetPassword(ctx context.Context, token, passwordHash string) error {
+	var userID int64
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		t, err := s.consumeEmailToken(ctx, tx, token, auth.PurposeResetPassword)
+		if err != nil {
+			return err
+		}
+		userID = t.UserID
+
+		if err := s.pgRepository.UpdatePasswordTx(ctx, tx, t.UserID, passwordHash); err != nil {
+			return err
+		}
+		if err := s.pgRepository.MarkEmailVerifiedTx(ctx, tx, t.UserID); err != nil {
+			return err
+		}
+		if err := s.pgRepository.RevokeUserRefreshTokensTx(ctx, tx, t.UserID); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditPasswordReset,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(t.UserID),
+		}, nil, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	user, err := s.pgRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	s.forgetCachedUser(user.Email)
+	if err := s.clearLoginLockout(user.Email); err != nil {
+		s.log.Warnf("ResetPassword: failed to clear login lockout (user=%d): %v", userID, err)
+	}
+	return nil
+}
+
+// sendVerificationAsync — письмо новому пользователю; ошибка отправки не мешает регистрации,
+// ссылку можно запросить повторно.
+func (s *service) sendVerificationAsync(userID int64) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := s.SendVerificationEmail(ctx, userID); err != nil {
+			s.log.Errorf("sendVerificationAsync: user=%d: %v", userID, err)
+		}
+	}()
+}
+
+// issueEmailToken выпускает подписанный токен и запоминает его jti для однократного использования.
+func (s *service) issueEmailToken(ctx context.Context, user *entities.User, purpose string, ttl time.Duration) (string, error) {
+	token, claims, err := auth.GenerateChallengeToken(s.keys, ttl, user.ID, purpose)
+	if err != nil {
+		return "", err
+	}
+	err = s.pgRepository.CreateUserToken(ctx, &entities.UserToken{
+		JTI:       claims.ID,
+		UserID:    user.ID,
+		Purpose:   purpose,
+		Email:     user.Email,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *service) consumeEmailToken(ctx context.Context, tx database.Transaction, token, purpose string) (*entities.UserToken, error) {
+	claims, err := auth.ParseChallengeToken(s.keys, token, purpose)
+	if err != nil {
+		return nil, entities.ErrInvalidEmailToken
+	}
+	t, err := s.pgRepository.ConsumeUserTokenTx(ctx, tx, claims.ID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || t.UserID != claims.UserID {
+		return nil, entities.ErrInvalidEmailToken
+	}
+	return t, nil
+}
+
+func (s *service) emailLink(path, token string) string {
+	return strings.TrimRight(s.config.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// forgetCachedUser убирает пользователя из кэша GetUserByEmail после смены пароля или адреса.
+func (s *service) forgetCachedUser(email string) {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Reset(userEmailCachePrefix + email); err != nil {
+		s.log.Warnf("forgetCachedUser: redis reset failed: %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+// publish рассылает событие подписчикам канала. Вызывается после коммита;
+// ошибка рассылки не откатывает операцию, только логируется.
+func (s *service) publish(ctx context.Context, channel string, typ entities.StreamEventType, data interface{}) {
+	if s.publisher == nil {
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.log.Errorf("publish %s to %s: %v", typ, channel, err)
+		return
+	}
+	if err := s.publisher.Publish(ctx, &entities.StreamEvent{
+		Channel: channel,
+		Type:    typ,
+		Data:    payload,
+	}); err != nil {
+		s.log.Warnf("publish %s to %s: %v", typ, channel, err)
+	}
+}
+
+func (s *service) publishPrice(ctx context.Context, stock *entities.Stock) {
+	s.publish(ctx, entities.PriceChannel(stock.Symbol), entities.EventPrice, entities.PriceEvent{
+		StockID: stock.ID,
+		Symbol:  stock.Symbol,
+		Price:   stock.Price,
+	})
+}
+
+func (s *service) publishOrder(ctx context.Context, o *entities.Order) {
+	s.publish(ctx, entities.UserChannel(o.UserID), entities.EventOrder, entities.OrderEvent{
+		OrderID:        o.ID,
+		StockID:        o.StockID,
+		Type:           o.OrderType,
+		Kind:           o.Kind,
+		Status:         o.Status,
+		Quantity:       o.Quantity,
+		FilledQuantity: o.FilledQuantity,
+		AvgFillPrice:   o.AvgFillPrice,
+	})
+}
+
+func (s *service) publishFill(ctx context.Context, o *entities.Order, tradeID int64, price, qty decimal.Decimal) {
+	s.publish(ctx, entities.UserChannel(o.UserID), entities.EventFill, entities.FillEvent{
+		TradeID:  tradeID,
+		OrderID:  o.ID,
+		StockID:  o.StockID,
+		Side:     o.OrderType,
+		Price:    price,
+		Quantity: qty,
+	})
+}
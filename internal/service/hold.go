@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// holdFor считает резерв под заявку: под BUY — деньги по худшей ожидаемой цене,
+// под SELL — бумаги.
+func holdFor(order *entities.Order, marketPrice decimal.Decimal) *entities.Hold {
+	h := &entities.Hold{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		StockID: order.StockID,
+		Kind:    entities.HoldShares,
+		Amount:  order.Quantity,
+	}
+	if order.OrderType == entities.OrderSell {
+		return h
+	}
+
+	price := marketPrice
+	switch {
+	case order.LimitPrice != nil:
+		price = *order.LimitPrice
+	case order.TriggerPrice != nil:
+		price = *order.TriggerPrice
+	case order.TrailingOffset != nil:
+		price = marketPrice.Add(*order.TrailingOffset)
+	}
+
+	h.Kind = entities.HoldCash
+	h.Amount = price.Mul(order.Quantity)
+	return h
+}
+
+// placeHold резервирует деньги или бумаги под только что созданную заявку.
+func (s *service) placeHold(ctx context.Context, order *entities.Order, marketPrice decimal.Decimal) error {
+	h := holdFor(order, marketPrice)
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		switch h.Kind {
+		case entities.HoldCash:
+			if err := s.pgRepository.ReserveBalanceTx(ctx, tx, h.UserID, h.Amount); err != nil {
+				return err
+			}
+		case entities.HoldShares:
+			if err := s.pgRepository.LockSharesTx(ctx, tx, h.UserID, h.StockID, h.Amount); err != nil {
+				return err
+			}
+		}
+		_, err := s.pgRepository.CreateHoldTx(ctx, tx, h)
+		return err
+	})
+}
+
+// consumeHoldTx списывает amount из резерва заявки перед исполнением: освобождённое
+// тут же тратится вызывающим кодом. Если исполнение дороже резерва, разница идёт
+// из свободного баланса.
+func (s *service) consumeHoldTx(ctx context.Context, tx database.Transaction, orderID int64, amount decimal.Decimal) error {
+	h, err := s.pgRepository.GetHoldForUpdateTx(ctx, tx, orderID)
+	if err != nil || h == nil || h.Status != entities.HoldActive {
+		return err
+	}
+
+	used := decimal.Min(h.Remaining, amount)
+	if err := s.releaseHeld(ctx, tx, h, used); err != nil {
+		return err
+	}
+
+	remaining := h.Remaining.Sub(used)
+	status := entities.HoldActive
+	if !remaining.IsPositive() {
+		status = entities.HoldConsumed
+	}
+	return s.pgRepository.UpdateHoldTx(ctx, tx, h.ID, remaining, status)
+}
+
+// releaseHoldTx возвращает неиспользованный остаток резерва, когда заявка закрылась.
+func (s *service) releaseHoldTx(ctx context.Context, tx database.Transaction, orderID int64) error {
+	h, err := s.pgRepository.GetHoldForUpdateTx(ctx, tx, orderID)
+	if err != nil || h == nil || h.Status != entities.HoldActive {
+		return err
+	}
+
+	if err := s.releaseHeld(ctx, tx, h, h.Remaining); err != nil {
+		return err
+	}
+	return s.pgRepository.UpdateHoldTx(ctx, tx, h.ID, decimal.Zero, entities.HoldReleased)
+}
+
+func (s *service) releaseHeld(ctx context.Context, tx database.Transaction, h *entities.Hold, amount decimal.Decimal) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+	if h.Kind == entities.HoldCash {
+		return s.pgRepository.ReleaseBalanceTx(ctx, tx, h.UserID, amount)
+	}
+	return s.pgRepository.UnlockSharesTx(ctx, tx, h.UserID, h.StockID, amount)
+}
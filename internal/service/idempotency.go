@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+const (
+	idempotencyCachePrefix = "idempotency:"
+	// пока запрос обрабатывается, ключ живёт недолго — упавший процесс не заблокирует его на весь TTL
+	idempotencyLockTTL = time.Minute
+)
+
+// BeginIdempotent пытается занять ключ. Если ключ уже занят, возвращает существующую
+// запись и acquired=false. Основное хранилище — Redis, при его недоступности — Postgres.
+func (s *service) BeginIdempotent(ctx context.Context, key, requestHash string, ttl time.Duration) (*entities.IdempotencyRecord, bool, error) {
+	rec := &entities.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().Add(min(ttl, idempotencyLockTTL)),
+	}
+
+	if s.cache != nil {
+		acquired, err := s.cache.StoreNX(idempotencyCachePrefix+key, rec, min(ttl, idempotencyLockTTL))
+		if err == nil {
+			if acquired {
+				return rec, true, nil
+			}
+
+			var existing entities.IdempotencyRecord
+			if err := s.cache.Get(idempotencyCachePrefix+key, &existing, false); err == nil {
+				return &existing, false, nil
+			}
+			// ключ успел истечь между SETNX и GET — пусть клиент повторит
+			return rec, false, nil
+		}
+		s.log.Warnf("BeginIdempotent: redis unavailable, falling back to postgres: %v", err)
+	}
+
+	acquired, err := s.pgRepository.InsertIdempotencyKey(ctx, rec)
+	if err != nil {
+		return nil, false, err
+	}
+	if acquired {
+		return rec, true, nil
+	}
+
+	existing, err := s.pgRepository.GetIdempotencyKey(ctx, key)
+	if err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// CompleteIdempotent сохраняет итоговый ответ для повторов.
+func (s *service) CompleteIdempotent(ctx context.Context, rec *entities.IdempotencyRecord, ttl time.Duration) error {
+	rec.ExpiresAt = time.Now().Add(ttl)
+
+	if s.cache != nil {
+		// истёкший ключ мог занять другой запрос — его блокировку не затираем
+		var existing entities.IdempotencyRecord
+		if err := s.cache.Get(idempotencyCachePrefix+rec.Key, &existing, false); err == nil && existing.RequestHash != rec.RequestHash {
+			return entities.ErrIdempotencyKeyTaken
+		}
+		// SET без проверки наличия: запрос дольше idempotencyLockTTL переживает свой ключ,
+		// а ответ всё равно нужно сохранить, иначе повтор исполнится заново
+		err := s.cache.Store(idempotencyCachePrefix+rec.Key, rec, ttl, false)
+		if err == nil {
+			return nil
+		}
+		s.log.Warnf("CompleteIdempotent: redis unavailable, falling back to postgres: %v", err)
+	}
+
+	stored, err := s.pgRepository.CompleteIdempotencyKey(ctx, rec)
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return entities.ErrIdempotencyKeyTaken
+	}
+	return nil
+}
+
+// ReleaseIdempotent освобождает ключ, чтобы запрос можно было честно повторить (например, после 5xx).
+func (s *service) ReleaseIdempotent(ctx context.Context, key string) error {
+	if s.cache != nil && s.cache.ExistKey(idempotencyCachePrefix+key) {
+		return s.cache.Reset(idempotencyCachePrefix + key)
+	}
+	return s.pgRepository.DeleteIdempotencyKey(ctx, key)
+}
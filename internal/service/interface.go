@@ -2,8 +2,11 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/pricefeed"
+	"github.com/shopspring/decimal"
 )
 
 type Service interface {
@@ -14,19 +17,79 @@ type Service interface {
 	DeleteUser(ctx context.Context, id int64) error
 	GetAllUsers(ctx context.Context) ([]*entities.User, error)
 
+	RegisterUser(ctx context.Context, user *entities.User, inviteCode string) (int64, error)
+	ProvisionUser(ctx context.Context, user *entities.User) (int64, error)
+	CreateInvite(ctx context.Context, inv *entities.Invite, ttl time.Duration) (string, error)
+	ListInvites(ctx context.Context) ([]*entities.Invite, error)
+	RevokeInvite(ctx context.Context, id int64) error
+	BootstrapAdmin(ctx context.Context, email, passwordHash string) (int64, error)
+
 	CreateStock(ctx context.Context, stock *entities.Stock) (int64, error)
 	GetStockByID(ctx context.Context, id int64) (*entities.Stock, error)
 	GetAllStocks(ctx context.Context) ([]*entities.Stock, error)
 	UpdateStock(ctx context.Context, stock *entities.Stock) error
 	DeleteStock(ctx context.Context, id int64) error
+	GetCandles(ctx context.Context, stockID int64, interval entities.CandleInterval, from, to time.Time) ([]*entities.Candle, error)
+	CompactPriceTicks(ctx context.Context) error
+	ApplyPriceFeed(ctx context.Context, feed pricefeed.Feed) error
 	CreateOrder(ctx context.Context, order *entities.Order) (int64, error)
 	UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error
+	CancelOrder(ctx context.Context, orderID int64) error
 	GetOrdersByUserID(ctx context.Context, userID int64) ([]*entities.Order, error)
 	GetOrderByID(ctx context.Context, orderID int64) (*entities.Order, error)
 	ExecuteOrder(ctx context.Context, order *entities.Order) error
+	RestoreOrderBooks(ctx context.Context) error
+	EvaluateStopOrders(ctx context.Context) error
+	ExpireOrders(ctx context.Context) error
 	GetPortfolio(ctx context.Context, userID, stockID int64) (*entities.Portfolio, error)
 	CreateOrUpdatePortfolio(ctx context.Context, p *entities.Portfolio) error
 	GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*entities.Portfolio, error)
+	GetPortfolioValuation(ctx context.Context, userID int64) (*entities.PortfolioValuation, error)
+	SetCostBasisMethod(ctx context.Context, userID int64, method entities.CostBasisMethod) error
 	AddHistoryRecord(ctx context.Context, h *entities.History) (int64, error)
 	GetHistoryByUserID(ctx context.Context, userID int64) ([]*entities.History, error)
+
+	Deposit(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error)
+	Withdraw(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error)
+	GetLedgerEntries(ctx context.Context, userID int64) ([]*entities.LedgerEntry, error)
+	ReconcileLedger(ctx context.Context) ([]*entities.BalanceMismatch, error)
+
+	BeginIdempotent(ctx context.Context, key, requestHash string, ttl time.Duration) (*entities.IdempotencyRecord, bool, error)
+	CompleteIdempotent(ctx context.Context, rec *entities.IdempotencyRecord, ttl time.Duration) error
+	ReleaseIdempotent(ctx context.Context, key string) error
+
+	CheckCredentials(ctx context.Context, email, password, clientIP string) (*entities.User, error)
+	UnlockUser(ctx context.Context, userID int64) error
+	SendVerificationEmail(ctx context.Context, userID int64) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string)
+	ResetPassword(ctx context.Context, token, passwordHash string) error
+	IssueTokens(ctx context.Context, user *entities.User) (*entities.TokenPair, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*entities.TokenPair, error)
+	RevokeTokens(ctx context.Context, userID int64, jti string, accessExpiresAt time.Time, refreshToken string) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	PurgeExpiredTokens(ctx context.Context) error
+
+	GetRolePermissions(ctx context.Context, role entities.Role) (entities.PermissionSet, error)
+	ListRoles(ctx context.Context) ([]*entities.RoleInfo, error)
+	ListPermissions(ctx context.Context) ([]*entities.PermissionInfo, error)
+	SetRolePermissions(ctx context.Context, role *entities.RoleInfo) error
+
+	CreateAPIKey(ctx context.Context, k *entities.APIKey) (string, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]*entities.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID, id int64) error
+	AuthenticateAPIKey(ctx context.Context, key, clientIP string) (*entities.APIKey, *entities.User, error)
+
+	EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, userID int64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+	GetTwoFactorStatus(ctx context.Context, userID int64) (*entities.TwoFactorStatus, error)
+	BeginLogin(ctx context.Context, user *entities.User) (*entities.TokenPair, *entities.LoginChallenge, error)
+	CompleteLogin(ctx context.Context, challengeToken, code string) (*entities.TokenPair, error)
+	VerifyStepUp(ctx context.Context, userID int64, code string) error
+
+	GetAuditLog(ctx context.Context, f *entities.AuditFilter) ([]*entities.AuditEntry, error)
+	VerifyAuditLog(ctx context.Context) (*entities.AuditVerification, error)
+	FlushAuditQueue(ctx context.Context) error
 }
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// transfer — перевод amount со счёта from на счёт to одной двойной проводкой.
+type transfer struct {
+	Kind      entities.JournalKind
+	Reference string
+	From      entities.LedgerAccount
+	To        entities.LedgerAccount
+	Amount    decimal.Decimal
+}
+
+// transferTx — единственный способ менять stock_user.balance: проводка в леджер
+// и кэшированный баланс пользователей обновляются в одной транзакции.
+func (s *service) transferTx(ctx context.Context, tx database.Transaction, t transfer) (int64, error) {
+	if !t.Amount.IsPositive() {
+		return 0, entities.ErrInvalidAmount
+	}
+
+	if t.From.UserID != nil {
+		if err := s.pgRepository.DebitBalanceTx(ctx, tx, *t.From.UserID, t.Amount); err != nil {
+			return 0, err
+		}
+	}
+	if t.To.UserID != nil {
+		if err := s.pgRepository.CreditBalanceTx(ctx, tx, *t.To.UserID, t.Amount); err != nil {
+			return 0, err
+		}
+	}
+
+	return s.pgRepository.PostJournalTx(ctx, tx, &entities.LedgerJournal{
+		Kind:      t.Kind,
+		Reference: t.Reference,
+	}, map[*entities.LedgerAccount]decimal.Decimal{
+		&t.From: t.Amount.Neg(),
+		&t.To:   t.Amount,
+	})
+}
+
+func (s *service) Deposit(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error) {
+	return s.moveFunds(ctx, userID, amount, entities.JournalDeposit)
+}
+
+func (s *service) Withdraw(ctx context.Context, userID int64, amount decimal.Decimal) (int64, error) {
+	return s.moveFunds(ctx, userID, amount, entities.JournalWithdraw)
+}
+
+// moveFunds проводит ввод/вывод средств между внешним миром и денежным счётом пользователя.
+func (s *service) moveFunds(ctx context.Context, userID int64, amount decimal.Decimal, kind entities.JournalKind) (int64, error) {
+	if !amount.IsPositive() {
+		return 0, entities.ErrInvalidAmount
+	}
+
+	t := transfer{
+		Kind:      kind,
+		Reference: fmt.Sprintf("user:%d", userID),
+		From:      entities.SystemAccount(entities.AccountExternal),
+		To:        entities.UserCashAccount(userID),
+		Amount:    amount,
+	}
+	action := entities.ActionDeposit
+	if kind == entities.JournalWithdraw {
+		t.From, t.To = t.To, t.From
+		action = entities.ActionWithdraw
+	}
+
+	var journalID int64
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		var err error
+		if journalID, err = s.transferTx(ctx, tx, t); err != nil {
+			return err
+		}
+		_, err = s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+			UserID:  userID,
+			Action:  action,
+			Details: fmt.Sprintf("Journal #%d", journalID),
+			Amount:  amount,
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return journalID, nil
+}
+
+// adjustBalanceTx доводит баланс пользователя до target корректирующей проводкой
+// в транзакции вызывающего: изменение профиля и проводка коммитятся вместе.
+func (s *service) adjustBalanceTx(ctx context.Context, tx database.Transaction, userID int64, current, target decimal.Decimal) error {
+	delta := target.Sub(current)
+	if delta.IsZero() {
+		return nil
+	}
+
+	t := transfer{
+		Kind:      entities.JournalAdjustment,
+		Reference: fmt.Sprintf("user:%d", userID),
+		From:      entities.SystemAccount(entities.AccountAdjustment),
+		To:        entities.UserCashAccount(userID),
+		Amount:    delta,
+	}
+	if delta.IsNegative() {
+		t.From, t.To = t.To, t.From
+		t.Amount = delta.Neg()
+	}
+
+	if _, err := s.transferTx(ctx, tx, t); err != nil {
+		return err
+	}
+	_, err := s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+		UserID:  userID,
+		Action:  entities.ActionBalanceUpdate,
+		Details: "Balance adjustment",
+		Amount:  delta,
+	})
+	return err
+}
+
+func (s *service) GetLedgerEntries(ctx context.Context, userID int64) ([]*entities.LedgerEntry, error) {
+	return s.pgRepository.GetLedgerEntriesByAccount(ctx, entities.UserCashAccount(userID).Code)
+}
+
+// ReconcileLedger сверяет кэшированные балансы с леджером и логирует расхождения.
+func (s *service) ReconcileLedger(ctx context.Context) ([]*entities.BalanceMismatch, error) {
+	mismatches, err := s.pgRepository.GetBalanceMismatches(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range mismatches {
+		s.log.Errorf("ledger mismatch: user=%d balance=%s ledger=%s", m.UserID, m.Balance, m.LedgerBalance)
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// Ключи защиты от подбора пароля. Счётчики ведутся и по несуществующим email,
+// иначе по блокировкам можно было бы понять, какие адреса зарегистрированы.
+const (
+	loginFailPrefix  = "login-fail:"  // неудачи в текущем окне (cache.RateLimit)
+	loginLockPrefix  = "login-lock:"  // активная блокировка, значение — unix-время окончания
+	loginLevelPrefix = "login-level:" // сколько блокировок подряд: от этого растёт их длительность
+
+	loginLevelTTL = 24 * time.Hour
+)
+
+// loginSubject — то, по чему считаются неудачи: email или IP клиента.
+type loginSubject struct {
+	key         string
+	maxAttempts int
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash — с ним сверяется пароль неизвестного email, чтобы ответ занимал столько же,
+// сколько проверка настоящего пароля.
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = auth.HashPassword("timing-equalizer")
+	})
+	return dummyHash
+}
+
+// CheckCredentials проверяет email и пароль с учётом блокировок. Неизвестный email и неверный
+// пароль неразличимы: одна ошибка и одинаковое время ответа.
+func (s *service) CheckCredentials(ctx context.Context, email, password, clientIP string) (*entities.User, error) {
+	subjects := s.loginSubjects(email, clientIP)
+	if retryAfter := s.loginLockedFor(subjects); retryAfter > 0 {
+		return nil, &entities.LoginLockedError{RetryAfter: retryAfter}
+	}
+
+	// ошибку не различаем: нет пользователя или БД недоступна — для клиента это одно и то же
+	user, err := s.GetUserByEmail(ctx, email)
+	if err != nil {
+		user = nil
+	}
+
+	hash := dummyPasswordHash()
+	if user != nil {
+		hash = user.Password
+	}
+	if !auth.CheckPasswordHash(hash, password) || user == nil {
+		s.recordLoginFailure(ctx, subjects, user, clientIP)
+		s.recordAuditAsync(ctx, &entities.AuditEntry{
+			Action:     entities.AuditLoginFailed,
+			TargetType: entities.AuditTargetEmail,
+			TargetID:   strings.ToLower(strings.TrimSpace(email)),
+		}, nil, nil)
+		return nil, entities.ErrInvalidCredentials
+	}
+
+	// успешный вход сбрасывает счётчик по email, но не по IP: иначе подбирающий
+	// сбрасывал бы его входом в свой аккаунт
+	if s.cache != nil {
+		key := subjects[0].key
+		if err := s.cache.ResetMany(loginFailPrefix+key, loginLevelPrefix+key); err != nil {
+			s.log.Warnf("CheckCredentials: failed to reset login counters: %v", err)
+		}
+	}
+	return user, nil
+}
+
+// UnlockUser снимает блокировки входа пользователя: по паролю и по второму фактору.
+func (s *service) UnlockUser(ctx context.Context, userID int64) error {
+	user, err := s.pgRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.clearLoginLockout(user.Email); err != nil {
+		return err
+	}
+	if err := s.pgRepository.ResetTOTPLockout(ctx, userID); err != nil {
+		return err
+	}
+
+	// блокировка живёт в Redis, поэтому журнал пишется после её снятия; ошибка журнала — ошибка запроса
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		if _, err := s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+			UserID:  userID,
+			Action:  entities.ActionLoginUnlock,
+			Details: "login lockout cleared by administrator",
+			Amount:  decimal.Zero,
+		}); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditUserUnlock,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(userID),
+		}, nil, nil)
+	})
+}
+
+// clearLoginLockout снимает блокировку и счётчики по email; блокировки по IP истекают сами.
+func (s *service) clearLoginLockout(email string) error {
+	if s.cache == nil {
+		return nil
+	}
+	key := loginEmailKey(email)
+	return s.cache.ResetMany(loginLockPrefix+key, loginFailPrefix+key, loginLevelPrefix+key)
+}
+
+func (s *service) loginSubjects(email, clientIP string) []loginSubject {
+	subjects := []loginSubject{{key: loginEmailKey(email), maxAttempts: s.config.LoginMaxAttemptsPerEmail}}
+	if clientIP != "" {
+		subjects = append(subjects, loginSubject{key: "ip:" + clientIP, maxAttempts: s.config.LoginMaxAttemptsPerIP})
+	}
+	return subjects
+}
+
+func loginEmailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// loginLockedFor — сколько ещё ждать; 0 — вход открыт. Без Redis защита выключена.
+func (s *service) loginLockedFor(subjects []loginSubject) time.Duration {
+	if s.cache == nil {
+		return 0
+	}
+
+	var longest time.Duration
+	for _, sub := range subjects {
+		var until int64
+		if err := s.cache.Get(loginLockPrefix+sub.key, &until, false); err != nil {
+			continue
+		}
+		if d := time.Until(time.Unix(until, 0)); d > longest {
+			longest = d
+		}
+	}
+	if longest > 0 && longest < time.Second {
+		longest = time.Second
+	}
+	return longest.Round(time.Second)
+}
+
+// recordLoginFailure считает неудачу по каждому субъекту и блокирует тех, кто исчерпал лимит.
+func (s *service) recordLoginFailure(ctx context.Context, subjects []loginSubject, user *entities.User, clientIP string) {
+	if s.cache == nil {
+		return
+	}
+
+	window := time.Duration(s.config.LoginAttemptWindowMinutes) * time.Minute
+	for _, sub := range subjects {
+		if s.cache.RateLimit(loginFailPrefix+sub.key, int64(sub.maxAttempts), window) {
+			continue
+		}
+		d, ok := s.lockLogin(sub.key)
+		if !ok {
+			continue
+		}
+		s.log.Warnf("login lockout: %s for %s", sub.key, d)
+
+		kind, value, _ := strings.Cut(sub.key, ":")
+		s.recordAuditAsync(ctx, &entities.AuditEntry{
+			Action:     entities.AuditLoginLockout,
+			TargetType: kind,
+			TargetID:   value,
+		}, nil, map[string]interface{}{"lockout": d.String()})
+
+		if user != nil {
+			// пишем в фоне: запись в БД только для существующих пользователей выдала бы их по времени ответа
+			details := fmt.Sprintf("login locked for %s after repeated failures (%s, ip %s)", d, strings.SplitN(sub.key, ":", 2)[0], clientIP)
+			go func(userID int64) {
+				if _, err := s.AddHistoryRecord(context.Background(), &entities.History{
+					UserID:  userID,
+					Action:  entities.ActionLoginLockout,
+					Details: details,
+					Amount:  decimal.Zero,
+				}); err != nil {
+					s.log.Errorf("recordLoginFailure: history (user=%d): %v", userID, err)
+				}
+			}(user.ID)
+		}
+	}
+}
+
+// lockLogin ставит блокировку: базовая длительность удваивается с каждой блокировкой подряд.
+func (s *service) lockLogin(key string) (time.Duration, bool) {
+	level, err := s.cache.Incr(loginLevelPrefix + key)
+	if err != nil {
+		s.log.Warnf("lockLogin: redis incr failed: %v", err)
+		return 0, false
+	}
+	if err := s.cache.Expire(loginLevelPrefix+key, loginLevelTTL); err != nil {
+		s.log.Warnf("lockLogin: redis expire failed: %v", err)
+	}
+
+	d := time.Duration(s.config.LoginLockoutBaseSeconds) * time.Second
+	maxLockout := time.Duration(s.config.LoginLockoutMaxMinutes) * time.Minute
+	for i := uint64(1); i < level && d < maxLockout; i++ {
+		d *= 2
+	}
+	if d > maxLockout {
+		d = maxLockout
+	}
+
+	if err := s.cache.Store(loginLockPrefix+key, time.Now().Add(d).Unix(), d, false); err != nil {
+		s.log.Warnf("lockLogin: redis store failed: %v", err)
+		return 0, false
+	}
+	if err := s.cache.Reset(loginFailPrefix + key); err != nil {
+		s.log.Warnf("lockLogin: redis reset failed: %v", err)
+	}
+	return d, true
+}
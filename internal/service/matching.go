@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/orderbook"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/shopspring/decimal"
+)
+
+// placeLimitOrder сводит лимитную заявку со встречными, остаток ставит в стакан.
+// Заявка уже должна быть сохранена в stock_order.
+func (s *service) placeLimitOrder(ctx context.Context, order *entities.Order) error {
+	if err := s.checkLimitOrder(ctx, order); err != nil {
+		s.failOrder(ctx, order, err)
+		return err
+	}
+
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now()
+	}
+
+	book := s.orderBook.Book(order.StockID)
+	book.Lock()
+	defer book.Unlock()
+
+	// заявку могли закрыть до того, как она дошла до стакана (например, стоп-лимитную
+	// сразу после срабатывания) — закрытую в стакан не ставим
+	current, err := s.pgRepository.GetOrderByID(ctx, order.ID)
+	if err != nil {
+		s.failOrder(ctx, order, err)
+		return err
+	}
+	if !current.Status.IsOpen() {
+		*order = *current
+		return nil
+	}
+
+	taker := entryFromOrder(order)
+
+	if order.TimeInForce == entities.TimeInForceFOK && book.Available(taker).LessThan(taker.Remaining) {
+		return s.cancelRemainder(ctx, order, "FOK order cancelled: not enough liquidity")
+	}
+
+	fills, selfMatch := book.Match(taker)
+	for _, fill := range fills {
+		if err := s.settleFill(ctx, order, fill); err != nil {
+			s.log.Errorf("settleFill failed (order=%d maker=%d): %v", order.ID, fill.MakerOrderID, err)
+			// остаток заявки снимаем, а стакан в памяти уже изменён — пересобираем его из БД
+			s.failOrder(ctx, order, err)
+			if reloadErr := s.reloadBook(ctx, book); reloadErr != nil {
+				s.log.Errorf("reloadBook failed (stock=%d): %v", book.StockID, reloadErr)
+			}
+			return err
+		}
+	}
+
+	if taker.Remaining.IsPositive() && selfMatch {
+		return s.cancelRemainder(ctx, order, "remainder cancelled: would trade with own order")
+	}
+	if taker.Remaining.IsPositive() && order.TimeInForce == entities.TimeInForceIOC {
+		return s.cancelRemainder(ctx, order, "IOC remainder cancelled")
+	}
+
+	book.Add(taker)
+	return nil
+}
+
+// cancelRemainder отменяет неисполненный остаток заявки, которая не должна вставать в стакан.
+// Вызывается под локом стакана.
+func (s *service) cancelRemainder(ctx context.Context, order *entities.Order, details string) error {
+	if err := s.closeOrderTx(ctx, order.ID, entities.OrderCancelled, details, nil); err != nil {
+		return err
+	}
+	order.Status = entities.OrderCancelled
+	return nil
+}
+
+// checkLimitOrder проверяет параметры лимитной заявки. Деньги или бумаги на всю
+// заявку уже зарезервированы при создании (см. placeHold).
+func (s *service) checkLimitOrder(ctx context.Context, order *entities.Order) error {
+	if order.LimitPrice == nil || !order.LimitPrice.IsPositive() {
+		return errors.New("limit price must be positive")
+	}
+
+	switch order.OrderType {
+	case entities.OrderBuy, entities.OrderSell:
+		return nil
+	default:
+		return errors.New("unknown order type")
+	}
+}
+
+// settleFill в одной транзакции записывает сделку, переводит деньги и бумаги
+// между покупателем и продавцом, обновляет обе заявки и историю.
+func (s *service) settleFill(ctx context.Context, taker *entities.Order, fill orderbook.Fill) error {
+	var (
+		settledTaker entities.Order
+		buy, sell    *entities.Order
+		tradeID      int64
+	)
+
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		maker, err := s.pgRepository.GetOrderForUpdateTx(ctx, tx, fill.MakerOrderID)
+		if err != nil {
+			return err
+		}
+
+		settledTaker = *taker
+		buy, sell = &settledTaker, maker
+		if fill.TakerSide == entities.OrderSell {
+			buy, sell = maker, &settledTaker
+		}
+
+		tradeID, err = s.pgRepository.CreateTradeTx(ctx, tx, &entities.Trade{
+			StockID:     taker.StockID,
+			BuyOrderID:  &buy.ID,
+			SellOrderID: &sell.ID,
+			BuyerID:     &buy.UserID,
+			SellerID:    &sell.UserID,
+			Price:       fill.Price,
+			Quantity:    fill.Quantity,
+		})
+		if err != nil {
+			return err
+		}
+
+		amount := fill.Price.Mul(fill.Quantity)
+		if err := s.consumeHoldTx(ctx, tx, buy.ID, amount); err != nil {
+			return err
+		}
+		if err := s.consumeHoldTx(ctx, tx, sell.ID, fill.Quantity); err != nil {
+			return err
+		}
+		if _, err := s.transferTx(ctx, tx, transfer{
+			Kind:      entities.JournalTrade,
+			Reference: fmt.Sprintf("trade:%d", tradeID),
+			From:      entities.UserCashAccount(buy.UserID),
+			To:        entities.UserCashAccount(sell.UserID),
+			Amount:    amount,
+		}); err != nil {
+			return err
+		}
+		if err := s.pgRepository.AddPortfolioQuantityTx(ctx, tx, buy.UserID, taker.StockID, fill.Quantity); err != nil {
+			return err
+		}
+		if err := s.pgRepository.AddPortfolioQuantityTx(ctx, tx, sell.UserID, taker.StockID, fill.Quantity.Neg()); err != nil {
+			return err
+		}
+		if err := s.addLotTx(ctx, tx, buy.UserID, taker.StockID, &buy.ID, fill.Quantity, fill.Price); err != nil {
+			return err
+		}
+		realized, err := s.relieveLotsTx(ctx, tx, sell.UserID, taker.StockID, fill.Quantity, fill.Price)
+		if err != nil {
+			return err
+		}
+
+		for _, o := range []*entities.Order{buy, sell} {
+			from := o.Status
+			to := o.ApplyFill(fill.Price, fill.Quantity)
+			if err := checkOrderTransition(from, to); err != nil {
+				return err
+			}
+			if err := s.pgRepository.UpdateOrderFillTx(ctx, tx, o.ID, o.FilledQuantity, o.AvgFillPrice, from, to); err != nil {
+				return err
+			}
+			o.Status = to
+			if !to.IsOpen() {
+				// BUY по цене лучше лимита оставляет неиспользованный резерв
+				if err := s.releaseHoldTx(ctx, tx, o.ID); err != nil {
+					return err
+				}
+			}
+
+			h := &entities.History{
+				UserID:  o.UserID,
+				OrderID: &o.ID,
+				StockID: &o.StockID,
+				Action:  entities.ActionBuy,
+				Details: "Limit order fill",
+				Amount:  amount,
+			}
+			if o.OrderType == entities.OrderSell {
+				h.Action = entities.ActionSell
+				h.RealizedPnL = &realized
+			}
+			if _, err := s.pgRepository.AddHistoryRecordTx(ctx, tx, h); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*taker = settledTaker
+	for _, o := range []*entities.Order{buy, sell} {
+		s.publishFill(ctx, o, tradeID, fill.Price, fill.Quantity)
+		s.publishOrder(ctx, o)
+	}
+	return nil
+}
+
+// RestoreOrderBooks поднимает стаканы из открытых лимитных заявок после рестарта.
+func (s *service) RestoreOrderBooks(ctx context.Context) error {
+	orders, err := s.pgRepository.GetOpenLimitOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		book := s.orderBook.Book(o.StockID)
+		book.Lock()
+		book.Add(entryFromOrder(o))
+		book.Unlock()
+	}
+
+	s.log.Infof("Order books restored: %d resting orders", len(orders))
+	return nil
+}
+
+// reloadBook пересобирает стакан акции из БД. Вызывается под book.Lock.
+func (s *service) reloadBook(ctx context.Context, book *orderbook.Book) error {
+	orders, err := s.pgRepository.GetOpenLimitOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	book.Clear()
+	for _, o := range orders {
+		if o.StockID == book.StockID {
+			book.Add(entryFromOrder(o))
+		}
+	}
+	return nil
+}
+
+func entryFromOrder(o *entities.Order) *orderbook.Entry {
+	var price decimal.Decimal
+	if o.LimitPrice != nil {
+		price = *o.LimitPrice
+	}
+	// сработавший STOP_LIMIT встаёт в очередь по времени срабатывания
+	placedAt := o.CreatedAt
+	if o.ActivatedAt != nil {
+		placedAt = *o.ActivatedAt
+	}
+	return &orderbook.Entry{
+		OrderID:   o.ID,
+		UserID:    o.UserID,
+		Side:      o.OrderType,
+		Price:     price,
+		Remaining: o.RemainingQuantity(),
+		CreatedAt: placedAt,
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/mailer"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// RegisterUser — публичная регистрация. Без приглашения создаётся только TRADER,
+// роль из запроса не учитывается; с приглашением роль берётся из него.
+func (s *service) RegisterUser(ctx context.Context, user *entities.User, inviteCode string) (int64, error) {
+	if strings.TrimSpace(inviteCode) == "" {
+		user.Role = entities.RoleTrader
+		return s.createUser(ctx, user, entities.AuditRegister)
+	}
+
+	var id int64
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		inv, err := s.pgRepository.ConsumeInviteTx(ctx, tx, auth.HashToken(strings.TrimSpace(inviteCode)))
+		if err != nil {
+			return err
+		}
+		// чужой адрес — тот же ответ, что и неверный код; транзакция откатывается, код остаётся в силе
+		if inv == nil || (inv.Email != "" && !strings.EqualFold(inv.Email, user.Email)) {
+			return entities.ErrInvalidInvite
+		}
+
+		user.Role = inv.Role
+		if inv.Email != "" {
+			// код пришёл письмом на этот адрес — повторно подтверждать его не нужно
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		}
+		if id, err = s.pgRepository.CreateUserTx(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.pgRepository.SetInviteUserTx(ctx, tx, inv.ID, id); err != nil {
+			return err
+		}
+		return s.userCreatedAuditTx(ctx, tx, entities.AuditRegister, id, user, map[string]interface{}{"invite_id": inv.ID})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !user.EmailVerified() {
+		s.sendVerificationAsync(id)
+	}
+	return id, nil
+}
+
+// ProvisionUser — создание пользователя администратором с любой заведённой ролью.
+func (s *service) ProvisionUser(ctx context.Context, user *entities.User) (int64, error) {
+	ok, err := s.roleExists(ctx, user.Role)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, entities.ErrUnknownRole
+	}
+	return s.CreateUser(ctx, user)
+}
+
+// CreateInvite выпускает приглашение и возвращает код; он показывается один раз.
+// Если у приглашения есть email, код отправляется и письмом.
+func (s *service) CreateInvite(ctx context.Context, inv *entities.Invite, ttl time.Duration) (string, error) {
+	ok, err := s.roleExists(ctx, inv.Role)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", entities.ErrUnknownRole
+	}
+	if ttl <= 0 {
+		ttl = time.Duration(s.config.InviteTTLHours) * time.Hour
+	}
+
+	code, hash, err := auth.GenerateInviteCode()
+	if err != nil {
+		return "", err
+	}
+	inv.CodeHash, inv.CreatedAt = hash, time.Now()
+	inv.ExpiresAt = inv.CreatedAt.Add(ttl)
+
+	err = s.inTx(ctx, func(tx database.Transaction) (err error) {
+		if inv.ID, err = s.pgRepository.CreateInviteTx(ctx, tx, inv); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditInviteCreate,
+			TargetType: entities.AuditTargetInvite,
+			TargetID:   auditID(inv.ID),
+		}, nil, inv)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if inv.Email != "" {
+		s.sendInviteAsync(inv, code)
+	}
+	return code, nil
+}
+
+func (s *service) ListInvites(ctx context.Context) ([]*entities.Invite, error) {
+	return s.pgRepository.GetInvites(ctx)
+}
+
+// RevokeInvite отзывает неиспользованное приглашение.
+func (s *service) RevokeInvite(ctx context.Context, id int64) error {
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		ok, err := s.pgRepository.RevokeInviteTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return entities.ErrInviteNotFound
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditInviteRevoke,
+			TargetType: entities.AuditTargetInvite,
+			TargetID:   auditID(id),
+		}, nil, nil)
+	})
+}
+
+// BootstrapAdmin создаёт первого администратора; если он уже есть — ErrAdminExists.
+// Адрес считается подтверждённым: его задаёт оператор сервера.
+func (s *service) BootstrapAdmin(ctx context.Context, email, passwordHash string) (int64, error) {
+	var id int64
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		newID, created, err := s.pgRepository.CreateFirstAdminTx(ctx, tx, &entities.User{Email: email, Password: passwordHash})
+		if err != nil {
+			return err
+		}
+		if !created {
+			return entities.ErrAdminExists
+		}
+		id = newID
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditAdminBootstrap,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(id),
+		}, nil, map[string]interface{}{"email": email, "role": entities.RoleAdmin})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// userCreatedAuditTx пишет в журнал нового пользователя; extra дополняет снимок «после».
+func (s *service) userCreatedAuditTx(ctx context.Context, tx database.Transaction, action entities.AuditAction, id int64, user *entities.User, extra map[string]interface{}) error {
+	after := userAuditView(user)
+	for k, v := range extra {
+		after[k] = v
+	}
+	return s.auditTx(ctx, tx, &entities.AuditEntry{
+		Action:     action,
+		TargetType: entities.AuditTargetUser,
+		TargetID:   auditID(id),
+	}, nil, after)
+}
+
+func (s *service) sendInviteAsync(inv *entities.Invite, code string) {
+	msg := &mailer.Message{
+		To:      inv.Email,
+		Subject: "You are invited",
+		Body: fmt.Sprintf("You have been invited to join as %s. Create your account by opening this link:\n\n%s\n\n"+
+			"Invite code: %s\nThe invite expires on %s.\n",
+			inv.Role, s.emailLink("/accept-invite", code), code, inv.ExpiresAt.UTC().Format(time.RFC1123)),
+	}
+	go func(inviteID int64) {
+		ctx, cancel := context.WithTimeout(context.Background(), mailTimeout)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.log.Errorf("sendInviteAsync: invite=%d: %v", inviteID, err)
+		}
+	}(inv.ID)
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Skapar/backend/internal/audit"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// orderTransitions — допустимые переходы статусов заявки. Финальные статусы сюда не входят.
+var orderTransitions = map[entities.OrderStatus][]entities.OrderStatus{
+	entities.OrderNew: {
+		entities.OrderPartiallyFilled,
+		entities.OrderFilled,
+		entities.OrderCancelled,
+		entities.OrderRejected,
+		entities.OrderExpired,
+	},
+	entities.OrderPartiallyFilled: {
+		entities.OrderPartiallyFilled,
+		entities.OrderFilled,
+		entities.OrderCancelled,
+		entities.OrderExpired,
+	},
+}
+
+func checkOrderTransition(from, to entities.OrderStatus) error {
+	for _, allowed := range orderTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &entities.OrderTransitionError{From: from, To: to}
+}
+
+// UpdateOrderStatus — ручная смена статуса. Исполнение (PARTIALLY_FILLED/FILLED)
+// возможно только через сделки, поэтому вручную заявку можно лишь закрыть.
+func (s *service) UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error {
+	switch status {
+	case entities.OrderCancelled, entities.OrderRejected, entities.OrderExpired:
+		return s.closeOrderByActor(ctx, orderID, status, "Order "+strings.ToLower(string(status)))
+	}
+
+	order, err := s.pgRepository.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	return &entities.OrderTransitionError{From: order.Status, To: status}
+}
+
+func (s *service) CancelOrder(ctx context.Context, orderID int64) error {
+	return s.closeOrderByActor(ctx, orderID, entities.OrderCancelled, "Order cancelled")
+}
+
+// closeOrderByActor — closeOrder по запросу пользователя: закрытие чужой заявки попадает в журнал
+// в той же транзакции, что и смена статуса.
+func (s *service) closeOrderByActor(ctx context.Context, orderID int64, to entities.OrderStatus, details string) error {
+	actor := audit.MetaFrom(ctx).ActorID
+	return s.closeOrder(ctx, orderID, to, details, func(tx database.Transaction, prev *entities.Order) error {
+		if actor == nil || prev.UserID == *actor {
+			return nil
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditOrderStatusSet,
+			TargetType: entities.AuditTargetOrder,
+			TargetID:   auditID(orderID),
+		}, map[string]interface{}{"status": prev.Status}, map[string]interface{}{"status": to})
+	})
+}
+
+// closeOrder переводит открытую заявку в финальный статус и снимает её из стакана.
+// then, если задан, выполняется в транзакции смены статуса и получает заявку до неё.
+func (s *service) closeOrder(ctx context.Context, orderID int64, to entities.OrderStatus, details string, then func(tx database.Transaction, prev *entities.Order) error) error {
+	order, err := s.pgRepository.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	// под локом стакана заявка не может исполниться параллельно с закрытием. Стоп-лимитная
+	// может сработать и стать лимитной, пока её закрывают, поэтому лок берётся и для неё,
+	// а вид заявки перечитывается под FOR UPDATE
+	if order.Kind == entities.OrderLimit || order.Kind == entities.OrderStopLimit {
+		book := s.orderBook.Book(order.StockID)
+		book.Lock()
+		defer book.Unlock()
+
+		var kind entities.OrderKind
+		err := s.closeOrderTx(ctx, orderID, to, details, func(tx database.Transaction, prev *entities.Order) error {
+			kind = prev.Kind
+			if then == nil {
+				return nil
+			}
+			return then(tx, prev)
+		})
+		if err != nil {
+			return err
+		}
+		if kind == entities.OrderLimit {
+			book.Remove(order.ID)
+		}
+		return nil
+	}
+
+	return s.closeOrderTx(ctx, orderID, to, details, then)
+}
+
+// closeOrderTx меняет статус и пишет историю в одной транзакции. Стакан не трогает —
+// для лимитных заявок вызывающий код должен держать его лок.
+func (s *service) closeOrderTx(ctx context.Context, orderID int64, to entities.OrderStatus, details string, then func(tx database.Transaction, prev *entities.Order) error) error {
+	var current *entities.Order
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		current, err = s.pgRepository.GetOrderForUpdateTx(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if err := checkOrderTransition(current.Status, to); err != nil {
+			return err
+		}
+		if err := s.pgRepository.TransitionOrderStatusTx(ctx, tx, orderID, current.Status, to); err != nil {
+			return err
+		}
+		if err := s.releaseHoldTx(ctx, tx, orderID); err != nil {
+			return err
+		}
+
+		_, err = s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+			UserID:  current.UserID,
+			OrderID: &current.ID,
+			StockID: &current.StockID,
+			Action:  closeAction(to),
+			Details: details,
+		})
+		if err != nil || then == nil {
+			return err
+		}
+		return then(tx, current)
+	})
+	if err != nil {
+		return err
+	}
+
+	current.Status = to
+	s.publishOrder(ctx, current)
+	return nil
+}
+
+func closeAction(to entities.OrderStatus) entities.HistoryAction {
+	if to == entities.OrderExpired {
+		return entities.ActionExpire
+	}
+	return entities.ActionCancel
+}
+
+// failOrder закрывает заявку, которую не удалось исполнить: без исполнений — REJECTED,
+// с частичным исполнением остаток отменяется.
+func (s *service) failOrder(ctx context.Context, order *entities.Order, reason error) {
+	to := entities.OrderRejected
+	if order.FilledQuantity.IsPositive() {
+		to = entities.OrderCancelled
+	}
+	if err := checkOrderTransition(order.Status, to); err != nil {
+		s.log.Errorf("failOrder: order=%d: %v", order.ID, err)
+		return
+	}
+
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		if err := s.pgRepository.TransitionOrderStatusTx(ctx, tx, order.ID, order.Status, to); err != nil {
+			return err
+		}
+		if err := s.releaseHoldTx(ctx, tx, order.ID); err != nil {
+			return err
+		}
+		_, err := s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+			UserID:  order.UserID,
+			OrderID: &order.ID,
+			StockID: &order.StockID,
+			Action:  entities.ActionCancel,
+			Details: "Order " + strings.ToLower(string(to)) + ": " + reason.Error(),
+		})
+		return err
+	})
+	if err != nil {
+		s.log.Errorf("failOrder: order=%d: %v", order.ID, err)
+		return
+	}
+	order.Status = to
+	s.publishOrder(ctx, order)
+}
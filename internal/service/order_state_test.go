@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+func TestCheckOrderTransition(t *testing.T) {
+	tests := []struct {
+		from, to entities.OrderStatus
+		ok       bool
+	}{
+		{entities.OrderNew, entities.OrderPartiallyFilled, true},
+		{entities.OrderNew, entities.OrderFilled, true},
+		{entities.OrderNew, entities.OrderCancelled, true},
+		{entities.OrderNew, entities.OrderRejected, true},
+		{entities.OrderNew, entities.OrderExpired, true},
+		{entities.OrderNew, entities.OrderNew, false},
+		{entities.OrderPartiallyFilled, entities.OrderPartiallyFilled, true},
+		{entities.OrderPartiallyFilled, entities.OrderFilled, true},
+		{entities.OrderPartiallyFilled, entities.OrderCancelled, true},
+		{entities.OrderPartiallyFilled, entities.OrderExpired, true},
+		{entities.OrderPartiallyFilled, entities.OrderRejected, false},
+		{entities.OrderPartiallyFilled, entities.OrderNew, false},
+		{entities.OrderFilled, entities.OrderCancelled, false},
+		{entities.OrderCancelled, entities.OrderFilled, false},
+		{entities.OrderRejected, entities.OrderNew, false},
+		{entities.OrderExpired, entities.OrderCancelled, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			err := checkOrderTransition(tt.from, tt.to)
+			if tt.ok {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var terr *entities.OrderTransitionError
+			if !errors.As(err, &terr) {
+				t.Fatalf("error = %v, want *OrderTransitionError", err)
+			}
+			if terr.From != tt.from || terr.To != tt.to {
+				t.Errorf("error = %s -> %s, want %s -> %s", terr.From, terr.To, tt.from, tt.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// roleCache — права всех ролей в памяти: они нужны на каждый запрос, а меняются редко.
+// Изменения через SetRolePermissions видны сразу на этом инстансе, на остальных — по истечении TTL.
+type roleCache struct {
+	mu       sync.RWMutex
+	roles    map[entities.Role]entities.PermissionSet
+	loadedAt time.Time
+}
+
+// GetRolePermissions возвращает права роли; у неизвестной роли прав нет.
+func (s *service) GetRolePermissions(ctx context.Context, role entities.Role) (entities.PermissionSet, error) {
+	roles, err := s.loadRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return roles[role], nil
+}
+
+func (s *service) ListRoles(ctx context.Context) ([]*entities.RoleInfo, error) {
+	roles, err := s.pgRepository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.pgRepository.GetRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byRole := make(map[entities.Role][]entities.Permission, len(roles))
+	for _, row := range rows {
+		byRole[row.Role] = append(byRole[row.Role], row.Permission)
+	}
+	for _, r := range roles {
+		r.Permissions = byRole[r.Name]
+		if r.Permissions == nil {
+			r.Permissions = []entities.Permission{}
+		}
+	}
+	return roles, nil
+}
+
+func (s *service) ListPermissions(ctx context.Context) ([]*entities.PermissionInfo, error) {
+	return s.pgRepository.GetPermissions(ctx)
+}
+
+// SetRolePermissions создаёт роль или заменяет её описание и набор прав.
+func (s *service) SetRolePermissions(ctx context.Context, role *entities.RoleInfo) error {
+	known, err := s.pgRepository.GetPermissions(ctx)
+	if err != nil {
+		return err
+	}
+	valid := make(map[entities.Permission]bool, len(known))
+	for _, p := range known {
+		valid[p.Name] = true
+	}
+
+	perms := make([]entities.Permission, 0, len(role.Permissions))
+	seen := make(map[entities.Permission]bool, len(role.Permissions))
+	for _, p := range role.Permissions {
+		if !valid[p] {
+			return entities.ErrUnknownPermission
+		}
+		if !seen[p] {
+			seen[p] = true
+			perms = append(perms, p)
+		}
+	}
+	sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+
+	// прежнее состояние роли — для журнала; новой роли в списке нет
+	var before *entities.RoleInfo
+	roles, err := s.ListRoles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range roles {
+		if r.Name == role.Name {
+			before = r
+		}
+	}
+
+	err = s.inTx(ctx, func(tx database.Transaction) error {
+		if err := s.pgRepository.UpsertRoleTx(ctx, tx, role); err != nil {
+			return err
+		}
+		if err := s.pgRepository.ReplaceRolePermissionsTx(ctx, tx, role.Name, perms); err != nil {
+			return err
+		}
+		after := *role
+		after.Permissions = perms
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditRoleUpdate,
+			TargetType: entities.AuditTargetRole,
+			TargetID:   string(role.Name),
+		}, before, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	role.Permissions = perms
+	s.invalidateRoles()
+	return nil
+}
+
+// roleExists — роль заведена в stock_role (пусть даже без прав).
+func (s *service) roleExists(ctx context.Context, role entities.Role) (bool, error) {
+	roles, err := s.loadRoles(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, ok := roles[role]
+	return ok, nil
+}
+
+func (s *service) loadRoles(ctx context.Context) (map[entities.Role]entities.PermissionSet, error) {
+	ttl := time.Duration(s.config.PermissionCacheSeconds) * time.Second
+
+	s.roles.mu.RLock()
+	roles, loadedAt := s.roles.roles, s.roles.loadedAt
+	s.roles.mu.RUnlock()
+	if roles != nil && time.Since(loadedAt) < ttl {
+		return roles, nil
+	}
+
+	list, err := s.pgRepository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.pgRepository.GetRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roles = make(map[entities.Role]entities.PermissionSet, len(list))
+	for _, r := range list {
+		roles[r.Name] = entities.PermissionSet{}
+	}
+	for _, row := range rows {
+		if set, ok := roles[row.Role]; ok {
+			set[row.Permission] = struct{}{}
+		}
+	}
+
+	s.roles.mu.Lock()
+	s.roles.roles, s.roles.loadedAt = roles, time.Now()
+	s.roles.mu.Unlock()
+	return roles, nil
+}
+
+func (s *service) invalidateRoles() {
+	s.roles.mu.Lock()
+	s.roles.roles = nil
+	s.roles.mu.Unlock()
+}
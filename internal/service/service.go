@@ -3,13 +3,21 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/Skapar/backend/config"
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/mailer"
 	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/internal/orderbook"
+	"github.com/Skapar/backend/internal/pricefeed"
 	"github.com/Skapar/backend/internal/repository"
+	"github.com/Skapar/backend/internal/stream"
 	"github.com/Skapar/backend/pkg/cache"
+	"github.com/Skapar/backend/pkg/database"
 	"github.com/Skapar/backend/pkg/logger"
+	"github.com/shopspring/decimal"
 )
 
 type service struct {
@@ -17,6 +25,13 @@ type service struct {
 	cache        cache.ICache
 	log          logger.Logger
 	config       *config.Config
+	orderBook    orderbook.Engine
+	publisher    stream.Publisher
+	keys         *auth.KeySet
+	secrets      *auth.SecretBox
+	mailer       mailer.Mailer
+	roles        roleCache
+	auditQueue   chan queuedAudit // фоновые записи журнала, см. recordAuditAsync
 }
 
 type SConfig struct {
@@ -24,6 +39,10 @@ type SConfig struct {
 	Cache        cache.ICache
 	Log          logger.Logger
 	Config       *config.Config
+	Publisher    stream.Publisher // nil — события не рассылаются
+	Keys         *auth.KeySet     // ключи подписи access-токенов
+	SecretBox    *auth.SecretBox  // шифрование TOTP-секретов; nil — 2FA подключить нельзя
+	Mailer       mailer.Mailer    // письма подтверждения email и сброса пароля
 }
 
 func NewService(cfg *SConfig) (Service, error) {
@@ -32,14 +51,45 @@ func NewService(cfg *SConfig) (Service, error) {
 		cache:        cfg.Cache,
 		log:          cfg.Log,
 		config:       cfg.Config,
+		orderBook:    orderbook.NewEngine(),
+		publisher:    cfg.Publisher,
+		keys:         cfg.Keys,
+		secrets:      cfg.SecretBox,
+		mailer:       cfg.Mailer,
+		auditQueue:   make(chan queuedAudit, cfg.Config.AuditQueueSize),
 	}, nil
 }
 
 func (s *service) CreateUser(ctx context.Context, user *entities.User) (int64, error) {
-	id, err := s.pgRepository.CreateUser(ctx, user)
+	return s.createUser(ctx, user, entities.AuditUserCreate)
+}
+
+// createUser заводит пользователя, его стартовый баланс и запись журнала с действием action
+// в одной транзакции.
+func (s *service) createUser(ctx context.Context, user *entities.User, action entities.AuditAction) (int64, error) {
+	// стартовый баланс заводим через леджер, а не напрямую в stock_user
+	opening := user.Balance
+	user.Balance = decimal.Zero
+
+	var id int64
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		if id, err = s.pgRepository.CreateUserTx(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.adjustBalanceTx(ctx, tx, id, decimal.Zero, opening); err != nil {
+			return err
+		}
+		user.Balance = opening
+		return s.userCreatedAuditTx(ctx, tx, action, id, user, nil)
+	})
+	user.Balance = opening
 	if err != nil {
 		return 0, err
 	}
+
+	if !user.EmailVerified() {
+		s.sendVerificationAsync(id)
+	}
 	return id, nil
 }
 
@@ -47,8 +97,11 @@ func (s *service) GetUserByID(ctx context.Context, id int64) (*entities.User, er
 	return s.pgRepository.GetUserByID(ctx, id)
 }
 
+// userEmailCachePrefix — кэш GetUserByEmail; сбрасывается при смене пароля или адреса.
+const userEmailCachePrefix = "user_email:"
+
 func (s *service) GetUserByEmail(ctx context.Context, email string) (*entities.User, error) {
-	key := "user_email:" + email
+	key := userEmailCachePrefix + email
 	if s.cache != nil {
 		var cached entities.User
 
@@ -81,11 +134,60 @@ func (s *service) GetUserByEmail(ctx context.Context, email string) (*entities.U
 }
 
 func (s *service) UpdateUser(ctx context.Context, user *entities.User) error {
-	return s.pgRepository.UpdateUser(ctx, user)
+	var current *entities.User
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		// строка под блокировкой: иначе сделка между чтением и проводкой сбила бы дельту баланса
+		if current, err = s.pgRepository.GetUserForUpdateTx(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		if user.Role != current.Role {
+			ok, err := s.roleExists(ctx, user.Role)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return entities.ErrUnknownRole
+			}
+		}
+		if err := s.pgRepository.UpdateUserTx(ctx, tx, user); err != nil {
+			return err
+		}
+		if err := s.adjustBalanceTx(ctx, tx, user.ID, current.Balance, user.Balance); err != nil {
+			return err
+		}
+
+		after := userAuditView(user)
+		if user.Password != current.Password {
+			after["password_changed"] = true
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditUserUpdate,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(user.ID),
+		}, userAuditView(current), after)
+	})
+	if err != nil {
+		return err
+	}
+	s.forgetCachedUser(current.Email)
+	return nil
 }
 
 func (s *service) DeleteUser(ctx context.Context, id int64) error {
-	return s.pgRepository.DeleteUser(ctx, id)
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		current, err := s.pgRepository.GetUserForUpdateTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.pgRepository.DeleteUserTx(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditUserDelete,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(id),
+		}, userAuditView(current), nil)
+	})
 }
 
 func (s *service) GetAllUsers(ctx context.Context) ([]*entities.User, error) {
@@ -93,11 +195,33 @@ func (s *service) GetAllUsers(ctx context.Context) ([]*entities.User, error) {
 }
 
 func (s *service) CreateStock(ctx context.Context, stock *entities.Stock) (int64, error) {
-	id, err := s.pgRepository.CreateStock(ctx, stock)
+	if stock.TickSize.IsZero() {
+		stock.TickSize = s.config.DefaultTickSize
+	}
+	if stock.LotSize.IsZero() {
+		stock.LotSize = s.config.DefaultLotSize
+	}
+
+	var id int64
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		if id, err = s.pgRepository.CreateStockTx(ctx, tx, stock); err != nil {
+			return err
+		}
+		if err := s.pgRepository.AddPriceTickTx(ctx, tx, id, stock.Price); err != nil {
+			return err
+		}
+		stock.ID = id
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditStockCreate,
+			TargetType: entities.AuditTargetStock,
+			TargetID:   auditID(id),
+		}, nil, stock)
+	})
 	if err != nil {
 		s.log.Errorf("Service.CreateStock failed: %v", err)
 		return 0, err
 	}
+	s.invalidateStocks()
 	return id, nil
 }
 
@@ -105,8 +229,20 @@ func (s *service) GetStockByID(ctx context.Context, id int64) (*entities.Stock,
 	return s.pgRepository.GetStockByID(ctx, id)
 }
 
+// stocksCacheKey — кэш списка акций; сбрасывается при любом изменении акций.
+const stocksCacheKey = "stocks:all"
+
+func (s *service) invalidateStocks() {
+	if s.cache == nil {
+		return
+	}
+	if err := s.cache.Reset(stocksCacheKey); err != nil {
+		s.log.Warnf("Redis RESET %s failed: %v", stocksCacheKey, err)
+	}
+}
+
 func (s *service) GetAllStocks(ctx context.Context) ([]*entities.Stock, error) {
-	key := "stocks:all"
+	key := stocksCacheKey
 
 	if s.cache != nil {
 		var cached []*entities.Stock
@@ -138,21 +274,153 @@ func (s *service) GetAllStocks(ctx context.Context) ([]*entities.Stock, error) {
 	return stocks, nil
 }
 
+// UpdateStock — правка акции администратором; в отличие от цен из фида, попадает в журнал.
 func (s *service) UpdateStock(ctx context.Context, stock *entities.Stock) error {
-	return s.pgRepository.UpdateStock(ctx, stock)
+	return s.updateStock(ctx, stock, func(tx database.Transaction, prev *entities.Stock) error {
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditStockUpdate,
+			TargetType: entities.AuditTargetStock,
+			TargetID:   auditID(stock.ID),
+		}, prev, stock)
+	})
+}
+
+// updateStock сохраняет акцию. then, если задан, выполняется в той же транзакции
+// и получает прежнее состояние акции.
+func (s *service) updateStock(ctx context.Context, stock *entities.Stock, then func(tx database.Transaction, prev *entities.Stock) error) error {
+	prev, err := s.pgRepository.GetStockByID(ctx, stock.ID)
+	if err != nil {
+		return err
+	}
+
+	// каждое изменение цены пишем в историю вместе с самой ценой
+	err = s.inTx(ctx, func(tx database.Transaction) error {
+		if err := s.pgRepository.UpdateStockTx(ctx, tx, stock); err != nil {
+			return err
+		}
+		if !prev.Price.Equal(stock.Price) {
+			if err := s.pgRepository.AddPriceTickTx(ctx, tx, stock.ID, stock.Price); err != nil {
+				return err
+			}
+		}
+		if then != nil {
+			return then(tx, prev)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateStocks()
+
+	if !prev.Price.Equal(stock.Price) {
+		s.publishPrice(ctx, stock)
+		go s.onPriceChange(stock.ID, stock.Price)
+	}
+	return nil
 }
 
 func (s *service) DeleteStock(ctx context.Context, id int64) error {
-	return s.pgRepository.DeleteStock(ctx, id)
+	prev, err := s.pgRepository.GetStockByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.inTx(ctx, func(tx database.Transaction) error {
+		if err := s.pgRepository.DeleteStockTx(ctx, tx, id); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditStockDelete,
+			TargetType: entities.AuditTargetStock,
+			TargetID:   auditID(id),
+		}, prev, nil)
+	})
+	if err != nil {
+		return err
+	}
+	s.invalidateStocks()
+	return nil
+}
+
+// ApplyPriceFeed берёт у фида следующие цены и проводит их как обычное изменение
+// цены акции: с историей, рассылкой и проверкой стоп-заявок.
+func (s *service) ApplyPriceFeed(ctx context.Context, feed pricefeed.Feed) error {
+	// мимо кэша: фид должен видеть цены, выставленные админом
+	stocks, err := s.pgRepository.GetAllStocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	prices := feed.Next(stocks)
+	for _, st := range stocks {
+		price, ok := prices[st.ID]
+		if !ok || price.Equal(st.Price) {
+			continue
+		}
+
+		updated := *st
+		updated.Price = price
+		updated.UpdatedAt = time.Now()
+		if err := s.updateStock(ctx, &updated, nil); err != nil {
+			s.log.Errorf("ApplyPriceFeed: stock=%d: %v", st.ID, err)
+		}
+	}
+	return nil
 }
 
 // Order
 func (s *service) CreateOrder(ctx context.Context, order *entities.Order) (int64, error) {
-	return s.pgRepository.CreateOrder(ctx, order)
+	switch order.TimeInForce {
+	case "":
+		order.TimeInForce = entities.TimeInForceGTC
+	case entities.TimeInForceDAY:
+		expiresAt := s.sessionClose(time.Now())
+		order.ExpiresAt = &expiresAt
+	}
+
+	owner, err := s.pgRepository.GetUserByID(ctx, order.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if !owner.EmailVerified() {
+		return 0, entities.ErrEmailNotVerified
+	}
+
+	stock, err := s.pgRepository.GetStockByID(ctx, order.StockID)
+	if err != nil {
+		return 0, err
+	}
+	if err := checkTradingRules(stock, order); err != nil {
+		return 0, err
+	}
+
+	id, err := s.pgRepository.CreateOrder(ctx, order)
+	if err != nil {
+		return 0, err
+	}
+	order.ID = id
+
+	// заявка без резерва не должна попасть ни в стакан, ни в исполнение
+	if err := s.placeHold(ctx, order, stock.Price); err != nil {
+		s.failOrder(ctx, order, err)
+		return id, err
+	}
+	s.publishOrder(ctx, order)
+	return id, nil
 }
 
-func (s *service) UpdateOrderStatus(ctx context.Context, orderID int64, status entities.OrderStatus) error {
-	return s.pgRepository.UpdateOrderStatus(ctx, orderID, status)
+// checkTradingRules проверяет заявку на шаг цены и лот акции.
+func checkTradingRules(stock *entities.Stock, order *entities.Order) error {
+	if !stock.ValidQuantity(order.Quantity) {
+		return entities.ErrInvalidLotSize
+	}
+	for _, price := range []*decimal.Decimal{order.LimitPrice, order.TriggerPrice, order.TrailingOffset} {
+		if price != nil && !stock.ValidPrice(*price) {
+			return entities.ErrInvalidTickSize
+		}
+	}
+	return nil
 }
 
 func (s *service) GetOrdersByUserID(ctx context.Context, userID int64) ([]*entities.Order, error) {
@@ -168,8 +436,37 @@ func (s *service) GetPortfolio(ctx context.Context, userID, stockID int64) (*ent
 	return s.pgRepository.GetPortfolio(ctx, userID, stockID)
 }
 
+// CreateOrUpdatePortfolio меняет позицию на p.Quantity. Начисление открывает лот
+// по текущей цене акции, списание закрывает лоты по методу пользователя.
 func (s *service) CreateOrUpdatePortfolio(ctx context.Context, p *entities.Portfolio) error {
-	return s.pgRepository.CreateOrUpdatePortfolio(ctx, p)
+	stock, err := s.pgRepository.GetStockByID(ctx, p.StockID)
+	if err != nil {
+		return err
+	}
+
+	return s.inTx(ctx, func(tx database.Transaction) error {
+		if err := s.pgRepository.AddPortfolioQuantityTx(ctx, tx, p.UserID, p.StockID, p.Quantity); err != nil {
+			return err
+		}
+		if p.Quantity.IsPositive() {
+			if err := s.addLotTx(ctx, tx, p.UserID, p.StockID, nil, p.Quantity, stock.Price); err != nil {
+				return err
+			}
+		} else if _, err := s.relieveLotsTx(ctx, tx, p.UserID, p.StockID, p.Quantity.Neg(), stock.Price); err != nil {
+			return err
+		}
+
+		// ручная правка позиции в обход сделок
+		return s.auditTx(ctx, tx, &entities.AuditEntry{
+			Action:     entities.AuditPortfolioSet,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(p.UserID),
+		}, nil, map[string]interface{}{
+			"stock_id":       p.StockID,
+			"quantity_delta": p.Quantity,
+			"price":          stock.Price,
+		})
+	})
 }
 
 func (s *service) GetPortfoliosByUserID(ctx context.Context, userID int64) ([]*entities.Portfolio, error) {
@@ -185,78 +482,128 @@ func (s *service) GetHistoryByUserID(ctx context.Context, userID int64) ([]*enti
 	return s.pgRepository.GetHistoryByUserID(ctx, userID)
 }
 
+// houseTrade — сделка заявки с домом; сторона дома остаётся пустой.
+func houseTrade(order *entities.Order, price decimal.Decimal) *entities.Trade {
+	t := &entities.Trade{StockID: order.StockID, Price: price, Quantity: order.Quantity}
+	if order.OrderType == entities.OrderBuy {
+		t.BuyOrderID, t.BuyerID = &order.ID, &order.UserID
+	} else {
+		t.SellOrderID, t.SellerID = &order.ID, &order.UserID
+	}
+	return t
+}
+
+// ExecuteOrder исполняет рыночную заявку по текущей цене акции.
+// Баланс, портфель, история и статус заявки меняются в одной транзакции.
 func (s *service) ExecuteOrder(ctx context.Context, order *entities.Order) error {
+	switch {
+	case order.Kind == entities.OrderLimit:
+		return s.placeLimitOrder(ctx, order)
+	case order.Kind.IsStop():
+		return s.placeStopOrder(ctx, order)
+	}
+
 	// Получаем цену акции
 	stock, err := s.GetStockByID(ctx, order.StockID)
 	if err != nil {
+		s.failOrder(ctx, order, err)
 		return err
 	}
 
-	totalAmount := stock.Price * order.Quantity
+	totalAmount := stock.Price.Mul(order.Quantity)
 	order.Price = totalAmount
 
-	switch order.OrderType {
-	case entities.OrderBuy:
-		p, _ := s.GetPortfolio(ctx, order.UserID, order.StockID)
-		newQty := order.Quantity
-		if p != nil {
-			newQty += p.Quantity
+	executed := *order
+	err = s.inTx(ctx, func(tx database.Transaction) error {
+		var (
+			action   entities.HistoryAction
+			realized *decimal.Decimal
+		)
+
+		switch order.OrderType {
+		case entities.OrderBuy:
+			action = entities.ActionBuy
+			if err := s.consumeHoldTx(ctx, tx, order.ID, totalAmount); err != nil {
+				return err
+			}
+			if _, err := s.transferTx(ctx, tx, transfer{
+				Kind:      entities.JournalTrade,
+				Reference: fmt.Sprintf("order:%d", order.ID),
+				From:      entities.UserCashAccount(order.UserID),
+				To:        entities.SystemAccount(entities.AccountHouse),
+				Amount:    totalAmount,
+			}); err != nil {
+				return err
+			}
+			if err := s.pgRepository.AddPortfolioQuantityTx(ctx, tx, order.UserID, order.StockID, order.Quantity); err != nil {
+				return err
+			}
+			if err := s.addLotTx(ctx, tx, order.UserID, order.StockID, &order.ID, order.Quantity, stock.Price); err != nil {
+				return err
+			}
+
+		case entities.OrderSell:
+			action = entities.ActionSell
+			if err := s.consumeHoldTx(ctx, tx, order.ID, order.Quantity); err != nil {
+				return err
+			}
+			if err := s.pgRepository.AddPortfolioQuantityTx(ctx, tx, order.UserID, order.StockID, order.Quantity.Neg()); err != nil {
+				return err
+			}
+			pnl, err := s.relieveLotsTx(ctx, tx, order.UserID, order.StockID, order.Quantity, stock.Price)
+			if err != nil {
+				return err
+			}
+			realized = &pnl
+			if _, err := s.transferTx(ctx, tx, transfer{
+				Kind:      entities.JournalTrade,
+				Reference: fmt.Sprintf("order:%d", order.ID),
+				From:      entities.SystemAccount(entities.AccountHouse),
+				To:        entities.UserCashAccount(order.UserID),
+				Amount:    totalAmount,
+			}); err != nil {
+				return err
+			}
+
+		default:
+			return errors.New("unknown order type")
 		}
 
-		err = s.CreateOrUpdatePortfolio(ctx, &entities.Portfolio{
-			UserID:   order.UserID,
-			StockID:  order.StockID,
-			Quantity: newQty,
-		})
-		if err != nil {
+		// сделка против дома: без неё исполнение не попадёт в объём свечей
+		if _, err := s.pgRepository.CreateTradeTx(ctx, tx, houseTrade(order, stock.Price)); err != nil {
 			return err
 		}
 
-	case entities.OrderSell:
-		p, err := s.GetPortfolio(ctx, order.UserID, order.StockID)
-		if err != nil || p == nil || p.Quantity < order.Quantity {
-			return errors.New("not enough stocks to sell")
-		}
-		newQty := p.Quantity - order.Quantity
-		err = s.CreateOrUpdatePortfolio(ctx, &entities.Portfolio{
-			UserID:   order.UserID,
-			StockID:  order.StockID,
-			Quantity: newQty,
-		})
-		if err != nil {
+		// Создаём запись в истории
+		if _, err := s.pgRepository.AddHistoryRecordTx(ctx, tx, &entities.History{
+			UserID:      order.UserID,
+			OrderID:     &order.ID,
+			StockID:     &order.StockID,
+			Action:      action,
+			Details:     "Executed order",
+			Amount:      totalAmount,
+			RealizedPnL: realized,
+		}); err != nil {
 			return err
 		}
 
-	default:
-		return errors.New("unknown order type")
-	}
-
-	// Маппим order type в action
-	var action entities.HistoryAction
-	switch order.OrderType {
-	case entities.OrderBuy:
-		action = entities.ActionBuy
-	case entities.OrderSell:
-		action = entities.ActionSell
-	default:
-		action = ""
-	}
-
-	// Создаём запись в истории
-	h := &entities.History{
-		UserID:  order.UserID,
-		OrderID: &order.ID,
-		StockID: &order.StockID,
-		Action:  action,
-		Details: "Executed order",
-		Amount:  totalAmount,
-	}
-	_, err = s.AddHistoryRecord(ctx, h)
+		// Обновляем статус ордера
+		executed.Status = executed.ApplyFill(stock.Price, order.Quantity)
+		if err := checkOrderTransition(order.Status, executed.Status); err != nil {
+			return err
+		}
+		if err := s.pgRepository.UpdateOrderFillTx(ctx, tx, order.ID, executed.FilledQuantity, executed.AvgFillPrice, order.Status, executed.Status); err != nil {
+			return err
+		}
+		return s.releaseHoldTx(ctx, tx, order.ID)
+	})
 	if err != nil {
+		s.failOrder(ctx, order, err)
 		return err
 	}
 
-	// Обновляем статус ордера
-	order.Status = entities.OrderCompleted
-	return s.UpdateOrderStatus(ctx, order.ID, order.Status)
+	*order = executed
+	s.publishFill(ctx, order, 0, stock.Price, order.Quantity)
+	s.publishOrder(ctx, order)
+	return nil
 }
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+// placeStopOrder оставляет условную заявку «спящей» до пересечения trigger_price.
+// Если цена уже за триггером — заявка срабатывает сразу.
+func (s *service) placeStopOrder(ctx context.Context, order *entities.Order) error {
+	stock, err := s.pgRepository.GetStockByID(ctx, order.StockID)
+	if err != nil {
+		s.failOrder(ctx, order, err)
+		return err
+	}
+	return s.evaluateStopOrder(ctx, order, stock.Price)
+}
+
+// EvaluateStopOrders проверяет все спящие условные заявки по текущим ценам из stock_stock.
+func (s *service) EvaluateStopOrders(ctx context.Context) error {
+	// идём мимо кэша stocks:all — нужны актуальные цены
+	stocks, err := s.pgRepository.GetAllStocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	prices := make(map[int64]decimal.Decimal, len(stocks))
+	for _, st := range stocks {
+		prices[st.ID] = st.Price
+	}
+	return s.evaluateStopOrders(ctx, prices)
+}
+
+func (s *service) evaluateStopOrders(ctx context.Context, prices map[int64]decimal.Decimal) error {
+	orders, err := s.pgRepository.GetDormantStopOrders(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		price, ok := prices[o.StockID]
+		if !ok {
+			continue
+		}
+		if err := s.evaluateStopOrder(ctx, o, price); err != nil {
+			s.log.Errorf("evaluateStopOrder failed (order=%d): %v", o.ID, err)
+		}
+	}
+	return nil
+}
+
+// onPriceChange вызывается после изменения цены акции.
+func (s *service) onPriceChange(stockID int64, price decimal.Decimal) {
+	ctx := context.Background()
+	if err := s.evaluateStopOrders(ctx, map[int64]decimal.Decimal{stockID: price}); err != nil {
+		s.log.Errorf("onPriceChange: stop orders (stock=%d): %v", stockID, err)
+	}
+}
+
+func (s *service) evaluateStopOrder(ctx context.Context, order *entities.Order, price decimal.Decimal) error {
+	if order.Kind == entities.OrderTrailingStop {
+		if trigger, moved := trailingTrigger(order, price); moved {
+			if err := s.pgRepository.UpdateTriggerPrice(ctx, order.ID, trigger); err != nil {
+				return err
+			}
+			order.TriggerPrice = &trigger
+		}
+	}
+
+	if !stopTriggered(order, price) {
+		return nil
+	}
+	return s.triggerStopOrder(ctx, order, price)
+}
+
+// triggerStopOrder превращает сработавшую заявку в MARKET/LIMIT и отправляет её
+// в обычный путь исполнения.
+func (s *service) triggerStopOrder(ctx context.Context, order *entities.Order, price decimal.Decimal) error {
+	kind := order.Kind.Activated()
+
+	activated, err := s.pgRepository.ActivateStopOrder(ctx, order.ID, kind)
+	if err != nil {
+		return err
+	}
+	if !activated {
+		// уже сработала в другом месте или была отменена
+		return nil
+	}
+
+	if _, err := s.AddHistoryRecord(ctx, &entities.History{
+		UserID:  order.UserID,
+		OrderID: &order.ID,
+		StockID: &order.StockID,
+		Action:  entities.ActionStopTriggered,
+		Details: fmt.Sprintf("%s triggered at %s", order.Kind, price),
+	}); err != nil {
+		s.log.Errorf("triggerStopOrder: history (order=%d): %v", order.ID, err)
+	}
+
+	now := time.Now()
+	order.Kind = kind
+	order.ActivatedAt = &now
+	return s.ExecuteOrder(ctx, order)
+}
+
+// trailingTrigger подтягивает триггер за ценой: для SELL только вверх, для BUY только вниз.
+func trailingTrigger(order *entities.Order, price decimal.Decimal) (decimal.Decimal, bool) {
+	if order.TrailingOffset == nil {
+		return decimal.Zero, false
+	}
+
+	offset := *order.TrailingOffset
+	if order.OrderType == entities.OrderSell {
+		candidate := price.Sub(offset)
+		if order.TriggerPrice == nil || candidate.GreaterThan(*order.TriggerPrice) {
+			return candidate, true
+		}
+		return decimal.Zero, false
+	}
+
+	candidate := price.Add(offset)
+	if order.TriggerPrice == nil || candidate.LessThan(*order.TriggerPrice) {
+		return candidate, true
+	}
+	return decimal.Zero, false
+}
+
+// stopTriggered — BUY срабатывает на росте до триггера, SELL — на падении до него.
+func stopTriggered(order *entities.Order, price decimal.Decimal) bool {
+	if order.TriggerPrice == nil {
+		return false
+	}
+	if order.OrderType == entities.OrderBuy {
+		return price.GreaterThanOrEqual(*order.TriggerPrice)
+	}
+	return price.LessThanOrEqual(*order.TriggerPrice)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/shopspring/decimal"
+)
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func decPtr(s string) *decimal.Decimal {
+	d := dec(s)
+	return &d
+}
+
+func TestStopTriggered(t *testing.T) {
+	tests := []struct {
+		name    string
+		side    entities.OrderType
+		trigger *decimal.Decimal
+		price   string
+		want    bool
+	}{
+		{"buy below trigger", entities.OrderBuy, decPtr("100"), "99.99", false},
+		{"buy at trigger", entities.OrderBuy, decPtr("100"), "100", true},
+		{"buy above trigger", entities.OrderBuy, decPtr("100"), "101", true},
+		{"sell above trigger", entities.OrderSell, decPtr("100"), "100.01", false},
+		{"sell at trigger", entities.OrderSell, decPtr("100"), "100", true},
+		{"sell below trigger", entities.OrderSell, decPtr("100"), "99", true},
+		{"no trigger yet", entities.OrderSell, nil, "1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &entities.Order{OrderType: tt.side, TriggerPrice: tt.trigger}
+			if got := stopTriggered(order, dec(tt.price)); got != tt.want {
+				t.Errorf("stopTriggered = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailingTrigger(t *testing.T) {
+	tests := []struct {
+		name    string
+		side    entities.OrderType
+		offset  *decimal.Decimal
+		trigger *decimal.Decimal
+		price   string
+		want    string
+		wantOK  bool
+	}{
+		{"sell sets initial trigger", entities.OrderSell, decPtr("5"), nil, "100", "95", true},
+		{"sell follows price up", entities.OrderSell, decPtr("5"), decPtr("95"), "102", "97", true},
+		{"sell does not follow price down", entities.OrderSell, decPtr("5"), decPtr("95"), "98", "0", false},
+		{"sell unchanged at same level", entities.OrderSell, decPtr("5"), decPtr("95"), "100", "0", false},
+		{"buy sets initial trigger", entities.OrderBuy, decPtr("5"), nil, "100", "105", true},
+		{"buy follows price down", entities.OrderBuy, decPtr("5"), decPtr("105"), "97", "102", true},
+		{"buy does not follow price up", entities.OrderBuy, decPtr("5"), decPtr("105"), "103", "0", false},
+		{"not a trailing order", entities.OrderSell, nil, decPtr("95"), "200", "0", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &entities.Order{OrderType: tt.side, TrailingOffset: tt.offset, TriggerPrice: tt.trigger}
+			got, ok := trailingTrigger(order, dec(tt.price))
+			if ok != tt.wantOK {
+				t.Fatalf("trailingTrigger ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !got.Equal(dec(tt.want)) {
+				t.Errorf("trailingTrigger = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
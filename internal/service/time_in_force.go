@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+)
+
+// ExpireOrders закрывает DAY/GTD заявки, у которых истёк expires_at.
+func (s *service) ExpireOrders(ctx context.Context) error {
+	orders, err := s.pgRepository.GetExpiredOrders(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, o := range orders {
+		details := fmt.Sprintf("Order expired (%s, expires_at=%s)", o.TimeInForce, o.ExpiresAt.UTC().Format(time.RFC3339))
+		if err := s.closeOrder(ctx, o.ID, entities.OrderExpired, details, nil); err != nil {
+			s.log.Errorf("ExpireOrders: order=%d: %v", o.ID, err)
+		}
+	}
+	return nil
+}
+
+// sessionClose — ближайшее после now закрытие торговой сессии (SESSION_CLOSE_UTC).
+func (s *service) sessionClose(now time.Time) time.Time {
+	now = now.UTC()
+
+	closeAt, err := time.Parse("15:04", s.config.SessionCloseUTC)
+	if err != nil {
+		s.log.Warnf("invalid SESSION_CLOSE_UTC %q, using end of day: %v", s.config.SessionCloseUTC, err)
+		closeAt = time.Date(0, 1, 1, 23, 59, 59, 0, time.UTC)
+	}
+
+	t := time.Date(now.Year(), now.Month(), now.Day(), closeAt.Hour(), closeAt.Minute(), closeAt.Second(), 0, time.UTC)
+	if !t.After(now) {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
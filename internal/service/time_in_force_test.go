@@ -0,0 +1,56 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Skapar/backend/config"
+)
+
+func TestSessionClose(t *testing.T) {
+	tests := []struct {
+		name  string
+		close string
+		now   time.Time
+		want  time.Time
+	}{
+		{
+			name:  "before close today",
+			close: "21:00",
+			now:   time.Date(2026, 5, 4, 10, 30, 0, 0, time.UTC),
+			want:  time.Date(2026, 5, 4, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "exactly at close rolls to tomorrow",
+			close: "21:00",
+			now:   time.Date(2026, 5, 4, 21, 0, 0, 0, time.UTC),
+			want:  time.Date(2026, 5, 5, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "after close",
+			close: "21:00",
+			now:   time.Date(2026, 5, 4, 22, 15, 0, 0, time.UTC),
+			want:  time.Date(2026, 5, 5, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "month boundary",
+			close: "21:00",
+			now:   time.Date(2026, 5, 31, 23, 0, 0, 0, time.UTC),
+			want:  time.Date(2026, 6, 1, 21, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "local time converted to utc",
+			close: "21:00",
+			now:   time.Date(2026, 5, 5, 1, 0, 0, 0, time.FixedZone("UTC+5", 5*3600)),
+			want:  time.Date(2026, 5, 4, 21, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{config: &config.Config{SessionCloseUTC: tt.close}}
+			if got := s.sessionClose(tt.now); !got.Equal(tt.want) {
+				t.Errorf("sessionClose = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
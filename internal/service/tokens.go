@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// revokedTokenPrefix — denylist jti отозванных access-токенов; ключ живёт, пока жив сам токен.
+const revokedTokenPrefix = "revoked-jti:"
+
+// IssueTokens выдаёт access-токен и refresh-токен нового семейства (новый логин).
+func (s *service) IssueTokens(ctx context.Context, user *entities.User) (*entities.TokenPair, error) {
+	var pair *entities.TokenPair
+	err := s.inTx(ctx, func(tx database.Transaction) (err error) {
+		if pair, err = s.newTokenPairTx(ctx, tx, user, uuid.NewString()); err != nil {
+			return err
+		}
+		return s.auditTx(ctx, tx, userAuditEntry(user, entities.AuditLogin), nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RefreshTokens меняет refresh-токен на новую пару. Повторное предъявление уже обменянного
+// токена означает, что он утёк: отзываем всё семейство, и вору, и владельцу придётся войти заново.
+func (s *service) RefreshTokens(ctx context.Context, refreshToken string) (*entities.TokenPair, error) {
+	var (
+		pair         *entities.TokenPair
+		reusedFamily string
+		reusedBy     int64
+	)
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		t, err := s.pgRepository.GetRefreshTokenForUpdateTx(ctx, tx, auth.HashToken(refreshToken))
+		if err != nil {
+			return err
+		}
+		switch {
+		case t == nil, t.RevokedAt != nil, time.Now().After(t.ExpiresAt):
+			return entities.ErrInvalidRefreshToken
+		case t.UsedAt != nil:
+			reusedFamily, reusedBy = t.FamilyID, t.UserID
+			return entities.ErrRefreshTokenReused
+		}
+
+		if err := s.pgRepository.MarkRefreshTokenUsedTx(ctx, tx, t.ID); err != nil {
+			return err
+		}
+
+		// роль могла поменяться с момента логина
+		user, err := s.pgRepository.GetUserByID(ctx, t.UserID)
+		if err != nil {
+			return err
+		}
+		pair, err = s.newTokenPairTx(ctx, tx, user, t.FamilyID)
+		return err
+	})
+
+	if reusedFamily != "" {
+		s.log.Warnf("RefreshTokens: reuse detected, revoking family %s", reusedFamily)
+		if revokeErr := s.pgRepository.RevokeRefreshTokenFamily(ctx, reusedFamily); revokeErr != nil {
+			return nil, revokeErr
+		}
+		if auditErr := s.recordAudit(ctx, &entities.AuditEntry{
+			Action:     entities.AuditRefreshReuse,
+			TargetType: entities.AuditTargetUser,
+			TargetID:   auditID(reusedBy),
+		}, nil, map[string]interface{}{"family_id": reusedFamily}); auditErr != nil {
+			return nil, auditErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// RevokeTokens — выход: отзывает семейство refresh-токена и текущий access-токен.
+func (s *service) RevokeTokens(ctx context.Context, userID int64, jti string, accessExpiresAt time.Time, refreshToken string) error {
+	if refreshToken != "" {
+		if err := s.pgRepository.RevokeRefreshTokenFamilyByHash(ctx, userID, auth.HashToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+	if jti == "" || !time.Now().Before(accessExpiresAt) {
+		return s.recordLogout(ctx, userID)
+	}
+
+	// Postgres — источник истины, Redis — быстрая проверка на каждый запрос
+	if err := s.pgRepository.AddRevokedToken(ctx, jti, accessExpiresAt); err != nil {
+		return err
+	}
+	if s.cache != nil {
+		if err := s.cache.Store(revokedTokenPrefix+jti, true, time.Until(accessExpiresAt), false); err != nil {
+			s.log.Warnf("RevokeTokens: redis store failed: %v", err)
+		}
+	}
+	return s.recordLogout(ctx, userID)
+}
+
+// recordLogout пишет выход в журнал. Отзыв идёт несколькими шагами (Postgres и Redis), поэтому
+// запись отдельная; при её ошибке выход возвращает ошибку, и клиент повторит его.
+func (s *service) recordLogout(ctx context.Context, userID int64) error {
+	return s.recordAudit(ctx, &entities.AuditEntry{
+		Action:     entities.AuditLogout,
+		TargetType: entities.AuditTargetUser,
+		TargetID:   auditID(userID),
+	}, nil, nil)
+}
+
+// IsTokenRevoked проверяет jti по denylist. Redis — только положительный кэш: запись туда
+// при отзыве может не дойти, поэтому промах или недоступность Redis проверяются в Postgres.
+func (s *service) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	if s.cache != nil {
+		var revoked bool
+		err := s.cache.Get(revokedTokenPrefix+jti, &revoked, false)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, redis.Nil) {
+			s.log.Warnf("IsTokenRevoked: redis unavailable, falling back to postgres: %v", err)
+		}
+	}
+	return s.pgRepository.IsTokenRevoked(ctx, jti)
+}
+
+// PurgeExpiredTokens удаляет истёкшие refresh-токены и записи отзыва.
+func (s *service) PurgeExpiredTokens(ctx context.Context) error {
+	n, err := s.pgRepository.DeleteExpiredTokens(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		s.log.Infof("PurgeExpiredTokens: deleted %d rows", n)
+	}
+	return nil
+}
+
+func (s *service) newTokenPairTx(ctx context.Context, tx database.Transaction, user *entities.User, familyID string) (*entities.TokenPair, error) {
+	now := time.Now()
+
+	access, err := auth.GenerateToken(s.keys, s.config.JWTTTLMinutes, user.ID, string(user.Role))
+	if err != nil {
+		return nil, err
+	}
+	refresh, hash, err := auth.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	rt := &entities.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: now.Add(time.Duration(s.config.RefreshTokenTTLHours) * time.Hour),
+	}
+	if _, err := s.pgRepository.CreateRefreshTokenTx(ctx, tx, rt); err != nil {
+		return nil, err
+	}
+
+	return &entities.TokenPair{
+		AccessToken:      access,
+		AccessExpiresAt:  now.Add(time.Duration(s.config.JWTTTLMinutes) * time.Minute),
+		RefreshToken:     refresh,
+		RefreshExpiresAt: rt.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// EnrollTOTP выдаёт новый секрет. Второй фактор включится только после ConfirmTOTP,
+// до этого повторный вызов просто заменяет секрет.
+func (s *service) EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error) {
+	if s.secrets == nil {
+		return nil, entities.ErrTwoFactorUnavailable
+	}
+	user, err := s.pgRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	enc, err := s.secrets.Seal([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.pgRepository.UpsertPendingUserTOTP(ctx, userID, enc)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, entities.ErrTwoFactorAlreadyEnabled
+	}
+
+	return &entities.TOTPEnrollment{
+		Secret: secret,
+		URI:    auth.TOTPURI(s.config.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP включает второй фактор по первому коду из приложения и возвращает коды восстановления.
+func (s *service) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkSecondFactor(ctx, userID, code, true, func(tx database.Transaction) error {
+		if err := s.pgRepository.ReplaceRecoveryCodesTx(ctx, tx, userID, hashes); err != nil {
+			return err
+		}
+		return s.twoFactorAuditTx(ctx, tx, userID, entities.AuditTwoFactorEnable)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP отключает второй фактор; нужен действующий код или код восстановления.
+func (s *service) DisableTOTP(ctx context.Context, userID int64, code string) error {
+	return s.checkSecondFactor(ctx, userID, code, false, func(tx database.Transaction) error {
+		if err := s.pgRepository.DeleteUserTOTPTx(ctx, tx, userID); err != nil {
+			return err
+		}
+		return s.twoFactorAuditTx(ctx, tx, userID, entities.AuditTwoFactorDisable)
+	})
+}
+
+// RegenerateRecoveryCodes заменяет коды восстановления; старые перестают действовать.
+func (s *service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	codes, hashes, err := s.newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	err = s.checkSecondFactor(ctx, userID, code, false, func(tx database.Transaction) error {
+		if err := s.pgRepository.ReplaceRecoveryCodesTx(ctx, tx, userID, hashes); err != nil {
+			return err
+		}
+		return s.twoFactorAuditTx(ctx, tx, userID, entities.AuditRecoveryCodesReset)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *service) GetTwoFactorStatus(ctx context.Context, userID int64) (*entities.TwoFactorStatus, error) {
+	t, err := s.pgRepository.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st := &entities.TwoFactorStatus{Enabled: t.Enabled()}
+	if st.Enabled {
+		if st.RecoveryCodesLeft, err = s.pgRepository.CountUnusedRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+// BeginLogin вызывается после проверки пароля: без второго фактора сразу выдаёт токены,
+// с ним — короткоживущий challenge для CompleteLogin.
+func (s *service) BeginLogin(ctx context.Context, user *entities.User) (*entities.TokenPair, *entities.LoginChallenge, error) {
+	t, err := s.pgRepository.GetUserTOTP(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !t.Enabled() {
+		pair, err := s.IssueTokens(ctx, user)
+		return pair, nil, err
+	}
+
+	ttl := time.Duration(s.config.LoginChallengeTTLMinutes) * time.Minute
+	token, claims, err := auth.GenerateChallengeToken(s.keys, ttl, user.ID, auth.PurposeLogin2FA)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, &entities.LoginChallenge{Token: token, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+// CompleteLogin — второй шаг логина: challenge из BeginLogin и код второго фактора.
+func (s *service) CompleteLogin(ctx context.Context, challengeToken, code string) (*entities.TokenPair, error) {
+	claims, err := auth.ParseChallengeToken(s.keys, challengeToken, auth.PurposeLogin2FA)
+	if err != nil {
+		return nil, entities.ErrInvalidLoginChallenge
+	}
+
+	if err := s.checkSecondFactor(ctx, claims.UserID, code, false, nil); err != nil {
+		return nil, err
+	}
+
+	// роль берём из БД, а не из challenge: она могла поменяться
+	user, err := s.pgRepository.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+	return s.IssueTokens(ctx, user)
+}
+
+// VerifyStepUp — повторная проверка перед опасной операцией. Без включённого второго фактора
+// пропускает: подключать его или нет, решает пользователь.
+func (s *service) VerifyStepUp(ctx context.Context, userID int64, code string) error {
+	t, err := s.pgRepository.GetUserTOTP(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !t.Enabled() {
+		return nil
+	}
+	if strings.TrimSpace(code) == "" {
+		return entities.ErrTwoFactorRequired
+	}
+	return s.checkSecondFactor(ctx, userID, code, false, nil)
+}
+
+// checkSecondFactor проверяет код под блокировкой строки фактора. confirm — подтверждение
+// подключения: принимается только TOTP и фактор включается. then выполняется в той же
+// транзакции и только при верном коде. Неверный код коммитится как ошибка попытки.
+func (s *service) checkSecondFactor(ctx context.Context, userID int64, code string, confirm bool, then func(tx database.Transaction) error) error {
+	var verifyErr error
+	err := s.inTx(ctx, func(tx database.Transaction) error {
+		t, err := s.pgRepository.GetUserTOTPForUpdateTx(ctx, tx, userID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case t == nil && confirm:
+			verifyErr = entities.ErrTwoFactorNotEnrolled
+			return nil
+		case t == nil, !confirm && !t.Enabled():
+			verifyErr = entities.ErrTwoFactorNotEnabled
+			return nil
+		case confirm && t.Enabled():
+			verifyErr = entities.ErrTwoFactorAlreadyEnabled
+			return nil
+		case t.Locked(time.Now()):
+			verifyErr = entities.ErrTwoFactorLocked
+			return nil
+		}
+
+		step, ok, err := s.matchSecondFactor(ctx, tx, t, code, !confirm)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			verifyErr = entities.ErrInvalidTwoFactorCode
+			lockout := time.Duration(s.config.TOTPLockoutMinutes) * time.Minute
+			if err := s.pgRepository.RecordTOTPFailureTx(ctx, tx, userID, s.config.TOTPMaxAttempts, lockout); err != nil {
+				return err
+			}
+			return s.twoFactorAuditTx(ctx, tx, userID, entities.AuditTwoFactorFailed)
+		}
+
+		if err := s.pgRepository.RecordTOTPSuccessTx(ctx, tx, userID, step, confirm); err != nil {
+			return err
+		}
+		if then != nil {
+			return then(tx)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return verifyErr
+}
+
+// matchSecondFactor сверяет TOTP, а если разрешено — и код восстановления (он при этом гасится).
+// step == 0 — сработал код восстановления.
+func (s *service) matchSecondFactor(ctx context.Context, tx database.Transaction, t *entities.UserTOTP, code string, allowRecovery bool) (int64, bool, error) {
+	if s.secrets == nil {
+		return 0, false, entities.ErrTwoFactorUnavailable
+	}
+	secret, err := s.secrets.Open(t.SecretEnc)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to decrypt totp secret: %w", err)
+	}
+
+	code = strings.TrimSpace(code)
+	if step, ok := auth.ValidateTOTP(string(secret), code, time.Now()); ok && step > t.LastUsedStep {
+		return step, true, nil
+	}
+	if !allowRecovery || code == "" {
+		return 0, false, nil
+	}
+
+	used, err := s.pgRepository.UseRecoveryCodeTx(ctx, tx, t.UserID, auth.HashToken(auth.NormalizeRecoveryCode(code)))
+	return 0, used, err
+}
+
+func (s *service) newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = auth.GenerateRecoveryCodes(s.config.RecoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes = make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = auth.HashToken(auth.NormalizeRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+func (s *service) twoFactorAuditTx(ctx context.Context, tx database.Transaction, userID int64, action entities.AuditAction) error {
+	return s.auditTx(ctx, tx, &entities.AuditEntry{
+		Action:     action,
+		TargetType: entities.AuditTargetUser,
+		TargetID:   auditID(userID),
+	}, nil, nil)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Skapar/backend/pkg/database"
+)
+
+// inTx выполняет fn в одной транзакции: commit при успехе, rollback при любой ошибке или панике.
+func (s *service) inTx(ctx context.Context, fn func(tx database.Transaction) error) (err error) {
+	tx, err := s.pgRepository.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil {
+				s.log.Errorf("tx rollback failed: %v", rbErr)
+			}
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
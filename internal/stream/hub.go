@@ -0,0 +1,224 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/Skapar/backend/pkg/logger"
+	"github.com/go-redis/redis/v8"
+)
+
+// redisPrefix — префикс каналов Redis, чтобы не пересекаться с чужими подписками.
+const redisPrefix = "stream:"
+
+// subscriptionBuffer — сколько событий может ждать отправки одному клиенту.
+const subscriptionBuffer = 256
+
+// Publisher — отправка событий подписчикам на всех инстансах.
+type Publisher interface {
+	Publish(ctx context.Context, ev *entities.StreamEvent) error
+}
+
+// Hub раздаёт события локальным подписчикам. С Redis публикация идёт через pub/sub,
+// и каждый инстанс получает события, опубликованные любым другим; без Redis —
+// только внутри процесса.
+type Hub struct {
+	redis      redis.UniversalClient
+	log        logger.Logger
+	replaySize int64
+	local      *localReplay
+
+	mu   sync.RWMutex
+	subs map[string]map[*Subscription]struct{}
+}
+
+type HubConfig struct {
+	Redis      redis.UniversalClient // nil — без межинстансной рассылки
+	Log        logger.Logger
+	ReplaySize int64 // сколько последних событий хранить для докачки; 0 — не хранить
+}
+
+func NewHub(cfg *HubConfig) *Hub {
+	return &Hub{
+		redis:      cfg.Redis,
+		log:        cfg.Log,
+		replaySize: cfg.ReplaySize,
+		local:      &localReplay{size: int(cfg.ReplaySize)},
+		subs:       make(map[string]map[*Subscription]struct{}),
+	}
+}
+
+// Start подписывается на Redis и раздаёт входящие события до отмены ctx.
+func (h *Hub) Start(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+
+	pubsub := h.redis.PSubscribe(ctx, redisPrefix+"*")
+	go func() {
+		defer pubsub.Close()
+
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var ev entities.StreamEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &ev); err != nil {
+					h.log.Warnf("stream: bad event on %s: %v", msg.Channel, err)
+					continue
+				}
+				h.dispatch(&ev)
+			}
+		}
+	}()
+}
+
+func (h *Hub) Publish(ctx context.Context, ev *entities.StreamEvent) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+	// без ID событие всё равно уйдёт подписчикам, но докачать его будет нельзя
+	if err := h.record(ctx, ev); err != nil {
+		h.log.Warnf("stream: replay buffer: %v", err)
+	}
+	if h.redis == nil {
+		h.dispatch(ev)
+		return nil
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	return h.redis.Publish(ctx, redisPrefix+ev.Channel, payload).Err()
+}
+
+func (h *Hub) dispatch(ev *entities.StreamEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for sub := range h.subs[ev.Channel] {
+		sub.deliver(ev)
+	}
+}
+
+// Subscribe создаёт подписку без каналов; каналы добавляются через Join.
+func (h *Hub) Subscribe() *Subscription {
+	return &Subscription{
+		hub:      h,
+		events:   make(chan *entities.StreamEvent, subscriptionBuffer),
+		channels: make(map[string]struct{}),
+	}
+}
+
+// Subscription — очередь событий одного клиента.
+// Если клиент не успевает читать, подписка закрывается: клиент переподключится
+// и перечитает состояние, а не получит поток с дырами.
+type Subscription struct {
+	hub    *Hub
+	events chan *entities.StreamEvent
+
+	mu       sync.Mutex
+	closed   bool
+	channels map[string]struct{}
+}
+
+// Events закрывается после Close или при переполнении буфера.
+func (s *Subscription) Events() <-chan *entities.StreamEvent {
+	return s.events
+}
+
+func (s *Subscription) Join(channels ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	for _, ch := range channels {
+		s.channels[ch] = struct{}{}
+		if s.hub.subs[ch] == nil {
+			s.hub.subs[ch] = make(map[*Subscription]struct{})
+		}
+		s.hub.subs[ch][s] = struct{}{}
+	}
+}
+
+func (s *Subscription) Leave(channels ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ch := range channels {
+		delete(s.channels, ch)
+		s.hub.unlink(ch, s)
+	}
+}
+
+func (s *Subscription) Channels() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]string, 0, len(s.channels))
+	for ch := range s.channels {
+		out = append(out, ch)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for ch := range s.channels {
+		s.hub.unlink(ch, s)
+	}
+	s.channels = map[string]struct{}{}
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+}
+
+func (s *Subscription) deliver(ev *entities.StreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.events <- ev:
+	default:
+		// отписку от хаба сделает Close из обработчика соединения
+		s.closed = true
+		close(s.events)
+	}
+}
+
+// unlink вызывается под h.mu.
+func (h *Hub) unlink(channel string, s *Subscription) {
+	subs := h.subs[channel]
+	delete(subs, s)
+	if len(subs) == 0 {
+		delete(h.subs, channel)
+	}
+}
@@ -0,0 +1,157 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Skapar/backend/internal/models/entities"
+	"github.com/go-redis/redis/v8"
+)
+
+// replayKey — Redis Stream с последними событиями для докачки по Last-Event-ID.
+// ID записей стрима и служат ID событий: они монотонны на всех инстансах.
+const replayKey = "stream-replay"
+
+// record сохраняет событие в буфер докачки и проставляет ему ID.
+func (h *Hub) record(ctx context.Context, ev *entities.StreamEvent) error {
+	if h.replaySize <= 0 {
+		return nil
+	}
+	if h.redis == nil {
+		h.local.add(ev)
+		return nil
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	id, err := h.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: replayKey,
+		MaxLen: h.replaySize,
+		Approx: true,
+		Values: map[string]interface{}{"event": payload},
+	}).Result()
+	if err != nil {
+		return err
+	}
+	ev.ID = id
+	return nil
+}
+
+// Replay возвращает события каналов, опубликованные после события afterID.
+// Если afterID уже вытеснен из буфера, отдаётся всё, что в нём осталось.
+func (h *Hub) Replay(ctx context.Context, afterID string, channels []string) ([]*entities.StreamEvent, error) {
+	if _, _, ok := parseEventID(afterID); !ok {
+		return nil, fmt.Errorf("invalid event id %q", afterID)
+	}
+
+	want := make(map[string]struct{}, len(channels))
+	for _, ch := range channels {
+		want[ch] = struct{}{}
+	}
+
+	var all []*entities.StreamEvent
+	if h.redis == nil {
+		all = h.local.after(afterID)
+	} else {
+		msgs, err := h.redis.XRange(ctx, replayKey, "("+afterID, "+").Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range msgs {
+			raw, _ := msg.Values["event"].(string)
+			var ev entities.StreamEvent
+			if err := json.Unmarshal([]byte(raw), &ev); err != nil {
+				h.log.Warnf("stream: bad replay entry %s: %v", msg.ID, err)
+				continue
+			}
+			ev.ID = msg.ID
+			all = append(all, &ev)
+		}
+	}
+
+	out := make([]*entities.StreamEvent, 0, len(all))
+	for _, ev := range all {
+		if _, ok := want[ev.Channel]; ok {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+// EventAfter сообщает, что событие с ID a опубликовано позже события b.
+func EventAfter(a, b string) bool {
+	ams, aseq, aok := parseEventID(a)
+	bms, bseq, bok := parseEventID(b)
+	if !aok || !bok {
+		return true
+	}
+	if ams != bms {
+		return ams > bms
+	}
+	return aseq > bseq
+}
+
+// parseEventID разбирает ID вида "<unix ms>-<seq>", как у Redis Streams.
+func parseEventID(id string) (ms, seq uint64, ok bool) {
+	head, tail, found := strings.Cut(id, "-")
+	if !found {
+		return 0, 0, false
+	}
+	ms, err := strconv.ParseUint(head, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	seq, err = strconv.ParseUint(tail, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return ms, seq, true
+}
+
+// localReplay — буфер докачки для запуска без Redis, ID в том же формате.
+type localReplay struct {
+	mu     sync.Mutex
+	size   int
+	events []*entities.StreamEvent
+	lastMs uint64
+	seq    uint64
+}
+
+func (r *localReplay) add(ev *entities.StreamEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms <= r.lastMs {
+		ms = r.lastMs
+		r.seq++
+	} else {
+		r.lastMs, r.seq = ms, 0
+	}
+	ev.ID = fmt.Sprintf("%d-%d", ms, r.seq)
+
+	r.events = append(r.events, ev)
+	if len(r.events) > r.size {
+		r.events = r.events[len(r.events)-r.size:]
+	}
+}
+
+func (r *localReplay) after(id string) []*entities.StreamEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out []*entities.StreamEvent
+	for _, ev := range r.events {
+		if EventAfter(ev.ID, id) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
@@ -1,10 +1,14 @@
 package worker
 
 import (
+	"context"
 	"time"
 
+	"github.com/Skapar/backend/config"
 	"github.com/Skapar/backend/pkg/logger"
 
+	"github.com/Skapar/backend/internal/auth"
+	"github.com/Skapar/backend/internal/pricefeed"
 	"github.com/Skapar/backend/internal/service"
 	"github.com/go-co-op/gocron"
 )
@@ -12,27 +16,149 @@ import (
 type worker struct {
 	service   service.Service
 	log       logger.Logger
+	config    *config.Config
 	scheduler *gocron.Scheduler
+	feed      pricefeed.Feed
+	keys      *auth.KeySet
 }
 
 type WorkerConfig struct {
 	Service service.Service
 	Log     logger.Logger
+	Config  *config.Config
+	Keys    *auth.KeySet // nil — ключи JWT не перечитываются
 }
 
 func NewWorker(cfg *WorkerConfig) Worker {
 	return &worker{
 		service:   cfg.Service,
 		log:       cfg.Log,
+		config:    cfg.Config,
 		scheduler: gocron.NewScheduler(time.UTC),
+		keys:      cfg.Keys,
 	}
 }
 
 func (w *worker) Start() {
+	stopEvery := time.Duration(w.config.StopOrderCheckSeconds) * time.Second
+	if _, err := w.scheduler.Every(stopEvery).SingletonMode().Do(w.evaluateStopOrders); err != nil {
+		w.log.Errorf("failed to schedule stop orders job: %v", err)
+	}
+
+	expiryEvery := time.Duration(w.config.OrderExpiryCheckSeconds) * time.Second
+	if _, err := w.scheduler.Every(expiryEvery).SingletonMode().Do(w.expireOrders); err != nil {
+		w.log.Errorf("failed to schedule order expiry job: %v", err)
+	}
+
+	reconcileEvery := time.Duration(w.config.LedgerReconcileMinutes) * time.Minute
+	if _, err := w.scheduler.Every(reconcileEvery).SingletonMode().Do(w.reconcileLedger); err != nil {
+		w.log.Errorf("failed to schedule ledger reconciliation job: %v", err)
+	}
+
+	compactEvery := time.Duration(w.config.PriceTickCompactionMinutes) * time.Minute
+	if _, err := w.scheduler.Every(compactEvery).SingletonMode().Do(w.compactPriceTicks); err != nil {
+		w.log.Errorf("failed to schedule price tick compaction job: %v", err)
+	}
+
+	auditEvery := time.Duration(w.config.AuditFlushSeconds) * time.Second
+	if _, err := w.scheduler.Every(auditEvery).SingletonMode().Do(w.flushAuditQueue); err != nil {
+		w.log.Errorf("failed to schedule audit queue flush job: %v", err)
+	}
+
+	tokenEvery := time.Duration(w.config.TokenCleanupMinutes) * time.Minute
+	if _, err := w.scheduler.Every(tokenEvery).SingletonMode().Do(w.purgeExpiredTokens); err != nil {
+		w.log.Errorf("failed to schedule token cleanup job: %v", err)
+	}
+
+	if w.keys != nil && w.config.JWTKeyReloadSeconds > 0 {
+		reloadEvery := time.Duration(w.config.JWTKeyReloadSeconds) * time.Second
+		if _, err := w.scheduler.Every(reloadEvery).SingletonMode().Do(w.reloadSigningKeys); err != nil {
+			w.log.Errorf("failed to schedule signing key reload job: %v", err)
+		}
+	}
+
+	if w.config.PriceFeedEnabled {
+		w.startPriceFeed()
+	}
+
 	w.scheduler.StartAsync()
 }
 
 func (w *worker) Stop() {
 	w.scheduler.Stop()
 	w.log.Info("Scheduler stopping...")
+	// то, что накопилось после последнего прогона, пишем до выхода
+	w.flushAuditQueue()
+}
+
+// evaluateStopOrders проверяет триггеры условных заявок по текущим ценам.
+func (w *worker) evaluateStopOrders() {
+	if err := w.service.EvaluateStopOrders(context.Background()); err != nil {
+		w.log.Errorf("EvaluateStopOrders failed: %v", err)
+	}
+}
+
+// expireOrders снимает DAY/GTD заявки с истёкшим сроком.
+func (w *worker) expireOrders() {
+	if err := w.service.ExpireOrders(context.Background()); err != nil {
+		w.log.Errorf("ExpireOrders failed: %v", err)
+	}
+}
+
+// reconcileLedger сверяет балансы пользователей с леджером.
+func (w *worker) reconcileLedger() {
+	if _, err := w.service.ReconcileLedger(context.Background()); err != nil {
+		w.log.Errorf("ReconcileLedger failed: %v", err)
+	}
+}
+
+// compactPriceTicks сворачивает старые тики цен в минутные бары.
+func (w *worker) compactPriceTicks() {
+	if err := w.service.CompactPriceTicks(context.Background()); err != nil {
+		w.log.Errorf("CompactPriceTicks failed: %v", err)
+	}
+}
+
+// purgeExpiredTokens чистит истёкшие refresh-токены и denylist.
+func (w *worker) purgeExpiredTokens() {
+	if err := w.service.PurgeExpiredTokens(context.Background()); err != nil {
+		w.log.Errorf("PurgeExpiredTokens failed: %v", err)
+	}
+}
+
+// flushAuditQueue пишет в журнал аудита фоновые записи о неудачных входах.
+func (w *worker) flushAuditQueue() {
+	if err := w.service.FlushAuditQueue(context.Background()); err != nil {
+		w.log.Errorf("FlushAuditQueue failed: %v", err)
+	}
+}
+
+// reloadSigningKeys перечитывает ключи JWT с диска; при ошибке остаются прежние.
+func (w *worker) reloadSigningKeys() {
+	if err := w.keys.Reload(); err != nil {
+		w.log.Errorf("signing key reload failed, keeping current keys: %v", err)
+	}
+}
+
+func (w *worker) startPriceFeed() {
+	feed, err := pricefeed.New(w.config)
+	if err != nil {
+		w.log.Errorf("price feed disabled: %v", err)
+		return
+	}
+	w.feed = feed
+
+	every := time.Duration(w.config.PriceFeedIntervalSeconds) * time.Second
+	if _, err := w.scheduler.Every(every).SingletonMode().Do(w.applyPriceFeed); err != nil {
+		w.log.Errorf("failed to schedule price feed job: %v", err)
+		return
+	}
+	w.log.Infof("price feed %q started, every %s", w.config.PriceFeedSource, every)
+}
+
+// applyPriceFeed записывает очередные цены из фида.
+func (w *worker) applyPriceFeed() {
+	if err := w.service.ApplyPriceFeed(context.Background(), w.feed); err != nil {
+		w.log.Errorf("ApplyPriceFeed failed: %v", err)
+	}
 }
@@ -0,0 +1,22 @@
+-- Лимитные заявки и стакан
+ALTER TABLE stock_order
+    ADD COLUMN IF NOT EXISTS kind            VARCHAR(16)    NOT NULL DEFAULT 'MARKET',
+    ADD COLUMN IF NOT EXISTS limit_price     NUMERIC(20, 8),
+    ADD COLUMN IF NOT EXISTS filled_quantity NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS avg_fill_price  NUMERIC(20, 8) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_stock_order_open ON stock_order (kind, status);
+
+CREATE TABLE IF NOT EXISTS stock_trade (
+    id            BIGSERIAL PRIMARY KEY,
+    stock_id      BIGINT         NOT NULL REFERENCES stock_stock (id),
+    buy_order_id  BIGINT         NOT NULL REFERENCES stock_order (id),
+    sell_order_id BIGINT         NOT NULL REFERENCES stock_order (id),
+    buyer_id      BIGINT         NOT NULL REFERENCES stock_user (id),
+    seller_id     BIGINT         NOT NULL REFERENCES stock_user (id),
+    price         NUMERIC(20, 8) NOT NULL,
+    quantity      NUMERIC(20, 8) NOT NULL,
+    created_at    TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_trade_stock_created ON stock_trade (stock_id, created_at);
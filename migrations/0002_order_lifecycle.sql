@@ -0,0 +1,10 @@
+-- Жизненный цикл заявки: NEW, PARTIALLY_FILLED, FILLED, CANCELLED, REJECTED, EXPIRED
+UPDATE stock_order SET status = 'PARTIALLY_FILLED' WHERE status = 'PENDING' AND filled_quantity > 0;
+UPDATE stock_order SET status = 'NEW' WHERE status = 'PENDING';
+UPDATE stock_order SET status = 'FILLED' WHERE status = 'COMPLETED';
+UPDATE stock_order SET status = 'REJECTED' WHERE status = 'FAILED';
+
+ALTER TABLE stock_order ALTER COLUMN status SET DEFAULT 'NEW';
+ALTER TABLE stock_order
+    ADD CONSTRAINT stock_order_status_check
+    CHECK (status IN ('NEW', 'PARTIALLY_FILLED', 'FILLED', 'CANCELLED', 'REJECTED', 'EXPIRED'));
@@ -0,0 +1,9 @@
+-- Условные заявки: STOP, STOP_LIMIT, TRAILING_STOP
+ALTER TABLE stock_order
+    ADD COLUMN IF NOT EXISTS trigger_price   NUMERIC(20, 8),
+    ADD COLUMN IF NOT EXISTS trailing_offset NUMERIC(20, 8),
+    ADD COLUMN IF NOT EXISTS activated_at    TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_stock_order_dormant_stop
+    ON stock_order (stock_id)
+    WHERE activated_at IS NULL AND kind IN ('STOP', 'STOP_LIMIT', 'TRAILING_STOP');
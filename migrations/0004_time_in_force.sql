@@ -0,0 +1,8 @@
+-- Time-in-force: GTC, DAY, IOC, FOK, GTD
+ALTER TABLE stock_order
+    ADD COLUMN IF NOT EXISTS time_in_force VARCHAR(3) NOT NULL DEFAULT 'GTC',
+    ADD COLUMN IF NOT EXISTS expires_at    TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_stock_order_expires_at
+    ON stock_order (expires_at)
+    WHERE expires_at IS NOT NULL AND status IN ('NEW', 'PARTIALLY_FILLED');
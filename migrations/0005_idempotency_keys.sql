@@ -0,0 +1,12 @@
+-- Fallback-хранилище Idempotency-Key, когда Redis недоступен
+CREATE TABLE IF NOT EXISTS stock_idempotency_key (
+    key          VARCHAR(300) PRIMARY KEY, -- "<user_id>:<Idempotency-Key>"
+    request_hash VARCHAR(64)  NOT NULL,
+    status_code  INT          NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    body         BYTEA,
+    expires_at   TIMESTAMPTZ  NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_idempotency_key_expires_at ON stock_idempotency_key (expires_at);
@@ -0,0 +1,52 @@
+-- Двойная запись: каждое движение денег — журнал из записей с нулевой суммой
+CREATE TABLE IF NOT EXISTS stock_ledger_account (
+    id         BIGSERIAL PRIMARY KEY,
+    code       VARCHAR(100) NOT NULL UNIQUE, -- "USER:<id>:CASH" или "SYSTEM:*"
+    user_id    BIGINT REFERENCES stock_user (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_stock_ledger_account_user_id ON stock_ledger_account (user_id);
+
+CREATE TABLE IF NOT EXISTS stock_ledger_journal (
+    id         BIGSERIAL PRIMARY KEY,
+    kind       VARCHAR(20)  NOT NULL, -- OPENING, DEPOSIT, WITHDRAW, TRADE, ADJUSTMENT
+    reference  VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS stock_ledger_entry (
+    id         BIGSERIAL PRIMARY KEY,
+    journal_id BIGINT         NOT NULL REFERENCES stock_ledger_journal (id),
+    account_id BIGINT         NOT NULL REFERENCES stock_ledger_account (id),
+    amount     NUMERIC(20, 8) NOT NULL, -- > 0 увеличивает остаток счёта
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_entry_account_id ON stock_ledger_entry (account_id);
+CREATE INDEX IF NOT EXISTS idx_stock_ledger_entry_journal_id ON stock_ledger_entry (journal_id);
+
+INSERT INTO stock_ledger_account (code)
+VALUES ('SYSTEM:EXTERNAL'), ('SYSTEM:HOUSE'), ('SYSTEM:ADJUSTMENT')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO stock_ledger_account (code, user_id)
+SELECT 'USER:' || id || ':CASH', id FROM stock_user
+ON CONFLICT (code) DO NOTHING;
+
+-- Открывающие проводки на существующие балансы, чтобы сверка сошлась с первого дня
+WITH opening AS (
+    INSERT INTO stock_ledger_journal (kind, reference)
+    SELECT 'OPENING', 'user:' || id FROM stock_user WHERE balance <> 0
+    RETURNING id, reference
+)
+INSERT INTO stock_ledger_entry (journal_id, account_id, amount)
+SELECT o.id, a.id, u.balance
+FROM opening o
+JOIN stock_user u ON o.reference = 'user:' || u.id
+JOIN stock_ledger_account a ON a.user_id = u.id
+UNION ALL
+SELECT o.id, s.id, -u.balance
+FROM opening o
+JOIN stock_user u ON o.reference = 'user:' || u.id
+CROSS JOIN (SELECT id FROM stock_ledger_account WHERE code = 'SYSTEM:ADJUSTMENT') s;
@@ -0,0 +1,55 @@
+-- Резервы денег и бумаг под открытые заявки
+ALTER TABLE stock_user
+    ADD COLUMN IF NOT EXISTS reserved_balance NUMERIC(20, 8) NOT NULL DEFAULT 0;
+
+ALTER TABLE stock_portfolio
+    ADD COLUMN IF NOT EXISTS locked_quantity NUMERIC(20, 8) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS stock_hold (
+    id         BIGSERIAL PRIMARY KEY,
+    order_id   BIGINT         NOT NULL UNIQUE REFERENCES stock_order (id),
+    user_id    BIGINT         NOT NULL REFERENCES stock_user (id),
+    stock_id   BIGINT         NOT NULL REFERENCES stock_stock (id),
+    kind       VARCHAR(10)    NOT NULL, -- CASH, SHARES
+    amount     NUMERIC(20, 8) NOT NULL,
+    remaining  NUMERIC(20, 8) NOT NULL,
+    status     VARCHAR(10)    NOT NULL DEFAULT 'ACTIVE', -- ACTIVE, CONSUMED, RELEASED
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_hold_user_status ON stock_hold (user_id, status);
+
+-- Резервы под заявки, открытые до появления холдов
+INSERT INTO stock_hold (order_id, user_id, stock_id, kind, amount, remaining)
+SELECT id, user_id, stock_id, kind, amount, amount
+FROM (
+    SELECT id, user_id, stock_id,
+           CASE WHEN order_type = 'SELL' THEN 'SHARES' ELSE 'CASH' END AS kind,
+           CASE WHEN order_type = 'SELL' THEN quantity - filled_quantity
+                ELSE (quantity - filled_quantity) * COALESCE(limit_price, trigger_price, 0) END AS amount
+    FROM stock_order
+    WHERE status IN ('NEW', 'PARTIALLY_FILLED')
+) o
+WHERE amount > 0
+ON CONFLICT (order_id) DO NOTHING;
+
+UPDATE stock_user u
+SET reserved_balance = h.total
+FROM (
+    SELECT user_id, SUM(remaining) AS total
+    FROM stock_hold
+    WHERE kind = 'CASH' AND status = 'ACTIVE'
+    GROUP BY user_id
+) h
+WHERE u.id = h.user_id;
+
+UPDATE stock_portfolio p
+SET locked_quantity = LEAST(h.total, p.quantity)
+FROM (
+    SELECT user_id, stock_id, SUM(remaining) AS total
+    FROM stock_hold
+    WHERE kind = 'SHARES' AND status = 'ACTIVE'
+    GROUP BY user_id, stock_id
+) h
+WHERE p.user_id = h.user_id AND p.stock_id = h.stock_id;
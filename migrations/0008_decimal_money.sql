@@ -0,0 +1,21 @@
+-- Деньги и количества — только NUMERIC, без float-дрейфа
+ALTER TABLE stock_user
+    ALTER COLUMN balance TYPE NUMERIC(20, 8) USING balance::numeric;
+
+ALTER TABLE stock_stock
+    ALTER COLUMN price TYPE NUMERIC(20, 8) USING price::numeric;
+
+ALTER TABLE stock_order
+    ALTER COLUMN price TYPE NUMERIC(20, 8) USING price::numeric,
+    ALTER COLUMN quantity TYPE NUMERIC(20, 8) USING quantity::numeric;
+
+ALTER TABLE stock_portfolio
+    ALTER COLUMN quantity TYPE NUMERIC(20, 8) USING quantity::numeric;
+
+ALTER TABLE stock_history
+    ALTER COLUMN amount TYPE NUMERIC(20, 8) USING amount::numeric;
+
+-- Шаг цены и лот; 0 — без ограничений
+ALTER TABLE stock_stock
+    ADD COLUMN IF NOT EXISTS tick_size NUMERIC(20, 8) NOT NULL DEFAULT 0.01,
+    ADD COLUMN IF NOT EXISTS lot_size  NUMERIC(20, 8) NOT NULL DEFAULT 1;
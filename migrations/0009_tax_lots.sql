@@ -0,0 +1,31 @@
+-- Налоговые лоты для учёта себестоимости и реализованного P&L
+ALTER TABLE stock_user
+    ADD COLUMN IF NOT EXISTS cost_basis_method VARCHAR(10) NOT NULL DEFAULT 'FIFO'; -- FIFO, LIFO, AVERAGE
+
+ALTER TABLE stock_history
+    ADD COLUMN IF NOT EXISTS realized_pnl NUMERIC(20, 8);
+
+CREATE TABLE IF NOT EXISTS stock_tax_lot (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT         NOT NULL REFERENCES stock_user (id),
+    stock_id   BIGINT         NOT NULL REFERENCES stock_stock (id),
+    order_id   BIGINT REFERENCES stock_order (id), -- NULL у ручных начислений
+    quantity   NUMERIC(20, 8) NOT NULL,
+    remaining  NUMERIC(20, 8) NOT NULL,
+    price      NUMERIC(20, 8) NOT NULL,
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_tax_lot_open ON stock_tax_lot (user_id, stock_id, created_at)
+    WHERE remaining > 0;
+
+-- Позиции, набранные до появления лотов: открываем их по текущей цене
+INSERT INTO stock_tax_lot (user_id, stock_id, quantity, remaining, price)
+SELECT p.user_id, p.stock_id, p.quantity, p.quantity, s.price
+FROM stock_portfolio p
+JOIN stock_stock s ON s.id = p.stock_id
+WHERE p.quantity > 0
+  AND NOT EXISTS (
+      SELECT 1 FROM stock_tax_lot l
+      WHERE l.user_id = p.user_id AND l.stock_id = p.stock_id
+  );
@@ -0,0 +1,38 @@
+-- История цен и минутные бары для OHLCV
+CREATE TABLE IF NOT EXISTS stock_price_tick (
+    id         BIGSERIAL PRIMARY KEY,
+    stock_id   BIGINT         NOT NULL REFERENCES stock_stock (id) ON DELETE CASCADE,
+    price      NUMERIC(20, 8) NOT NULL,
+    created_at TIMESTAMPTZ    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_price_tick_stock_created ON stock_price_tick (stock_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stock_price_tick_created ON stock_price_tick (created_at);
+
+-- Свёрнутые воркером тики и сделки старше окна хранения
+CREATE TABLE IF NOT EXISTS stock_candle (
+    stock_id  BIGINT         NOT NULL REFERENCES stock_stock (id) ON DELETE CASCADE,
+    open_time TIMESTAMPTZ    NOT NULL,
+    open      NUMERIC(20, 8) NOT NULL,
+    high      NUMERIC(20, 8) NOT NULL,
+    low       NUMERIC(20, 8) NOT NULL,
+    close     NUMERIC(20, 8) NOT NULL,
+    volume    NUMERIC(20, 8) NOT NULL DEFAULT 0,
+    PRIMARY KEY (stock_id, open_time)
+);
+
+-- Всё, что раньше compacted_until, читается только из stock_candle
+CREATE TABLE IF NOT EXISTS stock_candle_watermark (
+    id              BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    compacted_until TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO stock_candle_watermark (compacted_until)
+VALUES ('epoch')
+ON CONFLICT (id) DO NOTHING;
+
+-- Стартовая точка истории — текущие цены
+INSERT INTO stock_price_tick (stock_id, price, created_at)
+SELECT s.id, s.price, COALESCE(s.updated_at, NOW())
+FROM stock_stock s
+WHERE NOT EXISTS (SELECT 1 FROM stock_price_tick t WHERE t.stock_id = s.id);
@@ -0,0 +1,22 @@
+-- Refresh-токены: в БД только sha256, токены одного логина объединены family_id
+CREATE TABLE IF NOT EXISTS stock_refresh_token (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES stock_user (id) ON DELETE CASCADE,
+    family_id  VARCHAR(36) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ,          -- токен обменян на новый; повторное использование — утечка
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_refresh_token_family ON stock_refresh_token (family_id);
+CREATE INDEX IF NOT EXISTS idx_stock_refresh_token_expires_at ON stock_refresh_token (expires_at);
+
+-- Fallback-хранилище отозванных access-токенов (jti), когда Redis недоступен
+CREATE TABLE IF NOT EXISTS stock_revoked_token (
+    jti        VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_revoked_token_expires_at ON stock_revoked_token (expires_at);
@@ -0,0 +1,90 @@
+-- Роли и права: новая роль — это строки в stock_role и stock_role_permission, без изменений кода
+CREATE TABLE IF NOT EXISTS stock_role (
+    name        VARCHAR(50)  PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Справочник прав; имена должны совпадать с entities.Permission
+CREATE TABLE IF NOT EXISTS stock_permission (
+    name        VARCHAR(100) PRIMARY KEY,
+    description VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS stock_role_permission (
+    role       VARCHAR(50)  NOT NULL REFERENCES stock_role (name) ON DELETE CASCADE,
+    permission VARCHAR(100) NOT NULL REFERENCES stock_permission (name) ON DELETE CASCADE,
+    PRIMARY KEY (role, permission)
+);
+
+INSERT INTO stock_permission (name, description) VALUES
+    ('users:read:any',      'Read any user profile'),
+    ('users:admin',         'Update and delete users, including balance and role'),
+    ('stocks:read',         'Read stocks and candles'),
+    ('stocks:write',        'Create, update and delete stocks'),
+    ('orders:read',         'Read own orders'),
+    ('orders:read:any',     'Read orders of any user'),
+    ('orders:write',        'Place and cancel own orders'),
+    ('orders:write:any',    'Cancel or change status of any order'),
+    ('portfolio:read',      'Read own portfolio'),
+    ('portfolio:read:any',  'Read portfolio of any user'),
+    ('portfolio:write',     'Change own portfolio settings'),
+    ('portfolio:write:any', 'Adjust positions of any user'),
+    ('history:read',        'Read own history'),
+    ('history:read:any',    'Read history of any user'),
+    ('history:write',       'Add own history records'),
+    ('history:write:any',   'Add history records for any user'),
+    ('account:read',        'Read own ledger'),
+    ('account:write',       'Deposit and withdraw own funds'),
+    ('ledger:reconcile',    'Run ledger reconciliation'),
+    ('roles:admin',         'Manage roles and their permissions')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO stock_role (name, description) VALUES
+    ('TRADER',  'Trades on own account'),
+    ('ADMIN',   'Full access'),
+    ('SUPPORT', 'Read-only access to customer data'),
+    ('AUDITOR', 'Read-only access to customer data and ledger')
+ON CONFLICT (name) DO NOTHING;
+
+-- Роли, уже встречающиеся у пользователей, но без прав: доступ у них только к своему профилю
+INSERT INTO stock_role (name)
+SELECT DISTINCT role FROM stock_user
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO stock_role_permission (role, permission)
+SELECT 'ADMIN', name FROM stock_permission
+ON CONFLICT DO NOTHING;
+
+-- POST /portfolio (portfolio:write:any) правит позиции в обход сделок, поэтому у трейдера его нет
+INSERT INTO stock_role_permission (role, permission) VALUES
+    ('TRADER', 'stocks:read'),
+    ('TRADER', 'orders:read'),
+    ('TRADER', 'orders:write'),
+    ('TRADER', 'portfolio:read'),
+    ('TRADER', 'portfolio:write'),
+    ('TRADER', 'history:read'),
+    ('TRADER', 'history:write'),
+    ('TRADER', 'account:read'),
+    ('TRADER', 'account:write'),
+
+    ('SUPPORT', 'users:read:any'),
+    ('SUPPORT', 'stocks:read'),
+    ('SUPPORT', 'orders:read'),
+    ('SUPPORT', 'orders:read:any'),
+    ('SUPPORT', 'portfolio:read'),
+    ('SUPPORT', 'portfolio:read:any'),
+    ('SUPPORT', 'history:read'),
+    ('SUPPORT', 'history:read:any'),
+
+    ('AUDITOR', 'users:read:any'),
+    ('AUDITOR', 'stocks:read'),
+    ('AUDITOR', 'orders:read'),
+    ('AUDITOR', 'orders:read:any'),
+    ('AUDITOR', 'portfolio:read'),
+    ('AUDITOR', 'portfolio:read:any'),
+    ('AUDITOR', 'history:read'),
+    ('AUDITOR', 'history:read:any'),
+    ('AUDITOR', 'account:read'),
+    ('AUDITOR', 'ledger:reconcile')
+ON CONFLICT DO NOTHING;
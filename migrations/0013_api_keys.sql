@@ -0,0 +1,16 @@
+-- Персональные API-ключи: в БД только sha256 ключа, сам ключ показывается один раз
+CREATE TABLE IF NOT EXISTS stock_api_key (
+    id           BIGSERIAL PRIMARY KEY,
+    user_id      BIGINT       NOT NULL REFERENCES stock_user (id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL DEFAULT '',
+    prefix       VARCHAR(16)  NOT NULL,
+    key_hash     VARCHAR(64)  NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL,          -- read, trade, withdraw
+    allowed_ips  TEXT[]       NOT NULL DEFAULT '{}', -- IP или CIDR; пусто — без ограничений
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_api_key_user_id ON stock_api_key (user_id);
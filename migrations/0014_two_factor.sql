@@ -0,0 +1,21 @@
+-- TOTP второй фактор: секрет зашифрован AES-GCM ключом TOTP_ENCRYPTION_KEY
+CREATE TABLE IF NOT EXISTS stock_user_totp (
+    user_id         BIGINT PRIMARY KEY REFERENCES stock_user (id) ON DELETE CASCADE,
+    secret_enc      BYTEA       NOT NULL,
+    enabled_at      TIMESTAMPTZ,                -- NULL — подключение не подтверждено кодом
+    last_used_step  BIGINT      NOT NULL DEFAULT 0, -- защита от повторного использования кода
+    failed_attempts INT         NOT NULL DEFAULT 0,
+    locked_until    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Одноразовые коды восстановления: в БД только sha256
+CREATE TABLE IF NOT EXISTS stock_recovery_code (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL REFERENCES stock_user (id) ON DELETE CASCADE,
+    code_hash  VARCHAR(64) NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_recovery_code_user_id ON stock_recovery_code (user_id);
@@ -0,0 +1,19 @@
+-- Подтверждение email: до него торговать нельзя. Уже существующие пользователи считаются подтверждёнными.
+ALTER TABLE stock_user ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+UPDATE stock_user SET email_verified_at = created_at WHERE email_verified_at IS NULL;
+
+-- Одноразовые токены из писем. Сам токен — подписанный JWT, здесь только его jti:
+-- по нему токен гасится при использовании. email — адрес, на который ушло письмо;
+-- после смены адреса старые токены не действуют.
+CREATE TABLE IF NOT EXISTS stock_user_token (
+    jti        VARCHAR(36) PRIMARY KEY,
+    user_id    BIGINT       NOT NULL REFERENCES stock_user (id) ON DELETE CASCADE,
+    purpose    VARCHAR(32)  NOT NULL, -- verify_email, reset_password
+    email      VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ  NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_user_token_user_id ON stock_user_token (user_id, purpose);
+CREATE INDEX IF NOT EXISTS idx_stock_user_token_expires_at ON stock_user_token (expires_at);
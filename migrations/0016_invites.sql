@@ -0,0 +1,20 @@
+-- Приглашения на регистрацию с ролью: так заводятся администраторы и сотрудники,
+-- публичная регистрация создаёт только TRADER. В БД только sha256 кода.
+CREATE TABLE IF NOT EXISTS stock_invite (
+    id         BIGSERIAL PRIMARY KEY,
+    code_hash  VARCHAR(64)  NOT NULL UNIQUE,
+    role       VARCHAR(50)  NOT NULL REFERENCES stock_role (name) ON DELETE CASCADE,
+    email      VARCHAR(255) NOT NULL DEFAULT '', -- пусто — код подходит для любого адреса
+    created_by BIGINT       REFERENCES stock_user (id) ON DELETE SET NULL,
+    used_by    BIGINT       REFERENCES stock_user (id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ  NOT NULL,
+    used_at    TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_invite_created_at ON stock_invite (created_at);
+
+UPDATE stock_permission
+SET description = 'Create, update and delete users with any role, manage invites'
+WHERE name = 'users:admin';
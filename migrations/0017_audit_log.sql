@@ -0,0 +1,47 @@
+-- Журнал привилегированных действий и событий входа. Только добавление: правка и удаление
+-- запрещены триггером, а каждая запись хранит хэш предыдущей, поэтому подмену видно при проверке.
+CREATE TABLE IF NOT EXISTS stock_audit_log (
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    BIGINT,                      -- без FK: запись должна пережить удаление пользователя
+    actor_role  VARCHAR(50)  NOT NULL DEFAULT '',
+    action      VARCHAR(50)  NOT NULL,
+    target_type VARCHAR(50)  NOT NULL DEFAULT '',
+    target_id   VARCHAR(255) NOT NULL DEFAULT '',
+    before_data JSONB,                       -- только изменившиеся поля
+    after_data  JSONB,
+    ip          VARCHAR(64)  NOT NULL DEFAULT '',
+    request_id  VARCHAR(64)  NOT NULL DEFAULT '',
+    prev_hash   VARCHAR(64)  NOT NULL,
+    hash        VARCHAR(64)  NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_audit_log_actor ON stock_audit_log (actor_id, id);
+CREATE INDEX IF NOT EXISTS idx_stock_audit_log_target ON stock_audit_log (target_type, target_id, id);
+CREATE INDEX IF NOT EXISTS idx_stock_audit_log_action ON stock_audit_log (action, id);
+CREATE INDEX IF NOT EXISTS idx_stock_audit_log_created_at ON stock_audit_log (created_at);
+
+CREATE OR REPLACE FUNCTION stock_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_stock_audit_log_no_update ON stock_audit_log;
+CREATE TRIGGER trg_stock_audit_log_no_update
+    BEFORE UPDATE OR DELETE ON stock_audit_log
+    FOR EACH ROW EXECUTE FUNCTION stock_audit_log_append_only();
+
+DROP TRIGGER IF EXISTS trg_stock_audit_log_no_truncate ON stock_audit_log;
+CREATE TRIGGER trg_stock_audit_log_no_truncate
+    BEFORE TRUNCATE ON stock_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION stock_audit_log_append_only();
+
+INSERT INTO stock_permission (name, description) VALUES
+    ('audit:read', 'Read and verify the audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO stock_role_permission (role, permission) VALUES
+    ('ADMIN', 'audit:read'),
+    ('AUDITOR', 'audit:read')
+ON CONFLICT DO NOTHING;
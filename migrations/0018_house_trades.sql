@@ -0,0 +1,8 @@
+-- Исполнения против дома (рыночные и сработавшие стоп-заявки) тоже пишутся в stock_trade,
+-- чтобы попадать в объём свечей; сторона дома у таких сделок пустая
+ALTER TABLE stock_trade
+    ALTER COLUMN buy_order_id DROP NOT NULL,
+    ALTER COLUMN sell_order_id DROP NOT NULL,
+    ALTER COLUMN buyer_id DROP NOT NULL,
+    ALTER COLUMN seller_id DROP NOT NULL,
+    ADD CONSTRAINT stock_trade_has_order CHECK (buy_order_id IS NOT NULL OR sell_order_id IS NOT NULL);
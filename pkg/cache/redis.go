@@ -89,12 +89,9 @@ func (c *Cache) Store(key string, data interface{}, duration time.Duration, hsto
 	}
 
 	if hstore {
-		c.setHash(key, serializedData, field[0], duration)
-	} else {
-		c.setSimple(key, serializedData, duration)
+		return c.setHash(key, serializedData, field[0], duration)
 	}
-
-	return nil
+	return c.setSimple(key, serializedData, duration)
 }
 
 func (c *Cache) StoreNX(key string, data interface{}, duration time.Duration) (updated bool, err error) {
@@ -6,6 +6,7 @@ import (
 	"github.com/Skapar/backend/pkg/logger"
 
 	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
 )
 
@@ -78,6 +79,20 @@ func (tx *Tx) Delete(ctx context.Context, returnValue interface{}, sql string, a
 	return nil
 }
 
+// Exec, Query and QueryRow shadow the embedded Transaction, which is never set:
+// calling them through it would panic with a nil pointer.
+func (tx *Tx) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return tx.transaction.Exec(ctx, sql, args...)
+}
+
+func (tx *Tx) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return tx.transaction.Query(ctx, sql, args...)
+}
+
+func (tx *Tx) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return tx.transaction.QueryRow(ctx, sql, args...)
+}
+
 func (tx *Tx) Commit(ctx context.Context) error {
 	return tx.transaction.Commit(ctx)
 }